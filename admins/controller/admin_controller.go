@@ -0,0 +1,374 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"mygram-api/auth"
+	commentService "mygram-api/comments/service"
+	"mygram-api/helpers"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+	"mygram-api/models/response"
+	photoService "mygram-api/photos/service"
+	socialMediaService "mygram-api/social_medias/service"
+	userService "mygram-api/users/service"
+)
+
+type AdminController interface {
+	GetUsers(c *gin.Context)
+	BanUser(c *gin.Context)
+	UnbanUser(c *gin.Context)
+	SetUserRole(c *gin.Context)
+	DeletePhoto(c *gin.Context)
+	DeleteComment(c *gin.Context)
+	DeleteSocialMedia(c *gin.Context)
+}
+
+type AdminControllerService struct {
+	UserService        userService.UserService
+	PhotoService       photoService.PhotoService
+	CommentService     commentService.CommentService
+	SocialMediaService socialMediaService.SocialMediaService
+}
+
+func NewAdminController(userService userService.UserService, photoService photoService.PhotoService, commentService commentService.CommentService, socialMediaService socialMediaService.SocialMediaService) AdminController {
+	return &AdminControllerService{UserService: userService, PhotoService: photoService, CommentService: commentService, SocialMediaService: socialMediaService}
+}
+
+// GetUsers godoc
+// @Summary List users
+// @Description List every user with their role and ban state, moderators and admins only
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Security Bearer
+// @Router /admin/users [get]
+func (adminController *AdminControllerService) GetUsers(c *gin.Context) {
+
+	var pagination request.PaginationRequest
+
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	pagination.Normalize()
+
+	users, total, err := adminController.UserService.GetAll(pagination)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	usersResponse := []response.UserAdminResponse{}
+	for _, user := range users {
+		usersResponse = append(usersResponse, userAdminResponse(user))
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data:       usersResponse,
+		Pagination: response.NewPaginationResponse(pagination.Page, pagination.Limit, total),
+	})
+}
+
+// BanUser godoc
+// @Summary Ban a user
+// @Description Ban a user and sign them out everywhere, admins only
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security Bearer
+// @Router /admin/users/{id}/ban [post]
+func (adminController *AdminControllerService) BanUser(c *gin.Context) {
+
+	userID, ok := otherUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := adminController.UserService.Ban(userID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.ErrorResponse{
+			Code:   http.StatusNotFound,
+			Status: "Not Found",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: userAdminResponse(user),
+	})
+}
+
+// UnbanUser godoc
+// @Summary Unban a user
+// @Description Lift the ban of a user, admins only
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security Bearer
+// @Router /admin/users/{id}/unban [post]
+func (adminController *AdminControllerService) UnbanUser(c *gin.Context) {
+
+	userID, ok := otherUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := adminController.UserService.Unban(userID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.ErrorResponse{
+			Code:   http.StatusNotFound,
+			Status: "Not Found",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: userAdminResponse(user),
+	})
+}
+
+// SetUserRole godoc
+// @Summary Change the role of a user
+// @Description Make a user a regular user, moderator or admin, admins only
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param json body request.UserSetRoleRequest true "User Set Role Request"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security Bearer
+// @Router /admin/users/{id}/role [put]
+func (adminController *AdminControllerService) SetUserRole(c *gin.Context) {
+
+	var req request.UserSetRoleRequest
+
+	userID, ok := otherUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := c.ShouldBind(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	user, err := adminController.UserService.SetRole(userID, req.Role)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.ErrorResponse{
+			Code:   http.StatusNotFound,
+			Status: "Not Found",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: userAdminResponse(user),
+	})
+}
+
+// DeletePhoto godoc
+// @Summary Delete any photo
+// @Description Delete a photo of any user with its comments, moderators and admins only
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Photo ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security Bearer
+// @Router /admin/photos/{id} [delete]
+func (adminController *AdminControllerService) DeletePhoto(c *gin.Context) {
+
+	photoID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	if err := adminController.PhotoService.Delete(uint(photoID)); err != nil {
+		abortNotFound(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.PhotoDeleteResponse{
+			Message: "Photo deleted successfully",
+		},
+	})
+}
+
+// DeleteComment godoc
+// @Summary Delete any comment
+// @Description Delete a comment of any user, moderators and admins only
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Comment ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security Bearer
+// @Router /admin/comments/{id} [delete]
+func (adminController *AdminControllerService) DeleteComment(c *gin.Context) {
+
+	commentID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	if err := adminController.CommentService.Delete(uint(commentID)); err != nil {
+		abortNotFound(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.CommentDeleteResponse{
+			Message: "Comment deleted successfully",
+		},
+	})
+}
+
+// DeleteSocialMedia godoc
+// @Summary Delete any social media
+// @Description Delete a social media of any user, moderators and admins only
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Social Media ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security Bearer
+// @Router /admin/social-media/{id} [delete]
+func (adminController *AdminControllerService) DeleteSocialMedia(c *gin.Context) {
+
+	socialMediaID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	if _, err := adminController.SocialMediaService.GetOne(uint(socialMediaID)); err != nil {
+		abortNotFound(c, err)
+		return
+	}
+
+	if err := adminController.SocialMediaService.Delete(uint(socialMediaID)); err != nil {
+		abortNotFound(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.SocialMediaDeleteResponse{
+			Message: "Social Media Deleted Successfully",
+		},
+	})
+}
+
+// otherUserID reads the user id path parameter and refuses to let admins
+// act on their own account, so the last admin cannot lock themselves out
+func otherUserID(c *gin.Context) (uint, bool) {
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return 0, false
+	}
+
+	if uint(userID) == auth.MustGetPrincipal(c).UserID {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: gin.H{
+				"message": "You can't change your own account",
+			},
+		})
+
+		return 0, false
+	}
+
+	return uint(userID), true
+}
+
+func userAdminResponse(user domain.User) response.UserAdminResponse {
+	return response.UserAdminResponse{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		Role:      user.Role,
+		BannedAt:  user.BannedAt,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+func abortNotFound(c *gin.Context, err error) {
+	c.AbortWithStatusJSON(http.StatusNotFound, response.ErrorResponse{
+		Code:   http.StatusNotFound,
+		Status: "Not Found",
+		Errors: err.Error(),
+	})
+}
+
+func abortWithBindError(c *gin.Context, err error) {
+
+	validationError, ok := err.(validator.ValidationErrors)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	fieldErrorResponse := make(map[string]interface{})
+
+	for _, v := range validationError {
+		fieldErrorResponse[strings.ToLower(v.Field())] = helpers.GetValidationErrorMsg(v)
+	}
+
+	c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+		Code:   http.StatusBadRequest,
+		Status: "Bad Request",
+		Errors: fieldErrorResponse,
+	})
+}
@@ -0,0 +1,199 @@
+package app
+
+import (
+	"errors"
+	"sync"
+
+	"gorm.io/gorm"
+
+	commentRepository "mygram-api/comments/repository"
+	commentService "mygram-api/comments/service"
+	"mygram-api/config"
+	"mygram-api/database"
+	feedRepository "mygram-api/feeds/repository"
+	feedService "mygram-api/feeds/service"
+	followRepository "mygram-api/follows/repository"
+	followService "mygram-api/follows/service"
+	"mygram-api/jobs"
+	jobRepository "mygram-api/jobs/repository"
+	likeRepository "mygram-api/likes/repository"
+	likeService "mygram-api/likes/service"
+	"mygram-api/mailer"
+	mailWorker "mygram-api/mailer/worker"
+	mentionRepository "mygram-api/mentions/repository"
+	mentionService "mygram-api/mentions/service"
+	notificationRepository "mygram-api/notifications/repository"
+	notificationService "mygram-api/notifications/service"
+	photoRepository "mygram-api/photos/repository"
+	photoService "mygram-api/photos/service"
+	photoWorker "mygram-api/photos/worker"
+	"mygram-api/realtime"
+	searchRepository "mygram-api/search/repository"
+	searchService "mygram-api/search/service"
+	socialMediaRepository "mygram-api/social_medias/repository"
+	socialMediaService "mygram-api/social_medias/service"
+	"mygram-api/storage"
+	tagRepository "mygram-api/tags/repository"
+	tagService "mygram-api/tags/service"
+	userRepository "mygram-api/users/repository"
+	userService "mygram-api/users/service"
+	webhookRepository "mygram-api/webhooks/repository"
+	webhookService "mygram-api/webhooks/service"
+	webhookWorker "mygram-api/webhooks/worker"
+)
+
+// Container holds the dependencies shared by every route registrar
+type Container struct {
+	Config  *config.Config
+	DB      *gorm.DB
+	Storage storage.Storage
+	Hub     *realtime.Hub
+	Jobs    *jobs.Client
+	Mailer  mailer.Mailer
+
+	UserService         userService.UserService
+	AccountService      userService.AccountService
+	ProfileService      userService.ProfileService
+	PhotoService        photoService.PhotoService
+	CommentService      commentService.CommentService
+	LikeService         likeService.LikeService
+	FollowService       followService.FollowService
+	FeedService         feedService.FeedService
+	TagService          tagService.TagService
+	SearchService       searchService.SearchService
+	NotificationService notificationService.NotificationService
+	WebhookService      webhookService.WebhookService
+	SocialMediaService  socialMediaService.SocialMediaService
+
+	// VariantService and DeliveryService are only run by the job worker, as is Mailer
+	VariantService  photoService.VariantService
+	DeliveryService webhookService.DeliveryService
+
+	closeMutex sync.Mutex
+	closers    []func() error
+	closed     bool
+}
+
+// NewContainer opens the database once and wires every service on top of it
+func NewContainer(cfg *config.Config) (container *Container, err error) {
+	db, err := database.StartDB(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	// a start that fails past this point must not leave the pool open
+	defer func() {
+		if err != nil {
+			if sqlDB, dbErr := db.DB(); dbErr == nil {
+				sqlDB.Close()
+			}
+		}
+	}()
+
+	if cfg.Database.AutoMigrate {
+		if err = database.AutoMigrate(db); err != nil {
+			return nil, err
+		}
+	}
+
+	blobStorage, err := storage.New(cfg.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	broker, err := realtime.NewBroker(cfg.Realtime, db, cfg.Database.DSN())
+	if err != nil {
+		return nil, err
+	}
+
+	mailSender, err := mailer.New(cfg.Mail)
+	if err != nil {
+		return nil, err
+	}
+
+	hub := realtime.NewHub(broker)
+	publisher := realtime.NewPublisher(hub)
+
+	repositoryUser := userRepository.NewUserRepository(db)
+	repositoryRefreshToken := userRepository.NewRefreshTokenRepository(db)
+	repositoryPhoto := photoRepository.NewPhotoRepository(db)
+	repositorySocialMedia := socialMediaRepository.NewSocialMediaRepository(db)
+	repositoryFollow := followRepository.NewFollowRepository(db)
+	repositoryComment := commentRepository.NewCommentRepository(db)
+
+	jobClient := jobs.NewClient(jobRepository.NewJobRepository(db))
+	serviceAccount := userService.NewAccountService(repositoryUser, repositoryRefreshToken, mailWorker.NewMailScheduler(jobClient), cfg.JWT, cfg.Accounts)
+
+	serviceFeed := feedService.NewFeedService(repositoryPhoto, repositoryFollow, feedRepository.NewTimelineRepository(db), cfg.Feed)
+	serviceTag := tagService.NewTagService(tagRepository.NewTagRepository(db), repositoryPhoto)
+	serviceNotification := notificationService.NewNotificationService(notificationRepository.NewNotificationRepository(db), repositoryPhoto, repositoryComment, publisher)
+	serviceMention := mentionService.NewMentionService(mentionRepository.NewMentionRepository(db), repositoryUser, serviceNotification)
+
+	repositoryWebhookDelivery := webhookRepository.NewWebhookDeliveryRepository(db)
+	deliveryScheduler := webhookWorker.NewDeliveryScheduler(jobClient)
+	serviceWebhook := webhookService.NewWebhookService(webhookRepository.NewWebhookRepository(db), repositoryWebhookDelivery, repositoryPhoto, deliveryScheduler)
+
+	container = &Container{
+		Config:  cfg,
+		DB:      db,
+		Storage: blobStorage,
+		Hub:     hub,
+		Jobs:    jobClient,
+		Mailer:  mailSender,
+
+		UserService:         userService.NewUserService(repositoryUser, repositoryRefreshToken, serviceAccount, blobStorage, cfg.JWT, cfg.Accounts),
+		AccountService:      serviceAccount,
+		ProfileService:      userService.NewProfileService(repositoryUser, repositoryPhoto, repositorySocialMedia, repositoryFollow),
+		PhotoService:        photoService.NewPhotoService(repositoryPhoto, blobStorage, photoWorker.NewVariantScheduler(jobClient), serviceFeed, serviceTag, serviceMention, serviceWebhook, cfg.Storage.StripMetadata),
+		CommentService:      commentService.NewCommentService(repositoryComment, serviceMention, serviceNotification, publisher, serviceWebhook, cfg.Comments),
+		LikeService:         likeService.NewLikeService(likeRepository.NewLikeRepository(db), serviceNotification),
+		FollowService:       followService.NewFollowService(repositoryFollow, repositoryUser, serviceFeed, serviceNotification),
+		FeedService:         serviceFeed,
+		TagService:          serviceTag,
+		SearchService:       searchService.NewSearchService(searchRepository.NewSearchRepository(db), repositoryPhoto, repositoryComment, repositoryUser),
+		SocialMediaService:  socialMediaService.NewSocialMediaService(repositorySocialMedia, serviceWebhook),
+		NotificationService: serviceNotification,
+		WebhookService:      serviceWebhook,
+
+		VariantService:  photoService.NewVariantService(repositoryPhoto, photoRepository.NewPhotoVariantRepository(db), blobStorage, cfg.PhotoVariants),
+		DeliveryService: webhookService.NewDeliveryService(repositoryWebhookDelivery, deliveryScheduler, cfg.Webhooks),
+	}
+
+	hub.Start()
+	container.OnClose(hub.Close)
+
+	return container, nil
+}
+
+// OnClose registers a shutdown hook, such as stopping a background worker.
+// Hooks run in reverse registration order before the database pool closes.
+func (container *Container) OnClose(closer func() error) {
+	container.closeMutex.Lock()
+	defer container.closeMutex.Unlock()
+
+	container.closers = append(container.closers, closer)
+}
+
+// Close stops the registered hooks and then closes the database pool
+func (container *Container) Close() error {
+	container.closeMutex.Lock()
+	defer container.closeMutex.Unlock()
+
+	if container.closed {
+		return nil
+	}
+	container.closed = true
+
+	var errs []error
+	for i := len(container.closers) - 1; i >= 0; i-- {
+		errs = append(errs, container.closers[i]())
+	}
+
+	if sqlDB, err := container.DB.DB(); err != nil {
+		errs = append(errs, err)
+	} else {
+		errs = append(errs, sqlDB.Close())
+	}
+
+	return errors.Join(errs...)
+}
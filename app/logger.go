@@ -0,0 +1,65 @@
+package app
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// redactedParams are the query parameters carrying credentials, such as the
+// access token of the browser streams, which are never written to the logs
+var redactedParams = map[string]bool{
+	"access_token": true,
+	"token":        true,
+}
+
+// RequestLogger logs every request like gin.Logger, with the credentials in
+// its query string redacted
+func RequestLogger() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		var statusColor, methodColor, resetColor string
+		if param.IsOutputColor() {
+			statusColor = param.StatusCodeColor()
+			methodColor = param.MethodColor()
+			resetColor = param.ResetColor()
+		}
+
+		if param.Latency > time.Minute {
+			param.Latency = param.Latency.Truncate(time.Second)
+		}
+
+		return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			statusColor, param.StatusCode, resetColor,
+			param.Latency,
+			param.ClientIP,
+			methodColor, param.Method, resetColor,
+			redactQuery(param.Path),
+			param.ErrorMessage,
+		)
+	})
+}
+
+// redactQuery replaces the values of redactedParams in the query of path,
+// keeping the other parameters as they were sent
+func redactQuery(path string) string {
+	base, query, found := strings.Cut(path, "?")
+	if !found {
+		return path
+	}
+
+	pairs := strings.Split(query, "&")
+
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+
+		if name, err := url.QueryUnescape(key); err == nil && redactedParams[name] {
+			pairs[i] = key + "=REDACTED"
+		}
+	}
+
+	return base + "?" + strings.Join(pairs, "&")
+}
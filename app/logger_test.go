@@ -0,0 +1,25 @@
+package app
+
+import "testing"
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/stream", "/stream"},
+		{"/stream?photo_id=1", "/stream?photo_id=1"},
+		{"/stream?access_token=eyJ.abc.def", "/stream?access_token=REDACTED"},
+		{"/ws?photo_id=1&access_token=eyJ.abc.def&photo_id=2", "/ws?photo_id=1&access_token=REDACTED&photo_id=2"},
+		{"/ws?access%5Ftoken=eyJ", "/ws?access%5Ftoken=REDACTED"},
+		{"/users/verify?token=1.2.sig", "/users/verify?token=REDACTED"},
+		{"/stream?access_token", "/stream?access_token=REDACTED"},
+		{"/photos?tokens=1", "/photos?tokens=1"},
+	}
+
+	for _, test := range tests {
+		if got := redactQuery(test.path); got != test.want {
+			t.Errorf("redactQuery(%q) = %q, want %q", test.path, got, test.want)
+		}
+	}
+}
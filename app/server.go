@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"mygram-api/config"
+)
+
+// NewServer builds the http server with the configured timeouts
+func NewServer(appConfig config.AppConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              appConfig.Address(),
+		Handler:           handler,
+		ReadTimeout:       appConfig.ReadTimeout,
+		ReadHeaderTimeout: appConfig.ReadHeaderTimeout,
+		WriteTimeout:      appConfig.WriteTimeout,
+		IdleTimeout:       appConfig.IdleTimeout,
+	}
+}
+
+// Serve runs the server until ctx is cancelled, then stops accepting new
+// connections and waits up to the configured shutdown timeout for in-flight
+// requests to finish before closing the container.
+func Serve(ctx context.Context, server *http.Server, container *Container) error {
+	// streams never finish on their own, closing the hub ends them so they do
+	// not hold the shutdown up to its timeout
+	server.RegisterOnShutdown(func() {
+		_ = container.Hub.Close()
+	})
+
+	serveErr := make(chan error, 1)
+
+	go func() {
+		log.Printf("Listening and serving HTTP on %s\n", server.Addr)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return errors.Join(err, container.Close())
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down, draining in-flight requests")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), container.Config.App.ShutdownTimeout)
+	defer cancel()
+
+	err := server.Shutdown(shutdownCtx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = errors.Join(err, server.Close())
+	}
+
+	if serveErr := <-serveErr; !errors.Is(serveErr, http.ErrServerClosed) {
+		err = errors.Join(err, serveErr)
+	}
+
+	return errors.Join(err, container.Close())
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"log"
+	"time"
+
+	"mygram-api/jobs"
+	mailWorker "mygram-api/mailer/worker"
+	photoWorker "mygram-api/photos/worker"
+	webhookWorker "mygram-api/webhooks/worker"
+)
+
+const (
+	// cleanupInterval is how often the finished jobs past their retention are removed
+	cleanupInterval = time.Hour
+
+	// deliverSweepInterval is how often the webhook deliveries left without a job are queued again
+	deliverSweepInterval = 5 * time.Minute
+)
+
+// NewWorker builds the job worker with the handlers of every job type, each
+// queue running as many jobs at once as its setting allows
+func NewWorker(container *Container) *jobs.Worker {
+	cfg := container.Config
+
+	worker := jobs.NewWorker(container.Jobs.JobRepository, cfg.Jobs, map[string]int{
+		jobs.QueueDefault:  cfg.Jobs.Workers,
+		jobs.QueueVariants: cfg.PhotoVariants.Workers,
+		jobs.QueueWebhooks: cfg.Webhooks.Workers,
+	})
+
+	worker.Register(
+		jobs.CleanupHandler(container.Jobs.JobRepository, cfg.Jobs.Retention),
+		photoWorker.VariantsHandler(container.VariantService),
+		photoWorker.VariantsSweepHandler(container.VariantService, container.Jobs),
+		webhookWorker.DeliverHandler(container.DeliveryService),
+		webhookWorker.DeliverSweepHandler(container.DeliveryService, webhookWorker.NewDeliveryScheduler(container.Jobs)),
+		mailWorker.MailHandler(container.Mailer),
+	)
+
+	worker.Every(cleanupInterval, func() {
+		if _, err := jobs.Enqueue(container.Jobs, jobs.CleanupJob, struct{}{}, jobs.Unique(jobs.CleanupJob.Name)); err != nil {
+			log.Printf("queueing the job cleanup: %v", err)
+		}
+	})
+
+	worker.Every(deliverSweepInterval, func() {
+		if _, err := jobs.Enqueue(container.Jobs, webhookWorker.DeliverSweepJob, struct{}{}, jobs.Unique(webhookWorker.DeliverSweepJob.Name)); err != nil {
+			log.Printf("queueing the webhook delivery sweep: %v", err)
+		}
+	})
+
+	return worker
+}
+
+// StartWorker runs the job worker until the container closes, and queues a
+// sweep of the photos still without variants
+func StartWorker(container *Container) {
+	worker := NewWorker(container)
+
+	worker.Start()
+	container.OnClose(worker.Stop)
+
+	if _, err := jobs.Enqueue(container.Jobs, photoWorker.VariantsSweepJob, struct{}{}, jobs.Unique(photoWorker.VariantsSweepJob.Name)); err != nil {
+		log.Printf("queueing the photo variants sweep: %v", err)
+	}
+}
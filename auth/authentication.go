@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"mygram-api/helpers"
+	"mygram-api/models/response"
+)
+
+var errSessionRevoked = errors.New("session has been revoked, sign in to proceed")
+
+// SessionChecker reports whether the refresh token family an access token
+// was issued for has been revoked
+type SessionChecker interface {
+	IsSessionRevoked(sessionID string) bool
+}
+
+// Authentication verifies the bearer token and places its Principal on the context
+func Authentication(secretKey string, sessionChecker SessionChecker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims, err := helpers.VerifyToken(ctx, secretKey)
+
+		if err == nil && sessionChecker.IsSessionRevoked(claims.SessionID) {
+			err = errSessionRevoked
+		}
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response.ErrorResponse{
+				Code:   http.StatusUnauthorized,
+				Status: "Unauthorized",
+				Errors: err.Error(),
+			})
+
+			return
+		}
+
+		SetPrincipal(ctx, Principal{
+			UserID:    claims.ID,
+			Email:     claims.Email,
+			Roles:     claims.Roles,
+			SessionID: claims.SessionID,
+			ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+		})
+		ctx.Next()
+	}
+}
+
+// QueryToken copies the access_token query parameter into the Authorization
+// header for the clients that cannot set headers, such as browser EventSource
+// and WebSocket, to be verified by Authentication as usual
+func QueryToken() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if token := ctx.Query("access_token"); token != "" && ctx.GetHeader("Authorization") == "" {
+			ctx.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mygram-api/models/domain"
+	"mygram-api/models/response"
+)
+
+// RequireRole lets the request through only when the principal holds one of roles
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if principal, ok := GetPrincipal(ctx); !ok || !principal.HasRole(roles...) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, response.ErrorResponse{
+				Code:   http.StatusForbidden,
+				Status: "Forbidden",
+				Errors: gin.H{
+					"message": "You don't have permission",
+				},
+			})
+
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// CanModerate reports whether the principal may act on content owned by ownerID
+func (principal Principal) CanModerate(ownerID uint) bool {
+	return principal.UserID == ownerID || principal.HasRole(domain.RoleModerator, domain.RoleAdmin)
+}
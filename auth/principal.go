@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const principalKey = "principal"
+
+// Principal represents the authenticated user of a request
+type Principal struct {
+	UserID    uint
+	Email     string
+	Roles     []string
+	SessionID string
+
+	// ExpiresAt is when the access token of the request expires
+	ExpiresAt time.Time
+}
+
+// HasRole reports whether the principal holds any of the given roles
+func (principal Principal) HasRole(roles ...string) bool {
+	for _, held := range principal.Roles {
+		for _, role := range roles {
+			if held == role {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// SetPrincipal stores the principal on the request context
+func SetPrincipal(ctx *gin.Context, principal Principal) {
+	ctx.Set(principalKey, principal)
+}
+
+// GetPrincipal returns the principal stored by the Authentication middleware
+func GetPrincipal(ctx *gin.Context) (Principal, bool) {
+	value, ok := ctx.Get(principalKey)
+	if !ok {
+		return Principal{}, false
+	}
+
+	principal, ok := value.(Principal)
+
+	return principal, ok
+}
+
+// MustGetPrincipal is GetPrincipal for handlers mounted behind Authentication
+func MustGetPrincipal(ctx *gin.Context) Principal {
+	principal, ok := GetPrincipal(ctx)
+	if !ok {
+		panic("auth: no principal on context, is the route behind Authentication?")
+	}
+
+	return principal
+}
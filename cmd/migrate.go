@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"mygram-api/config"
+	"mygram-api/database"
+	"mygram-api/migrations"
+)
+
+// MigrateCommand returns the `migrate up|down|status|create` subcommand
+func MigrateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "migrate",
+		Usage: "Manage the database schema",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "up",
+				Usage: "Apply pending migrations",
+				Flags: []cli.Flag{
+					&cli.IntFlag{Name: "steps", Usage: "number of migrations to apply, 0 applies all"},
+				},
+				Action: migrateUp,
+			},
+			{
+				Name:  "down",
+				Usage: "Roll back applied migrations",
+				Flags: []cli.Flag{
+					&cli.IntFlag{Name: "steps", Value: 1, Usage: "number of migrations to roll back"},
+				},
+				Action: migrateDown,
+			},
+			{
+				Name:   "status",
+				Usage:  "List migrations and whether they are applied",
+				Action: migrateStatus,
+			},
+			{
+				Name:      "create",
+				Usage:     "Create an empty up/down migration pair",
+				ArgsUsage: "<name>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "dir", Value: "migrations/sql", Usage: "directory to write the migration files to"},
+				},
+				Action: migrateCreate,
+			},
+		},
+	}
+}
+
+func newMigrator() (*migrations.Migrator, func(), error) {
+	// the schema can be managed before the rest of the api is configured
+	cfg, err := config.LoadDatabase()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := database.StartDB(cfg.Database)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	closeDB := func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		closeDB()
+		return nil, nil, err
+	}
+
+	return migrator, closeDB, nil
+}
+
+func migrateUp(ctx *cli.Context) error {
+	migrator, closeDB, err := newMigrator()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	applied, err := migrator.Up(ctx.Int("steps"))
+	for _, migration := range applied {
+		fmt.Fprintf(ctx.App.Writer, "applied %d_%s\n", migration.Version, migration.Name)
+	}
+
+	if err == nil && len(applied) == 0 {
+		fmt.Fprintln(ctx.App.Writer, "no pending migrations")
+	}
+
+	return err
+}
+
+func migrateDown(ctx *cli.Context) error {
+	migrator, closeDB, err := newMigrator()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	reverted, err := migrator.Down(ctx.Int("steps"))
+	for _, migration := range reverted {
+		fmt.Fprintf(ctx.App.Writer, "reverted %d_%s\n", migration.Version, migration.Name)
+	}
+
+	if err == nil && len(reverted) == 0 {
+		fmt.Fprintln(ctx.App.Writer, "no applied migrations")
+	}
+
+	return err
+}
+
+func migrateStatus(ctx *cli.Context) error {
+	migrator, closeDB, err := newMigrator()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(ctx.App.Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(writer, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+
+	return writer.Flush()
+}
+
+func migrateCreate(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return cli.Exit("usage: mygram-api migrate create <name>", 1)
+	}
+
+	upPath, downPath, err := migrations.Create(ctx.String("dir"), ctx.Args().First(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(ctx.App.Writer, "created %s\ncreated %s\n", upPath, downPath)
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"mygram-api/config"
+	"mygram-api/database"
+	"mygram-api/jobs"
+	jobRepository "mygram-api/jobs/repository"
+	mailWorker "mygram-api/mailer/worker"
+	"mygram-api/storage"
+	"mygram-api/users/repository"
+	"mygram-api/users/service"
+)
+
+// UserCommand returns the `user set-role` subcommand, used to bootstrap the
+// first admin before anyone can reach the admin endpoints
+func UserCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "user",
+		Usage: "Manage user accounts",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "set-role",
+				Usage: "Change the role of a user",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "username", Required: true, Usage: "username of the account to change"},
+					&cli.StringFlag{Name: "role", Required: true, Usage: "one of user, moderator or admin"},
+				},
+				Action: userSetRole,
+			},
+		},
+	}
+}
+
+func userSetRole(ctx *cli.Context) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	db, err := database.StartDB(cfg.Database)
+	if err != nil {
+		return err
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	blobStorage, err := storage.New(cfg.Storage)
+	if err != nil {
+		return err
+	}
+
+	repositoryUser := repository.NewUserRepository(db)
+	repositoryRefreshToken := repository.NewRefreshTokenRepository(db)
+	serviceAccount := service.NewAccountService(repositoryUser, repositoryRefreshToken, mailWorker.NewMailScheduler(jobs.NewClient(jobRepository.NewJobRepository(db))), cfg.JWT, cfg.Accounts)
+	serviceUser := service.NewUserService(repositoryUser, repositoryRefreshToken, serviceAccount, blobStorage, cfg.JWT, cfg.Accounts)
+
+	user, err := repositoryUser.GetByUsername(ctx.String("username"))
+	if err != nil {
+		return fmt.Errorf("finding user %s: %w", ctx.String("username"), err)
+	}
+
+	if user, err = serviceUser.SetRole(user.ID, ctx.String("role")); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(ctx.App.Writer, "%s is now %s\n", user.Username, user.Role)
+
+	return nil
+}
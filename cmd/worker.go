@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/urfave/cli/v2"
+
+	"mygram-api/app"
+	"mygram-api/config"
+)
+
+// WorkerCommand returns the `worker` subcommand, running the background jobs
+// apart from the http server
+func WorkerCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "worker",
+		Usage:  "Run the background job worker",
+		Action: work,
+	}
+}
+
+func work(ctx *cli.Context) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	container, err := app.NewContainer(cfg)
+	if err != nil {
+		return err
+	}
+
+	signalCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	app.StartWorker(container)
+	log.Println("Running background jobs")
+
+	<-signalCtx.Done()
+
+	log.Println("Shutting down, requeueing the jobs in progress")
+
+	return container.Close()
+}
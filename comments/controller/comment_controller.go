@@ -1,14 +1,16 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
 
+	"mygram-api/auth"
 	"mygram-api/helpers"
 	"mygram-api/models/domain"
 	"mygram-api/models/request"
@@ -19,8 +21,10 @@ import (
 
 type CommentController interface {
 	Create(c *gin.Context)
+	CreateOnPhoto(c *gin.Context)
 	GetAll(c *gin.Context)
 	GetOne(c *gin.Context)
+	GetThread(c *gin.Context)
 	Update(c *gin.Context)
 	Delete(c *gin.Context)
 }
@@ -36,7 +40,7 @@ func NewCommentController(commentService service.CommentService, photoService ph
 
 // Create comment godoc
 // @Summary Create a comment
-// @Description Create and store a comment with authentication user
+// @Description Create and store a comment with authentication user, a reply to another comment of the photo when parent_id is set
 // @Tags comments
 // @Security Bearer
 // @Accept json
@@ -50,8 +54,7 @@ func (commentController *CommentControllerService) Create(c *gin.Context) {
 
 	var req request.CommentCreateRequest
 
-	userData := c.MustGet("userData").(jwt.MapClaims)
-	userID := uint(userData["id"].(float64))
+	userID := auth.MustGetPrincipal(c).UserID
 
 	contentType := c.Request.Header.Get("Content-Type")
 
@@ -101,9 +104,60 @@ func (commentController *CommentControllerService) Create(c *gin.Context) {
 		return
 	}
 
-	photoID := req.PhotoID
+	commentController.create(c, domain.Comment{
+		Message:  req.Message,
+		PhotoID:  req.PhotoID,
+		ParentID: req.ParentID,
+		UserID:   userID,
+	})
+}
+
+// CreateOnPhoto comment godoc
+// @Summary Comment on a photo
+// @Description Create and store a comment on the photo in the path, a reply to another comment of the photo when parent_id is set
+// @Tags comments
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Photo ID"
+// @Param json body request.PhotoCommentCreateRequest true "Add Comment"
+// @Success 201 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /photos/{id}/comments [post]
+func (commentController *CommentControllerService) CreateOnPhoto(c *gin.Context) {
+
+	var req request.PhotoCommentCreateRequest
 
-	if _, err := commentController.PhotoService.GetOne(photoID); err != nil {
+	photoID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	if err = c.ShouldBind(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	commentController.create(c, domain.Comment{
+		Message:  req.Message,
+		PhotoID:  uint(photoID),
+		ParentID: req.ParentID,
+		UserID:   auth.MustGetPrincipal(c).UserID,
+	})
+}
+
+// create stores comment once its photo is known to exist
+func (commentController *CommentControllerService) create(c *gin.Context, comment domain.Comment) {
+
+	if _, err := commentController.PhotoService.GetOne(comment.PhotoID); err != nil {
 		c.AbortWithStatusJSON(http.StatusNotFound, response.ErrorResponse{
 			Code:   http.StatusNotFound,
 			Status: "Not Found",
@@ -115,13 +169,19 @@ func (commentController *CommentControllerService) Create(c *gin.Context) {
 		return
 	}
 
-	comment := domain.Comment{
-		Message: req.Message,
-		PhotoID: req.PhotoID,
-		UserID:  userID,
-	}
-
 	if err := commentController.CommentService.Create(&comment); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, response.ErrorResponse{
+				Code:   http.StatusNotFound,
+				Status: "Not Found",
+				Errors: gin.H{
+					"message": "Parent comment not found",
+				},
+			})
+
+			return
+		}
+
 		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
 			Code:   http.StatusBadRequest,
 			Status: "Bad Request",
@@ -136,6 +196,7 @@ func (commentController *CommentControllerService) Create(c *gin.Context) {
 			ID:        comment.ID,
 			UserID:    comment.UserID,
 			PhotoID:   comment.PhotoID,
+			ParentID:  comment.ParentID,
 			Message:   comment.Message,
 			CreatedAt: comment.CreatedAt,
 		},
@@ -144,49 +205,75 @@ func (commentController *CommentControllerService) Create(c *gin.Context) {
 
 // GetAll comment godoc
 // @Summary Get all comments
-// @Description Get all comments with authentication user
+// @Description Get a page of comments with authentication user
 // @Tags comments
 // @Security Bearer
 // @Accept json
 // @Produce json
+// @Param page query int false "Page number for offset pagination with a total, without it the first keyset page is returned. Ignored when cursor is set"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "Cursor of the next page from a previous response"
+// @Param sort query string false "Sort order" Enums(-created_at, created_at) default(-created_at)
+// @Param user_id query int false "Only items of this user"
+// @Param created_after query string false "Only items created after this RFC 3339 time"
+// @Param photo_id query int false "Only comments on this photo"
 // @Success 200 {object} response.SuccessResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Router /comments [get]
 func (commentController *CommentControllerService) GetAll(c *gin.Context) {
 
-	comments, err := commentController.CommentService.GetAll()
+	var list request.CommentListRequest
+
+	if err := c.ShouldBindQuery(&list); err != nil {
+		validationError, ok := err.(validator.ValidationErrors)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+				Code:   http.StatusBadRequest,
+				Status: "Bad Request",
+				Errors: err.Error(),
+			})
+
+			return
+		}
+
+		fieldErrorResponse := make(map[string]interface{})
+
+		for _, v := range validationError {
+			fieldErrorResponse[strings.ToLower(v.Field())] = helpers.GetValidationErrorMsg(v)
+		}
+
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: fieldErrorResponse,
+		})
+
+		return
+	}
+
+	list.Normalize()
+
+	comments, page, err := commentController.CommentService.GetAll(list)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
 			Code:   http.StatusBadRequest,
 			Status: "Bad Request",
 			Errors: err.Error(),
 		})
+
+		return
 	}
 
 	commentsResponse := []response.CommentGetAllResponse{}
 	for _, comment := range comments {
-		commentsResponse = append(commentsResponse, response.CommentGetAllResponse{
-			ID:        comment.ID,
-			Message:   comment.Message,
-			PhotoID:   comment.PhotoID,
-			UserID:    comment.UserID,
-			User: response.CommentUserGetAllResponse{
-				Username: comment.User.Username,
-			},
-			Photo: response.CommentPhotoGetAllResponse{
-				Title:    comment.Photo.Title,
-				Caption:  comment.Photo.Caption,
-				PhotoUrl: comment.Photo.PhotoUrl,
-			},
-			CreatedAt: comment.CreatedAt,
-			UpdatedAt: comment.UpdatedAt,
-		})
+		commentsResponse = append(commentsResponse, response.NewCommentGetAllResponse(comment))
 	}
 
 	c.JSON(http.StatusOK, response.SuccessResponse{
-		Data: commentsResponse,
+		Data:       commentsResponse,
+		Pagination: response.NewListPaginationResponse(list.ListRequest, page.Total, page.NextCursor),
 	})
 }
 
@@ -223,25 +310,83 @@ func (commentController *CommentControllerService) GetOne(c *gin.Context) {
 		return
 	}
 
-	commentResponse := response.CommentGetAllResponse{
-		ID:        comment.ID,
-		Message:   comment.Message,
-		PhotoID:   comment.PhotoID,
-		UserID:    comment.UserID,
-		User: response.CommentUserGetAllResponse{
-			Username: comment.User.Username,
-		},
-		Photo: response.CommentPhotoGetAllResponse{
-			Title:    comment.Photo.Title,
-			Caption:  comment.Photo.Caption,
-			PhotoUrl: comment.Photo.PhotoUrl,
-		},
-		CreatedAt: comment.CreatedAt,
-		UpdatedAt: comment.UpdatedAt,
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.NewCommentGetAllResponse(comment),
+	})
+}
+
+// GetThread comment godoc
+// @Summary Get the comments of a photo
+// @Description Get a page of the top level comments of a photo, each with its replies nested down to the configured depth.
+// @Description A deleted comment that has replies is kept with "[deleted]" as message and without its author.
+// @Tags comments
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Photo ID"
+// @Param page query int false "Page number for offset pagination with a total, without it the first keyset page is returned. Ignored when cursor is set"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "Cursor of the next page from a previous response"
+// @Param sort query string false "Sort order of the top level comments, replies are always oldest first" Enums(-created_at, created_at) default(-created_at)
+// @Param user_id query int false "Only top level comments of this user"
+// @Param created_after query string false "Only top level comments created after this RFC 3339 time"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /photos/{id}/comments [get]
+func (commentController *CommentControllerService) GetThread(c *gin.Context) {
+
+	var list request.ListRequest
+
+	photoID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	if _, err = commentController.PhotoService.GetOne(uint(photoID)); err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.ErrorResponse{
+			Code:   http.StatusNotFound,
+			Status: "Not Found",
+			Errors: gin.H{
+				"message": "Record not found",
+			},
+		})
+
+		return
+	}
+
+	if err = c.ShouldBindQuery(&list); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	list.Normalize()
+
+	comments, replyCounts, page, err := commentController.CommentService.GetThread(uint(photoID), list)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	commentsResponse := []response.CommentThreadResponse{}
+	for _, comment := range comments {
+		commentsResponse = append(commentsResponse, response.NewCommentThreadResponse(comment, replyCounts))
 	}
 
 	c.JSON(http.StatusOK, response.SuccessResponse{
-		Data: commentResponse,
+		Data:       commentsResponse,
+		Pagination: response.NewListPaginationResponse(list, page.Total, page.NextCursor),
 	})
 }
 
@@ -267,8 +412,6 @@ func (commentController *CommentControllerService) Update(c *gin.Context) {
 	)
 
 	commentID, _ := strconv.ParseUint(c.Param("commentId"), 10, 32)
-	userData := c.MustGet("userData").(jwt.MapClaims)
-	userID := uint(userData["id"].(float64))
 
 	contentType := c.Request.Header.Get("Content-Type")
 
@@ -303,9 +446,9 @@ func (commentController *CommentControllerService) Update(c *gin.Context) {
 		return
 	}
 
+	// the author stays the same whoever edits, a moderator included
 	comment := domain.Comment{
-		ID:     uint(commentID),
-		UserID: userID,
+		ID: uint(commentID),
 	}
 
 	if req.Message != "" {
@@ -335,7 +478,7 @@ func (commentController *CommentControllerService) Update(c *gin.Context) {
 
 // Delete godoc
 // @Summary Delete a comment
-// @Description Delete a comment by id with authentication user
+// @Description Delete a comment by id with authentication user, a comment with replies is kept as a "[deleted]" placeholder
 // @Tags comments
 // @Security Bearer
 // @Accept json
@@ -366,3 +509,29 @@ func (commentController *CommentControllerService) Delete(c *gin.Context) {
 		},
 	})
 }
+
+func abortWithBindError(c *gin.Context, err error) {
+
+	validationError, ok := err.(validator.ValidationErrors)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	fieldErrorResponse := make(map[string]interface{})
+
+	for _, v := range validationError {
+		fieldErrorResponse[strings.ToLower(v.Field())] = helpers.GetValidationErrorMsg(v)
+	}
+
+	c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+		Code:   http.StatusBadRequest,
+		Status: "Bad Request",
+		Errors: fieldErrorResponse,
+	})
+}
@@ -4,9 +4,9 @@ import (
 	"net/http"
 	"strconv"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 
+	"mygram-api/auth"
 	"mygram-api/models/domain"
 	"mygram-api/models/response"
 	"mygram-api/comments/service"
@@ -20,8 +20,7 @@ func Authorization(commentService service.CommentService) gin.HandlerFunc {
 		)
 
 		commentID, _ := strconv.ParseUint(ctx.Param("commentId"), 10, 32)
-		userData := ctx.MustGet("userData").(jwt.MapClaims)
-		userID := uint(userData["id"].(float64))
+		principal := auth.MustGetPrincipal(ctx)
 
 		if comment, err = commentService.GetOne(uint(commentID)); err != nil {
 			ctx.AbortWithStatusJSON(http.StatusNotFound, response.ErrorResponse{
@@ -35,7 +34,7 @@ func Authorization(commentService service.CommentService) gin.HandlerFunc {
 			return
 		}
 
-		if comment.UserID != userID {
+		if !principal.CanModerate(comment.UserID) {
 			ctx.AbortWithStatusJSON(http.StatusForbidden, response.ErrorResponse{
 				Code:   http.StatusForbidden,
 				Status: "Forbidden",
@@ -1,15 +1,25 @@
 package repository
 
 import (
+	"errors"
+	"time"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
+	"mygram-api/database"
 	"mygram-api/models/domain"
+	"mygram-api/models/request"
 )
 
 type CommentRepository interface {
 	Create(comment *domain.Comment) (err error)
-	GetAll() (comments []domain.Comment, err error)
+	GetAll(list request.CommentListRequest) (comments []domain.Comment, page database.Page, err error)
 	GetOne(id uint) (comment domain.Comment, err error)
+	GetByIDs(ids []uint) (comments []domain.Comment, err error)
+	GetRootsByPhoto(photoID uint, list request.ListRequest) (comments []domain.Comment, page database.Page, err error)
+	GetReplies(rootIDs []uint, maxDepth int) (replies []domain.Comment, err error)
+	CountReplies(ids []uint) (replyCounts map[uint]int64, err error)
 	Update(comment domain.Comment) (updatedComment domain.Comment, err error)
 	Delete(id uint) (err error)
 }
@@ -31,17 +41,21 @@ func (commentRepository *CommentRepositoryDB) Create(comment *domain.Comment) (e
 	return
 }
 
-func (commentRepository *CommentRepositoryDB) GetAll() (comments []domain.Comment, err error) {
+func (commentRepository *CommentRepositoryDB) GetAll(list request.CommentListRequest) (comments []domain.Comment, page database.Page, err error) {
 
-	if err = commentRepository.DB.Preload("User", func(db *gorm.DB) *gorm.DB {
+	query := commentRepository.DB.Model(&domain.Comment{}).Preload("User", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id", "email", "username")
 	}).Preload("Photo", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id", "user_id", "title", "photo_url", "caption")
-	}).Find(&comments).Error; err != nil {
-		return
+	})
+
+	if list.PhotoID != 0 {
+		query = query.Where("photo_id = ?", list.PhotoID)
 	}
 
-	return
+	return database.Paginate(query, list.ListRequest, func(comment domain.Comment) request.Cursor {
+		return request.Cursor{CreatedAt: comment.CreatedAt, ID: comment.ID}
+	})
 }
 
 func (commentRepository *CommentRepositoryDB) GetOne(id uint) (comment domain.Comment, err error) {
@@ -55,28 +69,146 @@ func (commentRepository *CommentRepositoryDB) GetOne(id uint) (comment domain.Co
     return
 }
 
-func (commentRepository *CommentRepositoryDB) Update(comment domain.Comment) (updatedComment domain.Comment, err error) {
+// GetByIDs loads the comments with the given ids, in no particular order
+func (commentRepository *CommentRepositoryDB) GetByIDs(ids []uint) (comments []domain.Comment, err error) {
 
-	if err = commentRepository.DB.First(&updatedComment, comment.ID).Error; err != nil {
+	if err = commentRepository.DB.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "email", "username")
+	}).Preload("Photo", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "user_id", "title", "photo_url", "caption")
+	}).Find(&comments, ids).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// GetRootsByPhoto loads a page of the top level comments of a photo
+func (commentRepository *CommentRepositoryDB) GetRootsByPhoto(photoID uint, list request.ListRequest) (comments []domain.Comment, page database.Page, err error) {
+
+	query := commentRepository.DB.Model(&domain.Comment{}).Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "username")
+	}).Where("photo_id = ? AND parent_id IS NULL", photoID)
+
+	return database.Paginate(query, list, func(comment domain.Comment) request.Cursor {
+		return request.Cursor{CreatedAt: comment.CreatedAt, ID: comment.ID}
+	})
+}
+
+// GetReplies loads the replies under the given comments down to maxDepth,
+// oldest first so every level reads as a conversation
+func (commentRepository *CommentRepositoryDB) GetReplies(rootIDs []uint, maxDepth int) (replies []domain.Comment, err error) {
+
+	if len(rootIDs) == 0 || maxDepth < 1 {
+		return
+	}
+
+	if err = commentRepository.DB.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "username")
+	}).Where(`id IN (
+		WITH RECURSIVE thread AS (
+			SELECT id FROM comments WHERE parent_id IN ? AND depth <= ?
+			UNION ALL
+			SELECT comments.id FROM comments JOIN thread ON comments.parent_id = thread.id WHERE comments.depth <= ?
+		)
+		SELECT id FROM thread
+	)`, rootIDs, maxDepth, maxDepth).Order("created_at ASC, id ASC").Find(&replies).Error; err != nil {
 		return
 	}
 
-	if err = commentRepository.DB.Model(&updatedComment).Updates(comment).Error; err != nil {
+	return
+}
+
+// CountReplies counts the direct replies of each comment, comments without
+// replies are left out of the map
+func (commentRepository *CommentRepositoryDB) CountReplies(ids []uint) (replyCounts map[uint]int64, err error) {
+
+	var rows []struct {
+		ParentID   uint
+		ReplyCount int64
+	}
+
+	replyCounts = make(map[uint]int64)
+
+	if len(ids) == 0 {
 		return
 	}
 
+	if err = commentRepository.DB.Model(&domain.Comment{}).
+		Select("parent_id, COUNT(*) AS reply_count").
+		Where("parent_id IN ?", ids).
+		Group("parent_id").
+		Scan(&rows).Error; err != nil {
+		return
+	}
+
+	for _, row := range rows {
+		replyCounts[row.ParentID] = row.ReplyCount
+	}
+
 	return
 }
 
-func (commentRepository *CommentRepositoryDB) Delete(id uint) (err error) {
+func (commentRepository *CommentRepositoryDB) Update(comment domain.Comment) (updatedComment domain.Comment, err error) {
 
-	if err = commentRepository.DB.First(&domain.Comment{}, id).Error; err != nil {
+	if err = commentRepository.DB.First(&updatedComment, comment.ID).Error; err != nil {
 		return
 	}
 
-	if err = commentRepository.DB.Delete(&domain.Comment{}, id).Error; err != nil {
+	// only the message is editable, the author and the photo never move
+	if err = commentRepository.DB.Model(&updatedComment).Omit("user_id", "photo_id", "parent_id", "depth").Updates(comment).Error; err != nil {
 		return
 	}
 
 	return
-}
\ No newline at end of file
+}
+
+// Delete removes a comment. A comment with replies is kept as a placeholder
+// without its message instead, and a placeholder left without replies is
+// removed along with its last reply.
+func (commentRepository *CommentRepositoryDB) Delete(id uint) (err error) {
+
+	return commentRepository.DB.Transaction(func(tx *gorm.DB) error {
+		var comment domain.Comment
+
+		// the lock makes replies posted meanwhile wait, so none is lost with the comment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&comment, id).Error; err != nil {
+			return err
+		}
+
+		for {
+			var replies int64
+
+			if err := tx.Model(&domain.Comment{}).Where("parent_id = ?", comment.ID).Count(&replies).Error; err != nil {
+				return err
+			}
+
+			if replies > 0 {
+				if comment.DeletedAt != nil {
+					return nil
+				}
+
+				return tx.Model(&comment).Updates(map[string]interface{}{"message": "", "deleted_at": time.Now()}).Error
+			}
+
+			if err := tx.Delete(&domain.Comment{}, comment.ID).Error; err != nil {
+				return err
+			}
+
+			if comment.ParentID == nil {
+				return nil
+			}
+
+			parentID := *comment.ParentID
+			comment = domain.Comment{}
+
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("deleted_at IS NOT NULL").First(&comment, parentID).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	})
+}
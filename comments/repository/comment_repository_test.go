@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"testing"
+
+	"mygram-api/database/dbtest"
+	"mygram-api/models/domain"
+)
+
+func TestUpdateKeepsTheAuthor(t *testing.T) {
+	db := dbtest.Open(t)
+
+	owner := domain.User{Username: "owner", Email: "owner@example.com", Password: "secret", Age: 20}
+	moderator := domain.User{Username: "moderator", Email: "moderator@example.com", Password: "secret", Age: 20, Role: domain.RoleModerator}
+
+	for _, user := range []*domain.User{&owner, &moderator} {
+		if err := db.Create(user).Error; err != nil {
+			t.Fatalf("creating user: %v", err)
+		}
+	}
+
+	photo := domain.Photo{Title: "photo", PhotoUrl: "https://example.com/photo.jpg", UserID: owner.ID}
+	if err := db.Create(&photo).Error; err != nil {
+		t.Fatalf("creating photo: %v", err)
+	}
+
+	repository := NewCommentRepository(db)
+
+	comment := domain.Comment{UserID: owner.ID, PhotoID: photo.ID, Message: "first"}
+	if err := repository.Create(&comment); err != nil {
+		t.Fatalf("creating comment: %v", err)
+	}
+
+	// a moderator's edit carrying their own id must not take the comment over
+	updated, err := repository.Update(domain.Comment{ID: comment.ID, UserID: moderator.ID, Message: "edited"})
+	if err != nil {
+		t.Fatalf("updating comment: %v", err)
+	}
+
+	stored, err := repository.GetOne(comment.ID)
+	if err != nil {
+		t.Fatalf("getting comment: %v", err)
+	}
+
+	for _, got := range []domain.Comment{updated, stored} {
+		if got.UserID != owner.ID {
+			t.Errorf("UserID = %d, want the owner %d", got.UserID, owner.ID)
+		}
+
+		if got.Message != "edited" {
+			t.Errorf("Message = %q, want %q", got.Message, "edited")
+		}
+	}
+}
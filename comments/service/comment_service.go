@@ -1,37 +1,110 @@
 package service
 
 import (
-	"mygram-api/models/domain"
+	"errors"
+	"log"
+
 	"mygram-api/comments/repository"
+	"mygram-api/config"
+	"mygram-api/database"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+)
+
+var (
+	ErrCommentDeleted   = errors.New("comment has been deleted")
+	ErrParentOtherPhoto = errors.New("parent comment is on another photo")
+	ErrReplyTooDeep     = errors.New("replies cannot be nested any deeper")
 )
 
+// MentionSync keeps the users mentioned by a comment in sync with its message
+type MentionSync interface {
+	SyncCommentMentions(comment domain.Comment) (err error)
+}
+
+// CommentNotifier is told about every new comment, to notify the owner of
+// the photo and the author of the comment replied to
+type CommentNotifier interface {
+	Commented(comment domain.Comment)
+}
+
+// CommentPublisher pushes every new comment, loaded with its author, to the
+// clients following the comments of its photo
+type CommentPublisher interface {
+	CommentCreated(comment domain.Comment)
+}
+
+// CommentWebhooks is told about the comment events, to queue them for the
+// webhooks of the author and of the owner of the photo
+type CommentWebhooks interface {
+	CommentEvent(event string, comment domain.Comment)
+}
+
 type CommentService interface {
 	Create(comment *domain.Comment) (err error)
-	GetAll() (comments []domain.Comment, err error)
+	GetAll(list request.CommentListRequest) (comments []domain.Comment, page database.Page, err error)
 	GetOne(id uint) (comment domain.Comment, err error)
+	GetThread(photoID uint, list request.ListRequest) (comments []domain.Comment, replyCounts map[uint]int64, page database.Page, err error)
 	Update(comment domain.Comment) (updatedComment domain.Comment, err error)
 	Delete(id uint) (err error)
 }
 
 type CommentServiceRepository struct {
 	CommentRepository repository.CommentRepository
+	MentionSync       MentionSync
+	CommentNotifier   CommentNotifier
+	CommentPublisher  CommentPublisher
+	CommentWebhooks   CommentWebhooks
+	Config            config.CommentsConfig
 }
 
-func NewCommentService(commentRepository repository.CommentRepository) CommentService {
-	return &CommentServiceRepository{CommentRepository: commentRepository}
+func NewCommentService(commentRepository repository.CommentRepository, mentionSync MentionSync, commentNotifier CommentNotifier, commentPublisher CommentPublisher, commentWebhooks CommentWebhooks, commentsConfig config.CommentsConfig) CommentService {
+	return &CommentServiceRepository{CommentRepository: commentRepository, MentionSync: mentionSync, CommentNotifier: commentNotifier, CommentPublisher: commentPublisher, CommentWebhooks: commentWebhooks, Config: commentsConfig}
 }
 
+// Create stores a comment, or a reply when its ParentID is set
 func (commentService *CommentServiceRepository) Create(comment *domain.Comment) (err error) {
 
+	if comment.ParentID != nil {
+		var parent domain.Comment
+
+		if parent, err = commentService.CommentRepository.GetOne(*comment.ParentID); err != nil {
+			return
+		}
+
+		switch {
+		case parent.PhotoID != comment.PhotoID:
+			return ErrParentOtherPhoto
+		case parent.DeletedAt != nil:
+			return ErrCommentDeleted
+		case parent.Depth >= commentService.Config.MaxDepth:
+			return ErrReplyTooDeep
+		}
+
+		comment.Depth = parent.Depth + 1
+	}
+
 	if err = commentService.CommentRepository.Create(comment); err != nil {
 		return
 	}
+
+	commentService.syncMentions(*comment)
+
+	commentService.CommentNotifier.Commented(*comment)
+
+	if created, err := commentService.CommentRepository.GetOne(comment.ID); err != nil {
+		log.Printf("publishing comment %d: %v", comment.ID, err)
+	} else {
+		commentService.CommentPublisher.CommentCreated(created)
+		commentService.CommentWebhooks.CommentEvent(domain.WebhookEventCommentCreated, created)
+	}
+
 	return
 }
 
-func (commentService *CommentServiceRepository) GetAll() (comments []domain.Comment, err error) {
+func (commentService *CommentServiceRepository) GetAll(list request.CommentListRequest) (comments []domain.Comment, page database.Page, err error) {
 
-	if comments, err = commentService.CommentRepository.GetAll(); err != nil {
+	if comments, page, err = commentService.CommentRepository.GetAll(list); err != nil {
 		return
 	}
 
@@ -46,20 +119,93 @@ func (commentService *CommentServiceRepository) GetOne(id uint) (comment domain.
 	return
 }
 
+// GetThread loads a page of the top level comments of a photo with their
+// replies nested in Replies, down to the configured depth
+func (commentService *CommentServiceRepository) GetThread(photoID uint, list request.ListRequest) (comments []domain.Comment, replyCounts map[uint]int64, page database.Page, err error) {
+
+	if comments, page, err = commentService.CommentRepository.GetRootsByPhoto(photoID, list); err != nil {
+		return
+	}
+
+	ids := make([]uint, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, comment.ID)
+	}
+
+	replies, err := commentService.CommentRepository.GetReplies(ids, commentService.Config.MaxDepth)
+	if err != nil {
+		return
+	}
+
+	children := make(map[uint][]domain.Comment)
+	for _, reply := range replies {
+		ids = append(ids, reply.ID)
+		children[*reply.ParentID] = append(children[*reply.ParentID], reply)
+	}
+
+	if replyCounts, err = commentService.CommentRepository.CountReplies(ids); err != nil {
+		return
+	}
+
+	for i := range comments {
+		attachReplies(&comments[i], children)
+	}
+
+	return
+}
+
+// syncMentions updates the mentions of a stored comment from its message. A
+// failure is logged rather than returned, failing the request would only get
+// a comment that is already stored posted again on retry.
+func (commentService *CommentServiceRepository) syncMentions(comment domain.Comment) {
+	if err := commentService.MentionSync.SyncCommentMentions(comment); err != nil {
+		log.Printf("syncing mentions of comment %d: %v", comment.ID, err)
+	}
+}
+
+func attachReplies(comment *domain.Comment, children map[uint][]domain.Comment) {
+	comment.Replies = children[comment.ID]
+
+	for i := range comment.Replies {
+		attachReplies(&comment.Replies[i], children)
+	}
+}
+
 func (commentService *CommentServiceRepository) Update(comment domain.Comment) (updatedComment domain.Comment, err error) {
 
+	var current domain.Comment
+
+	if current, err = commentService.CommentRepository.GetOne(comment.ID); err != nil {
+		return
+	}
+
+	if current.DeletedAt != nil {
+		return updatedComment, ErrCommentDeleted
+	}
+
 	if updatedComment, err = commentService.CommentRepository.Update(comment); err != nil {
 		return
 	}
 
+	commentService.syncMentions(updatedComment)
+
+	commentService.CommentWebhooks.CommentEvent(domain.WebhookEventCommentUpdated, updatedComment)
+
 	return
 }
 
 func (commentService *CommentServiceRepository) Delete(id uint) (err error) {
 
+	comment, err := commentService.CommentRepository.GetOne(id)
+	if err != nil {
+		return
+	}
+
 	if err = commentService.CommentRepository.Delete(id); err != nil {
 		return
 	}
 
+	commentService.CommentWebhooks.CommentEvent(domain.WebhookEventCommentDeleted, comment)
+
 	return
 }
\ No newline at end of file
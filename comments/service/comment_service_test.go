@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"mygram-api/comments/repository"
+	"mygram-api/config"
+	"mygram-api/models/domain"
+)
+
+// memoryComments stores comments in memory, the methods the tests do not
+// use are left to the nil interface
+type memoryComments struct {
+	repository.CommentRepository
+
+	comments map[uint]domain.Comment
+}
+
+func (memory *memoryComments) Create(comment *domain.Comment) error {
+	comment.ID = uint(len(memory.comments) + 1)
+	memory.comments[comment.ID] = *comment
+	return nil
+}
+
+func (memory *memoryComments) GetOne(id uint) (domain.Comment, error) {
+	return memory.comments[id], nil
+}
+
+func (memory *memoryComments) Update(comment domain.Comment) (domain.Comment, error) {
+	stored := memory.comments[comment.ID]
+	stored.Message = comment.Message
+	memory.comments[comment.ID] = stored
+	return stored, nil
+}
+
+type failingMentionSync struct{}
+
+func (failingMentionSync) SyncCommentMentions(domain.Comment) error {
+	return errors.New("database unavailable")
+}
+
+type recordingHooks struct {
+	commented int
+	created   int
+	events    []string
+}
+
+func (hooks *recordingHooks) Commented(domain.Comment)      { hooks.commented++ }
+func (hooks *recordingHooks) CommentCreated(domain.Comment) { hooks.created++ }
+func (hooks *recordingHooks) CommentEvent(event string, _ domain.Comment) {
+	hooks.events = append(hooks.events, event)
+}
+
+func TestMentionSyncFailureDoesNotFailTheComment(t *testing.T) {
+	comments := &memoryComments{comments: make(map[uint]domain.Comment)}
+	hooks := &recordingHooks{}
+
+	commentService := NewCommentService(comments, failingMentionSync{}, hooks, hooks, hooks, config.CommentsConfig{MaxDepth: 5})
+
+	comment := domain.Comment{UserID: 1, PhotoID: 1, Message: "hi @someone"}
+	if err := commentService.Create(&comment); err != nil {
+		t.Fatalf("Create failed for a stored comment, a retry would post it twice: %v", err)
+	}
+
+	if _, err := commentService.Update(domain.Comment{ID: comment.ID, Message: "hi @someone_else"}); err != nil {
+		t.Fatalf("Update failed for a stored edit: %v", err)
+	}
+
+	if len(comments.comments) != 1 {
+		t.Errorf("stored %d comments, want 1", len(comments.comments))
+	}
+
+	if hooks.commented != 1 || hooks.created != 1 {
+		t.Errorf("notified %d and published %d times, want once each", hooks.commented, hooks.created)
+	}
+
+	if len(hooks.events) != 2 || hooks.events[0] != domain.WebhookEventCommentCreated || hooks.events[1] != domain.WebhookEventCommentUpdated {
+		t.Errorf("webhook events %v, want created then updated", hooks.events)
+	}
+}
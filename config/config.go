@@ -0,0 +1,706 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Config represents the application configuration
+type Config struct {
+	App      AppConfig      `yaml:"app"`
+	Database DatabaseConfig `yaml:"database"`
+	JWT      JWTConfig      `yaml:"jwt"`
+	Storage  StorageConfig  `yaml:"storage"`
+
+	PhotoVariants PhotoVariantsConfig `yaml:"photo_variants"`
+	Feed          FeedConfig          `yaml:"feed"`
+	Comments      CommentsConfig      `yaml:"comments"`
+	Realtime      RealtimeConfig      `yaml:"realtime"`
+	Webhooks      WebhooksConfig      `yaml:"webhooks"`
+	Jobs          JobsConfig          `yaml:"jobs"`
+	Accounts      AccountsConfig      `yaml:"accounts"`
+	Mail          MailConfig          `yaml:"mail"`
+}
+
+// AppConfig represents the http server configuration
+type AppConfig struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+
+	// ShutdownTimeout bounds how long in-flight requests may drain on SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+// DatabaseConfig represents the database connection configuration
+type DatabaseConfig struct {
+	Host     string `yaml:"host"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Port     int    `yaml:"port"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+
+	// AutoMigrate lets gorm sync the schema on boot, for local development only
+	AutoMigrate bool `yaml:"auto_migrate"`
+}
+
+// JWTConfig represents the token signing configuration
+type JWTConfig struct {
+	SecretKey       string        `yaml:"secret_key"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+}
+
+const (
+	StorageDriverLocal = "local"
+	StorageDriverS3    = "s3"
+)
+
+// defaultLocalPublicURL is where the api serves local uploads when
+// STORAGE_PUBLIC_URL is not set
+const defaultLocalPublicURL = "http://localhost:8080/uploads"
+
+// StorageConfig represents where uploaded photos are kept
+type StorageConfig struct {
+	Driver string `yaml:"driver"`
+
+	// PublicURL is the prefix of the URL clients download blobs from, see
+	// PublicBaseURL for what applies when it is left empty
+	PublicURL string `yaml:"public_url"`
+
+	// MaxUploadSize is the largest accepted upload in bytes
+	MaxUploadSize int `yaml:"max_upload_size"`
+
+	// StripMetadata removes EXIF, XMP and comments from stored originals
+	StripMetadata bool `yaml:"strip_metadata"`
+
+	Local LocalStorageConfig `yaml:"local"`
+	S3    S3Config           `yaml:"s3"`
+}
+
+// LocalStorageConfig represents the filesystem storage backend
+type LocalStorageConfig struct {
+	Dir string `yaml:"dir"`
+}
+
+// S3Config represents an S3-compatible storage backend such as AWS S3 or MinIO
+type S3Config struct {
+	Endpoint        string `yaml:"endpoint"`
+	Region          string `yaml:"region"`
+	Bucket          string `yaml:"bucket"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+
+	// PathStyle addresses objects as endpoint/bucket/key, which MinIO needs
+	PathStyle bool `yaml:"path_style"`
+}
+
+// PhotoVariantsConfig represents the background generation of resized photo copies
+type PhotoVariantsConfig struct {
+	Workers     int `yaml:"workers"`
+	JPEGQuality int `yaml:"jpeg_quality"`
+
+	// CwebpPath is the cwebp binary used for the WebP copies, which are skipped when it is empty or missing
+	CwebpPath string `yaml:"cwebp_path"`
+}
+
+// FeedConfig represents how the home feed of a user is built
+type FeedConfig struct {
+	// FanOutMinFollowing is the number of followed users from which a user gets
+	// a materialized timeline filled on write, 0 serves every feed on read
+	FanOutMinFollowing int `yaml:"fanout_min_following"`
+}
+
+// CommentsConfig represents how deep replies to comments can nest
+type CommentsConfig struct {
+	// MaxDepth is the number of reply levels under a top level comment
+	MaxDepth int `yaml:"max_depth"`
+}
+
+const (
+	RealtimeBrokerLocal    = "local"
+	RealtimeBrokerPostgres = "postgres"
+)
+
+// RealtimeConfig represents the push of events to connected clients
+type RealtimeConfig struct {
+	// Broker is local to push within this process, or postgres to fan out
+	// through LISTEN/NOTIFY to every instance sharing the database
+	Broker string `yaml:"broker"`
+
+	// KeepAlive is how often an idle stream is pinged
+	KeepAlive time.Duration `yaml:"keep_alive"`
+
+	// SessionCheck is how often an open stream checks its session is still
+	// valid, so signing out everywhere or a ban closes it
+	SessionCheck time.Duration `yaml:"session_check"`
+}
+
+// WebhooksConfig represents the delivery of events to the webhooks of users
+type WebhooksConfig struct {
+	Workers int `yaml:"workers"`
+
+	// MaxAttempts is how many times a delivery is tried before it is marked failed
+	MaxAttempts int `yaml:"max_attempts"`
+
+	// Timeout bounds a single attempt, connecting included
+	Timeout time.Duration `yaml:"timeout"`
+
+	// AllowPrivateNetworks lets webhooks reach loopback and private addresses,
+	// which would otherwise expose the internal network, for local development only
+	AllowPrivateNetworks bool `yaml:"allow_private_networks"`
+}
+
+// JobsConfig represents the background job queue. The photo variants and
+// webhooks queues take their number of workers from their own settings.
+type JobsConfig struct {
+	// Workers is the number of workers of the default queue, running the cleanup among others
+	Workers int `yaml:"workers"`
+
+	// PollInterval is how often idle workers look for due jobs
+	PollInterval time.Duration `yaml:"poll_interval"`
+
+	// Timeout bounds a single run of a job, after which it is cancelled and retried
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Retention is how long finished jobs are kept before the cleanup removes them
+	Retention time.Duration `yaml:"retention"`
+
+	// RunInServer runs the workers in the http server process too, turn it off
+	// when they run separately with the worker command
+	RunInServer bool `yaml:"run_in_server"`
+}
+
+// AccountsConfig represents the email verification and password reset of accounts
+type AccountsConfig struct {
+	// RequireVerifiedEmail refuses to log in users who have not verified their email yet
+	RequireVerifiedEmail bool `yaml:"require_verified_email"`
+
+	VerificationTTL  time.Duration `yaml:"verification_ttl"`
+	PasswordResetTTL time.Duration `yaml:"password_reset_ttl"`
+
+	// LinkBaseURL is where the client pages linked from the emails live, the
+	// links are LinkBaseURL/verify-email?token=... and LinkBaseURL/reset-password?token=...
+	LinkBaseURL string `yaml:"link_base_url"`
+}
+
+const (
+	MailDriverSMTP = "smtp"
+	MailDriverFile = "file"
+	MailDriverLog  = "log"
+)
+
+// MailConfig represents how emails are sent
+type MailConfig struct {
+	Driver string `yaml:"driver"`
+
+	// From is the sender of every email, such as MyGram <no-reply@example.com>
+	From string `yaml:"from"`
+
+	// Timeout bounds the sending of a single email
+	Timeout time.Duration `yaml:"timeout"`
+
+	SMTP SMTPConfig     `yaml:"smtp"`
+	File FileMailConfig `yaml:"file"`
+}
+
+// SMTPConfig represents the smtp mail driver. STARTTLS is used whenever the
+// server offers it, and the credentials are only sent over TLS or to localhost.
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+
+	// RequireTLS refuses to send an email to a server that does not offer
+	// STARTTLS, rather than sending it in clear text
+	RequireTLS bool `yaml:"require_tls"`
+}
+
+// FileMailConfig represents the file mail driver, which writes every email
+// to a .eml file instead of sending it
+type FileMailConfig struct {
+	Dir string `yaml:"dir"`
+}
+
+// Address returns the address the http server listens on
+func (app AppConfig) Address() string {
+	return fmt.Sprintf("%s:%d", app.Host, app.Port)
+}
+
+// PublicBaseURL returns PublicURL or, when it is empty, the driver's own
+// address: /uploads on this api for local storage and nothing for s3, whose
+// blobs are then addressed on the bucket itself
+func (storage StorageConfig) PublicBaseURL() string {
+	if storage.PublicURL == "" && storage.Driver == StorageDriverLocal {
+		return defaultLocalPublicURL
+	}
+
+	return storage.PublicURL
+}
+
+// DSN returns the postgres connection string
+func (database DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s", database.Host, database.User, database.Password, database.Name, database.Port, database.SSLMode)
+}
+
+// Load builds the configuration from the defaults, an optional YAML file,
+// a .env file and the environment, in increasing order of precedence.
+func Load() (*Config, error) {
+	return load((*Config).Validate)
+}
+
+// LoadDatabase builds the configuration like Load but only validates the
+// database settings, for commands such as migrate that need nothing else
+func LoadDatabase() (*Config, error) {
+	return load(func(config *Config) error {
+		return invalid(config.Database.problems())
+	})
+}
+
+func load(validate func(*Config) error) (*Config, error) {
+	config := Default()
+
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("loading .env: %w", err)
+	}
+
+	if err := config.loadFile(os.Getenv("CONFIG_FILE")); err != nil {
+		return nil, err
+	}
+
+	if err := config.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := validate(config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Default returns the configuration used when nothing else is set
+func Default() *Config {
+	return &Config{
+		App: AppConfig{
+			Port: 8080,
+
+			ReadTimeout:       60 * time.Second,
+			ReadHeaderTimeout: 10 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Host:    "localhost",
+			User:    "postgres",
+			Port:    5432,
+			Name:    "mygram-api",
+			SSLMode: "disable",
+
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		JWT: JWTConfig{
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 30 * 24 * time.Hour,
+		},
+		Storage: StorageConfig{
+			Driver:        StorageDriverLocal,
+			MaxUploadSize: 10 << 20,
+			StripMetadata: true,
+			Local: LocalStorageConfig{
+				Dir: "uploads",
+			},
+			S3: S3Config{
+				Region: "us-east-1",
+			},
+		},
+		PhotoVariants: PhotoVariantsConfig{
+			Workers:     2,
+			JPEGQuality: 82,
+			CwebpPath:   "cwebp",
+		},
+		Comments: CommentsConfig{
+			MaxDepth: 5,
+		},
+		Realtime: RealtimeConfig{
+			Broker:       RealtimeBrokerLocal,
+			KeepAlive:    25 * time.Second,
+			SessionCheck: time.Minute,
+		},
+		Webhooks: WebhooksConfig{
+			Workers:     2,
+			MaxAttempts: 8,
+			Timeout:     10 * time.Second,
+		},
+		Jobs: JobsConfig{
+			Workers:      1,
+			PollInterval: time.Second,
+			Timeout:      5 * time.Minute,
+			Retention:    7 * 24 * time.Hour,
+			RunInServer:  true,
+		},
+		Accounts: AccountsConfig{
+			VerificationTTL:  48 * time.Hour,
+			PasswordResetTTL: time.Hour,
+			LinkBaseURL:      "http://localhost:3000",
+		},
+		Mail: MailConfig{
+			Driver:  MailDriverFile,
+			From:    "MyGram <no-reply@mygram.local>",
+			Timeout: 10 * time.Second,
+			SMTP: SMTPConfig{
+				Host: "localhost",
+				Port: 587,
+			},
+			File: FileMailConfig{
+				Dir: "mail",
+			},
+		},
+	}
+}
+
+func (config *Config) loadFile(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	if err = yaml.Unmarshal(file, config); err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (config *Config) loadEnv() error {
+	var errs []error
+
+	lookupString(&config.App.Host, "APP_HOST")
+	errs = append(errs, lookupInt(&config.App.Port, "APP_PORT"))
+	errs = append(errs, lookupDuration(&config.App.ReadTimeout, "APP_READ_TIMEOUT"))
+	errs = append(errs, lookupDuration(&config.App.ReadHeaderTimeout, "APP_READ_HEADER_TIMEOUT"))
+	errs = append(errs, lookupDuration(&config.App.WriteTimeout, "APP_WRITE_TIMEOUT"))
+	errs = append(errs, lookupDuration(&config.App.IdleTimeout, "APP_IDLE_TIMEOUT"))
+	errs = append(errs, lookupDuration(&config.App.ShutdownTimeout, "APP_SHUTDOWN_TIMEOUT"))
+
+	lookupString(&config.Database.Host, "DB_HOST")
+	lookupString(&config.Database.User, "DB_USER")
+	lookupString(&config.Database.Password, "DB_PASSWORD")
+	errs = append(errs, lookupInt(&config.Database.Port, "DB_PORT"))
+	lookupString(&config.Database.Name, "DB_NAME")
+	lookupString(&config.Database.SSLMode, "DB_SSLMODE")
+	errs = append(errs, lookupInt(&config.Database.MaxOpenConns, "DB_MAX_OPEN_CONNS"))
+	errs = append(errs, lookupInt(&config.Database.MaxIdleConns, "DB_MAX_IDLE_CONNS"))
+	errs = append(errs, lookupDuration(&config.Database.ConnMaxLifetime, "DB_CONN_MAX_LIFETIME"))
+	errs = append(errs, lookupDuration(&config.Database.ConnMaxIdleTime, "DB_CONN_MAX_IDLE_TIME"))
+	errs = append(errs, lookupBool(&config.Database.AutoMigrate, "DB_AUTO_MIGRATE"))
+
+	lookupString(&config.JWT.SecretKey, "JWT_SECRET_KEY")
+	errs = append(errs, lookupDuration(&config.JWT.AccessTokenTTL, "JWT_ACCESS_TOKEN_TTL"))
+	errs = append(errs, lookupDuration(&config.JWT.RefreshTokenTTL, "JWT_REFRESH_TOKEN_TTL"))
+
+	lookupString(&config.Storage.Driver, "STORAGE_DRIVER")
+	lookupString(&config.Storage.PublicURL, "STORAGE_PUBLIC_URL")
+	errs = append(errs, lookupInt(&config.Storage.MaxUploadSize, "STORAGE_MAX_UPLOAD_SIZE"))
+	errs = append(errs, lookupBool(&config.Storage.StripMetadata, "STORAGE_STRIP_METADATA"))
+	lookupString(&config.Storage.Local.Dir, "STORAGE_LOCAL_DIR")
+	lookupString(&config.Storage.S3.Endpoint, "S3_ENDPOINT")
+	lookupString(&config.Storage.S3.Region, "S3_REGION")
+	lookupString(&config.Storage.S3.Bucket, "S3_BUCKET")
+	lookupString(&config.Storage.S3.AccessKeyID, "S3_ACCESS_KEY_ID")
+	lookupString(&config.Storage.S3.SecretAccessKey, "S3_SECRET_ACCESS_KEY")
+	errs = append(errs, lookupBool(&config.Storage.S3.PathStyle, "S3_PATH_STYLE"))
+
+	errs = append(errs, lookupInt(&config.PhotoVariants.Workers, "PHOTO_VARIANTS_WORKERS"))
+	errs = append(errs, lookupInt(&config.PhotoVariants.JPEGQuality, "PHOTO_VARIANTS_JPEG_QUALITY"))
+	lookupString(&config.PhotoVariants.CwebpPath, "PHOTO_VARIANTS_CWEBP_PATH")
+	errs = append(errs, lookupInt(&config.Feed.FanOutMinFollowing, "FEED_FANOUT_MIN_FOLLOWING"))
+	errs = append(errs, lookupInt(&config.Comments.MaxDepth, "COMMENTS_MAX_DEPTH"))
+	lookupString(&config.Realtime.Broker, "REALTIME_BROKER")
+	errs = append(errs, lookupDuration(&config.Realtime.KeepAlive, "REALTIME_KEEP_ALIVE"))
+	errs = append(errs, lookupDuration(&config.Realtime.SessionCheck, "REALTIME_SESSION_CHECK"))
+	errs = append(errs, lookupInt(&config.Webhooks.Workers, "WEBHOOKS_WORKERS"))
+	errs = append(errs, lookupInt(&config.Webhooks.MaxAttempts, "WEBHOOKS_MAX_ATTEMPTS"))
+	errs = append(errs, lookupDuration(&config.Webhooks.Timeout, "WEBHOOKS_TIMEOUT"))
+	errs = append(errs, lookupBool(&config.Webhooks.AllowPrivateNetworks, "WEBHOOKS_ALLOW_PRIVATE_NETWORKS"))
+	errs = append(errs, lookupInt(&config.Jobs.Workers, "JOBS_WORKERS"))
+	errs = append(errs, lookupDuration(&config.Jobs.PollInterval, "JOBS_POLL_INTERVAL"))
+	errs = append(errs, lookupDuration(&config.Jobs.Timeout, "JOBS_TIMEOUT"))
+	errs = append(errs, lookupDuration(&config.Jobs.Retention, "JOBS_RETENTION"))
+	errs = append(errs, lookupBool(&config.Jobs.RunInServer, "JOBS_RUN_IN_SERVER"))
+	errs = append(errs, lookupBool(&config.Accounts.RequireVerifiedEmail, "ACCOUNTS_REQUIRE_VERIFIED_EMAIL"))
+	errs = append(errs, lookupDuration(&config.Accounts.VerificationTTL, "ACCOUNTS_VERIFICATION_TTL"))
+	errs = append(errs, lookupDuration(&config.Accounts.PasswordResetTTL, "ACCOUNTS_PASSWORD_RESET_TTL"))
+	lookupString(&config.Accounts.LinkBaseURL, "ACCOUNTS_LINK_BASE_URL")
+	lookupString(&config.Mail.Driver, "MAIL_DRIVER")
+	lookupString(&config.Mail.From, "MAIL_FROM")
+	errs = append(errs, lookupDuration(&config.Mail.Timeout, "MAIL_TIMEOUT"))
+	lookupString(&config.Mail.SMTP.Host, "SMTP_HOST")
+	errs = append(errs, lookupInt(&config.Mail.SMTP.Port, "SMTP_PORT"))
+	lookupString(&config.Mail.SMTP.Username, "SMTP_USERNAME")
+	lookupString(&config.Mail.SMTP.Password, "SMTP_PASSWORD")
+	errs = append(errs, lookupBool(&config.Mail.SMTP.RequireTLS, "SMTP_REQUIRE_TLS"))
+	lookupString(&config.Mail.File.Dir, "MAIL_FILE_DIR")
+
+	return errors.Join(errs...)
+}
+
+// Validate reports every missing or invalid setting at once
+func (config *Config) Validate() error {
+	var problems []string
+
+	if config.App.Port <= 0 || config.App.Port > 65535 {
+		problems = append(problems, "APP_PORT must be between 1 and 65535")
+	}
+
+	if config.App.ShutdownTimeout <= 0 {
+		problems = append(problems, "APP_SHUTDOWN_TIMEOUT must be positive")
+	}
+
+	problems = append(problems, config.Database.problems()...)
+
+	if config.JWT.SecretKey == "" {
+		problems = append(problems, "JWT_SECRET_KEY is required")
+	} else if len(config.JWT.SecretKey) < 32 {
+		problems = append(problems, "JWT_SECRET_KEY must be at least 32 characters long")
+	}
+
+	if config.JWT.AccessTokenTTL <= 0 || config.JWT.RefreshTokenTTL <= 0 {
+		problems = append(problems, "JWT_ACCESS_TOKEN_TTL and JWT_REFRESH_TOKEN_TTL must be positive")
+	} else if config.JWT.AccessTokenTTL >= config.JWT.RefreshTokenTTL {
+		problems = append(problems, "JWT_ACCESS_TOKEN_TTL must be shorter than JWT_REFRESH_TOKEN_TTL")
+	}
+
+	if config.Storage.MaxUploadSize <= 0 {
+		problems = append(problems, "STORAGE_MAX_UPLOAD_SIZE must be positive")
+	}
+
+	switch config.Storage.Driver {
+	case StorageDriverLocal:
+		if config.Storage.Local.Dir == "" {
+			problems = append(problems, "STORAGE_LOCAL_DIR is required for the local storage driver")
+		}
+
+		if publicURL, err := url.Parse(config.Storage.PublicBaseURL()); err != nil || strings.Trim(publicURL.Path, "/") == "" {
+			problems = append(problems, "STORAGE_PUBLIC_URL must be a URL with a path, such as http://localhost:8080/uploads, for the local storage driver")
+		}
+	case StorageDriverS3:
+		if config.Storage.S3.Endpoint == "" || config.Storage.S3.Bucket == "" || config.Storage.S3.Region == "" {
+			problems = append(problems, "S3_ENDPOINT, S3_BUCKET and S3_REGION are required for the s3 storage driver")
+		}
+
+		if config.Storage.S3.AccessKeyID == "" || config.Storage.S3.SecretAccessKey == "" {
+			problems = append(problems, "S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required for the s3 storage driver")
+		}
+	default:
+		problems = append(problems, "STORAGE_DRIVER must be local or s3")
+	}
+
+	if config.PhotoVariants.Workers < 1 {
+		problems = append(problems, "PHOTO_VARIANTS_WORKERS must be at least 1")
+	}
+
+	if config.PhotoVariants.JPEGQuality < 1 || config.PhotoVariants.JPEGQuality > 100 {
+		problems = append(problems, "PHOTO_VARIANTS_JPEG_QUALITY must be between 1 and 100")
+	}
+
+	if config.Feed.FanOutMinFollowing < 0 {
+		problems = append(problems, "FEED_FANOUT_MIN_FOLLOWING must not be negative")
+	}
+
+	if config.Comments.MaxDepth < 0 {
+		problems = append(problems, "COMMENTS_MAX_DEPTH must not be negative")
+	}
+
+	if config.Realtime.Broker != RealtimeBrokerLocal && config.Realtime.Broker != RealtimeBrokerPostgres {
+		problems = append(problems, "REALTIME_BROKER must be local or postgres")
+	}
+
+	if config.Realtime.KeepAlive <= 0 {
+		problems = append(problems, "REALTIME_KEEP_ALIVE must be positive")
+	}
+
+	if config.Realtime.SessionCheck <= 0 {
+		problems = append(problems, "REALTIME_SESSION_CHECK must be positive")
+	}
+
+	if config.Webhooks.Workers < 1 {
+		problems = append(problems, "WEBHOOKS_WORKERS must be at least 1")
+	}
+
+	if config.Webhooks.MaxAttempts < 1 {
+		problems = append(problems, "WEBHOOKS_MAX_ATTEMPTS must be at least 1")
+	}
+
+	if config.Webhooks.Timeout <= 0 {
+		problems = append(problems, "WEBHOOKS_TIMEOUT must be positive")
+	}
+
+	if config.Jobs.Workers < 1 {
+		problems = append(problems, "JOBS_WORKERS must be at least 1")
+	}
+
+	if config.Jobs.PollInterval <= 0 || config.Jobs.Timeout <= 0 || config.Jobs.Retention <= 0 {
+		problems = append(problems, "JOBS_POLL_INTERVAL, JOBS_TIMEOUT and JOBS_RETENTION must be positive")
+	}
+
+	if config.Accounts.VerificationTTL <= 0 || config.Accounts.PasswordResetTTL <= 0 {
+		problems = append(problems, "ACCOUNTS_VERIFICATION_TTL and ACCOUNTS_PASSWORD_RESET_TTL must be positive")
+	}
+
+	if linkBaseURL, err := url.Parse(config.Accounts.LinkBaseURL); err != nil || !linkBaseURL.IsAbs() {
+		problems = append(problems, "ACCOUNTS_LINK_BASE_URL must be an absolute URL")
+	}
+
+	if _, err := mail.ParseAddress(config.Mail.From); err != nil {
+		problems = append(problems, "MAIL_FROM must be an email address, optionally with a name")
+	}
+
+	if config.Mail.Timeout <= 0 {
+		problems = append(problems, "MAIL_TIMEOUT must be positive")
+	}
+
+	switch config.Mail.Driver {
+	case MailDriverSMTP:
+		if config.Mail.SMTP.Host == "" {
+			problems = append(problems, "SMTP_HOST is required for the smtp mail driver")
+		}
+
+		if config.Mail.SMTP.Port <= 0 || config.Mail.SMTP.Port > 65535 {
+			problems = append(problems, "SMTP_PORT must be between 1 and 65535")
+		}
+	case MailDriverFile:
+		if config.Mail.File.Dir == "" {
+			problems = append(problems, "MAIL_FILE_DIR is required for the file mail driver")
+		}
+	case MailDriverLog:
+	default:
+		problems = append(problems, "MAIL_DRIVER must be smtp, file or log")
+	}
+
+	return invalid(problems)
+}
+
+// problems lists the missing or invalid database settings
+func (database DatabaseConfig) problems() (problems []string) {
+	if database.Host == "" {
+		problems = append(problems, "DB_HOST is required")
+	}
+
+	if database.User == "" {
+		problems = append(problems, "DB_USER is required")
+	}
+
+	if database.Password == "" {
+		problems = append(problems, "DB_PASSWORD is required")
+	}
+
+	if database.Name == "" {
+		problems = append(problems, "DB_NAME is required")
+	}
+
+	if database.Port <= 0 || database.Port > 65535 {
+		problems = append(problems, "DB_PORT must be between 1 and 65535")
+	}
+
+	if database.MaxOpenConns < 0 || database.MaxIdleConns < 0 {
+		problems = append(problems, "DB_MAX_OPEN_CONNS and DB_MAX_IDLE_CONNS must not be negative")
+	}
+
+	if database.MaxOpenConns > 0 && database.MaxIdleConns > database.MaxOpenConns {
+		problems = append(problems, "DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS")
+	}
+
+	return
+}
+
+// invalid joins problems into a single error, nil when there are none
+func invalid(problems []string) error {
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+func lookupString(target *string, key string) {
+	if value, ok := os.LookupEnv(key); ok {
+		*target = value
+	}
+}
+
+func lookupInt(target *int, key string) error {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%s must be an integer: %w", key, err)
+	}
+
+	*target = parsed
+
+	return nil
+}
+
+func lookupBool(target *bool, key string) error {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("%s must be true or false: %w", key, err)
+	}
+
+	*target = parsed
+
+	return nil
+}
+
+func lookupDuration(target *time.Duration, key string) error {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return nil
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%s must be a duration such as 30s or 5m: %w", key, err)
+	}
+
+	*target = parsed
+
+	return nil
+}
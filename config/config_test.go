@@ -0,0 +1,33 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadDatabaseOnlyValidatesTheDatabase(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("JWT_SECRET_KEY", "")
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "JWT_SECRET_KEY") {
+		t.Errorf("Load = %v, want the missing JWT_SECRET_KEY reported", err)
+	}
+
+	cfg, err := LoadDatabase()
+	if err != nil {
+		t.Fatalf("LoadDatabase = %v, want no error without JWT_SECRET_KEY", err)
+	}
+
+	if cfg.Database.Password != "secret" {
+		t.Errorf("Database.Password = %q, want the environment applied", cfg.Database.Password)
+	}
+
+	t.Setenv("DB_PASSWORD", "")
+	t.Setenv("DB_PORT", "0")
+
+	_, err = LoadDatabase()
+	if err == nil || !strings.Contains(err.Error(), "DB_PASSWORD") || !strings.Contains(err.Error(), "DB_PORT") {
+		t.Errorf("LoadDatabase = %v, want DB_PASSWORD and DB_PORT reported", err)
+	}
+}
@@ -1,38 +1,40 @@
 package database
 
 import (
-	"fmt"
-	"log"
-
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
+	"mygram-api/config"
 	"mygram-api/models/domain"
 )
 
-var (
-	host     = "localhost"
-	user     = "postgres"
-	password = "/"
-	dbPort   = 5432
-	dbName   = "mygram-api"
-	db       *gorm.DB
-	err      error
-)
-
-func StartDB() *gorm.DB {
+// StartDB opens the connection pool shared by the whole application
+func StartDB(databaseConfig config.DatabaseConfig) (*gorm.DB, error) {
 
-	config := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=disable", host, user, password, dbName, dbPort)
-	dsn := config
-	db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(databaseConfig.DSN()), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
 
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatal("Error connecting to database :", err)
+		return nil, err
 	}
 
-	if err := db.AutoMigrate(&domain.User{}, &domain.Photo{}, &domain.Comment{}, &domain.SocialMedia{}); err != nil {
-		log.Fatal(err.Error())
+	sqlDB.SetMaxOpenConns(databaseConfig.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(databaseConfig.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(databaseConfig.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(databaseConfig.ConnMaxIdleTime)
+
+	return db, nil
+}
+
+// AutoMigrate syncs the schema from the domain models. It is meant for local
+// development only, deployed databases are managed by the migrations package.
+func AutoMigrate(db *gorm.DB) error {
+	if err := db.SetupJoinTable(&domain.Photo{}, "Tags", &domain.PhotoTag{}); err != nil {
+		return err
 	}
 
-	return db
+	return db.AutoMigrate(&domain.User{}, &domain.Photo{}, &domain.PhotoVariant{}, &domain.Tag{}, &domain.PhotoTag{}, &domain.Comment{}, &domain.PhotoMention{}, &domain.CommentMention{}, &domain.Notification{}, &domain.Webhook{}, &domain.WebhookDelivery{}, &domain.Job{}, &domain.Like{}, &domain.Follow{}, &domain.Timeline{}, &domain.TimelineEntry{}, &domain.SocialMedia{}, &domain.RefreshToken{})
 }
@@ -0,0 +1,79 @@
+// Package dbtest gives a test a freshly migrated postgres schema of its own.
+// The tests using it are skipped unless MYGRAM_TEST_DATABASE_DSN points at a
+// database they may create and drop schemas in, for instance:
+//
+//	MYGRAM_TEST_DATABASE_DSN="host=localhost user=postgres password=postgres dbname=mygram_test sslmode=disable" go test ./...
+package dbtest
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"mygram-api/migrations"
+)
+
+const DSNEnv = "MYGRAM_TEST_DATABASE_DSN"
+
+// Open returns a connection pool whose search_path is a new schema with every
+// migration applied. The schema is dropped when the test ends.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv(DSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", DSNEnv)
+	}
+
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connecting to the test database: %v", err)
+	}
+
+	schema := fmt.Sprintf("test_%d_%d", time.Now().UnixNano(), rand.Intn(1_000_000))
+
+	if err = admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("creating schema %s: %v", schema, err)
+	}
+
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("parsing %s: %v", DSNEnv, err)
+	}
+
+	connConfig.RuntimeParams["search_path"] = schema
+	sqlDB := stdlib.OpenDB(*connConfig)
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connecting to schema %s: %v", schema, err)
+	}
+
+	t.Cleanup(func() {
+		sqlDB.Close()
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+
+		if adminDB, err := admin.DB(); err == nil {
+			adminDB.Close()
+		}
+	})
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		t.Fatalf("loading migrations: %v", err)
+	}
+
+	if _, err = migrator.Up(0); err != nil {
+		t.Fatalf("migrating schema %s: %v", schema, err)
+	}
+
+	return db
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"mygram-api/models/request"
+)
+
+// Page describes the slice of rows returned by Paginate
+type Page struct {
+	// Total is only counted for offset pages, keyset pages skip the count
+	Total      *int64
+	NextCursor string
+}
+
+// Paginate applies the user_id and created_after filters, the sort and either
+// the cursor or the offset of list to query, then loads one page of rows.
+// Rows are ordered by (created_at, id) so keyset pages stay stable under inserts.
+// Without a cursor nor a page the first keyset page is loaded, skipping the count.
+func Paginate[T any](query *gorm.DB, list request.ListRequest, cursorOf func(T) request.Cursor) (rows []T, page Page, err error) {
+
+	query = filter(query, list)
+
+	direction, comparison := "ASC", ">"
+	if list.Descending() {
+		direction, comparison = "DESC", "<"
+	}
+
+	if list.Cursor != "" {
+		cursor, err := request.DecodeCursor(list.Cursor)
+		if err != nil {
+			return nil, page, err
+		}
+
+		query = query.Where(fmt.Sprintf("(created_at, id) %s (?, ?)", comparison), cursor.CreatedAt, cursor.ID)
+	} else if list.Page > 0 {
+		var total int64
+
+		if err = query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return
+		}
+
+		page.Total = &total
+		query = query.Offset(list.Offset())
+	}
+
+	// one extra row tells whether there is a next page
+	if err = query.
+		Order(fmt.Sprintf("created_at %s, id %s", direction, direction)).
+		Limit(list.Limit + 1).
+		Find(&rows).Error; err != nil {
+		return
+	}
+
+	if len(rows) > list.Limit {
+		rows = rows[:list.Limit]
+		page.NextCursor = request.EncodeCursor(cursorOf(rows[len(rows)-1]))
+	}
+
+	return
+}
+
+// PaginateByRank applies the filters and the offset of list to query, then
+// loads one page of rows ordered by their rank column, best first. Ranks are
+// not unique, so ties are broken by id.
+func PaginateByRank[T any](query *gorm.DB, list request.ListRequest) (rows []T, page Page, err error) {
+
+	query = filter(query, list)
+
+	var total int64
+
+	if err = query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return
+	}
+
+	page.Total = &total
+
+	if err = query.
+		Order("rank DESC, id DESC").
+		Offset(list.Offset()).
+		Limit(list.Limit).
+		Find(&rows).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// filter applies the user_id and created_after filters of list to query
+func filter(query *gorm.DB, list request.ListRequest) *gorm.DB {
+
+	if list.UserID != 0 {
+		query = query.Where("user_id = ?", list.UserID)
+	}
+
+	if !list.CreatedAfter.IsZero() {
+		query = query.Where("created_at > ?", list.CreatedAfter)
+	}
+
+	return query
+}
@@ -0,0 +1,185 @@
+package database
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"mygram-api/models/request"
+)
+
+type item struct {
+	ID        uint
+	UserID    uint
+	CreatedAt time.Time
+}
+
+type query struct {
+	sql  string
+	vars []interface{}
+}
+
+// dryRun opens a postgres session that builds statements without a server.
+// Every query is recorded and handed back the rows of found.
+func dryRun(t *testing.T, found []item) (*gorm.DB, *[]query) {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var queries []query
+
+	err = db.Callback().Query().After("gorm:query").Register("record", func(tx *gorm.DB) {
+		queries = append(queries, query{tx.Statement.SQL.String(), tx.Statement.Vars})
+
+		if rows, ok := tx.Statement.Dest.(*[]item); ok {
+			*rows = append([]item(nil), found...)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db, &queries
+}
+
+func cursorOf(row item) request.Cursor {
+	return request.Cursor{CreatedAt: row.CreatedAt, ID: row.ID}
+}
+
+func TestPaginate(t *testing.T) {
+	at := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+	cursor := request.Cursor{CreatedAt: at, ID: 7}
+
+	tests := []struct {
+		name      string
+		list      request.ListRequest
+		wantSQL   []string
+		wantVars  [][]interface{}
+		wantTotal bool
+	}{
+		{
+			name:     "first keyset page",
+			list:     request.ListRequest{PaginationRequest: request.PaginationRequest{Limit: 2}, Sort: request.SortNewest},
+			wantSQL:  []string{`SELECT * FROM "items" ORDER BY created_at DESC, id DESC LIMIT 3`},
+			wantVars: [][]interface{}{nil},
+		},
+		{
+			name:     "after a cursor, newest first",
+			list:     request.ListRequest{PaginationRequest: request.PaginationRequest{Limit: 2}, Sort: request.SortNewest, Cursor: request.EncodeCursor(cursor)},
+			wantSQL:  []string{`SELECT * FROM "items" WHERE (created_at, id) < ($1, $2) ORDER BY created_at DESC, id DESC LIMIT 3`},
+			wantVars: [][]interface{}{{at, uint(7)}},
+		},
+		{
+			name:     "after a cursor, oldest first",
+			list:     request.ListRequest{PaginationRequest: request.PaginationRequest{Limit: 2}, Sort: request.SortOldest, Cursor: request.EncodeCursor(cursor)},
+			wantSQL:  []string{`SELECT * FROM "items" WHERE (created_at, id) > ($1, $2) ORDER BY created_at ASC, id ASC LIMIT 3`},
+			wantVars: [][]interface{}{{at, uint(7)}},
+		},
+		{
+			name: "offset page with filters",
+			list: request.ListRequest{PaginationRequest: request.PaginationRequest{Page: 3, Limit: 2}, Sort: request.SortOldest, UserID: 4, CreatedAfter: at},
+			wantSQL: []string{
+				`SELECT count(*) FROM "items" WHERE user_id = $1 AND created_at > $2`,
+				`SELECT * FROM "items" WHERE user_id = $1 AND created_at > $2 ORDER BY created_at ASC, id ASC LIMIT 3 OFFSET 4`,
+			},
+			wantVars:  [][]interface{}{{uint(4), at}, {uint(4), at}},
+			wantTotal: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, queries := dryRun(t, nil)
+
+			_, page, err := Paginate[item](db.Model(&item{}), test.list, cursorOf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(*queries) != len(test.wantSQL) {
+				t.Fatalf("ran %d queries, want %d: %+v", len(*queries), len(test.wantSQL), *queries)
+			}
+
+			for i, query := range *queries {
+				if query.sql != test.wantSQL[i] {
+					t.Errorf("query %d = %s, want %s", i, query.sql, test.wantSQL[i])
+				}
+
+				if len(query.vars) != len(test.wantVars[i]) || (len(query.vars) > 0 && !reflect.DeepEqual(query.vars, test.wantVars[i])) {
+					t.Errorf("query %d vars = %v, want %v", i, query.vars, test.wantVars[i])
+				}
+			}
+
+			if (page.Total != nil) != test.wantTotal {
+				t.Errorf("Total = %v, want counted %t", page.Total, test.wantTotal)
+			}
+		})
+	}
+}
+
+func TestPaginateNextCursor(t *testing.T) {
+	at := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+	found := []item{{ID: 9, CreatedAt: at}, {ID: 8, CreatedAt: at}, {ID: 5, CreatedAt: at.Add(-time.Hour)}}
+	list := request.ListRequest{PaginationRequest: request.PaginationRequest{Limit: 2}, Sort: request.SortNewest}
+
+	tests := []struct {
+		found      []item
+		wantRows   int
+		wantCursor string
+	}{
+		// the extra row only tells there is more, the cursor points at the last row returned
+		{found, 2, request.EncodeCursor(request.Cursor{CreatedAt: at, ID: 8})},
+		{found[:2], 2, ""},
+		{nil, 0, ""},
+	}
+
+	for _, test := range tests {
+		db, _ := dryRun(t, test.found)
+
+		rows, page, err := Paginate[item](db.Model(&item{}), list, cursorOf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(rows) != test.wantRows || page.NextCursor != test.wantCursor {
+			t.Errorf("with %d rows found got %d rows and cursor %q, want %d and %q", len(test.found), len(rows), page.NextCursor, test.wantRows, test.wantCursor)
+		}
+	}
+}
+
+func TestPaginateWithoutCursorNorPageSkipsTheCount(t *testing.T) {
+	db, queries := dryRun(t, nil)
+
+	// as bound from a query string without page nor cursor
+	var list request.ListRequest
+	list.Normalize()
+
+	if _, page, err := Paginate[item](db.Model(&item{}), list, cursorOf); err != nil || page.Total != nil {
+		t.Fatalf("Paginate = total %v, %v, want a keyset page without total", page.Total, err)
+	}
+
+	want := `SELECT * FROM "items" ORDER BY created_at DESC, id DESC LIMIT 21`
+	if len(*queries) != 1 || (*queries)[0].sql != want {
+		t.Errorf("ran %+v, want only %s", *queries, want)
+	}
+}
+
+func TestPaginateRejectsInvalidCursors(t *testing.T) {
+	db, queries := dryRun(t, nil)
+	list := request.ListRequest{PaginationRequest: request.PaginationRequest{Limit: 2}, Cursor: "not a cursor"}
+
+	if _, _, err := Paginate[item](db.Model(&item{}), list, cursorOf); !errors.Is(err, request.ErrInvalidCursor) {
+		t.Errorf("Paginate = %v, want ErrInvalidCursor", err)
+	}
+
+	if len(*queries) != 0 {
+		t.Errorf("ran %+v, want no query", *queries)
+	}
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"mygram-api/auth"
+	"mygram-api/feeds/service"
+	"mygram-api/helpers"
+	likeService "mygram-api/likes/service"
+	"mygram-api/models/request"
+	"mygram-api/models/response"
+)
+
+type FeedController interface {
+	GetFeed(c *gin.Context)
+}
+
+type FeedControllerService struct {
+	FeedService service.FeedService
+	LikeService likeService.LikeService
+}
+
+func NewFeedController(feedService service.FeedService, likeService likeService.LikeService) FeedController {
+	return &FeedControllerService{FeedService: feedService, LikeService: likeService}
+}
+
+// GetFeed godoc
+// @Summary Get the home feed
+// @Description Get a page of the photos of the users the authentication user follows, newest first
+// @Tags feed
+// @Security Bearer
+// @Produce json
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "Cursor of the next page from a previous response"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /feed [get]
+func (feedController *FeedControllerService) GetFeed(c *gin.Context) {
+
+	var cursorRequest request.CursorRequest
+
+	if err := c.ShouldBindQuery(&cursorRequest); err != nil {
+		validationError, ok := err.(validator.ValidationErrors)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+				Code:   http.StatusBadRequest,
+				Status: "Bad Request",
+				Errors: err.Error(),
+			})
+
+			return
+		}
+
+		fieldErrorResponse := make(map[string]interface{})
+
+		for _, v := range validationError {
+			fieldErrorResponse[strings.ToLower(v.Field())] = helpers.GetValidationErrorMsg(v)
+		}
+
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: fieldErrorResponse,
+		})
+
+		return
+	}
+
+	list := cursorRequest.ListRequest()
+	userID := auth.MustGetPrincipal(c).UserID
+
+	photos, page, err := feedController.FeedService.GetFeed(userID, list)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	likeSummaries, err := feedController.LikeService.GetSummaries(photos, userID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	photosResponse := []response.PhotoGetAllResponse{}
+	for _, photo := range photos {
+		photosResponse = append(photosResponse, response.NewPhotoGetAllResponse(photo, likeSummaries[photo.ID]))
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data:       photosResponse,
+		Pagination: response.NewListPaginationResponse(list, page.Total, page.NextCursor),
+	})
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"mygram-api/models/domain"
+)
+
+type TimelineRepository interface {
+	Exists(userID uint) (exists bool, err error)
+	Create(userID uint, backfill int) (err error)
+	AddFollowee(userID, followeeID uint, backfill int) (err error)
+	RemoveFollowee(userID, followeeID uint) (err error)
+	FanOut(photo domain.Photo) (err error)
+}
+
+type TimelineRepositoryDB struct {
+	DB *gorm.DB
+}
+
+func NewTimelineRepository(db *gorm.DB) TimelineRepository {
+	return &TimelineRepositoryDB{DB: db}
+}
+
+func (timelineRepository *TimelineRepositoryDB) Exists(userID uint) (exists bool, err error) {
+
+	err = timelineRepository.DB.Select("user_id").Take(&domain.Timeline{}, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Create materializes the timeline of userID with the latest backfill photos
+// of the users it follows. Creating an existing timeline is not an error.
+func (timelineRepository *TimelineRepositoryDB) Create(userID uint, backfill int) (err error) {
+
+	return timelineRepository.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&domain.Timeline{UserID: userID}).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec(`INSERT INTO timeline_entries (user_id, photo_id)
+			SELECT ?, id FROM photos
+			WHERE user_id IN (SELECT followee_id FROM follows WHERE follower_id = ?)
+			ORDER BY created_at DESC, id DESC
+			LIMIT ?
+			ON CONFLICT DO NOTHING`, userID, userID, backfill).Error
+	})
+}
+
+// AddFollowee copies the latest backfill photos of a newly followed user
+// into the timeline of userID, if it has one
+func (timelineRepository *TimelineRepositoryDB) AddFollowee(userID, followeeID uint, backfill int) (err error) {
+
+	if err = timelineRepository.DB.Exec(`INSERT INTO timeline_entries (user_id, photo_id)
+		SELECT ?, id FROM photos
+		WHERE user_id = ? AND EXISTS (SELECT 1 FROM timelines WHERE user_id = ?)
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+		ON CONFLICT DO NOTHING`, userID, followeeID, userID, backfill).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// RemoveFollowee drops the photos of an unfollowed user from the timeline of userID
+func (timelineRepository *TimelineRepositoryDB) RemoveFollowee(userID, followeeID uint) (err error) {
+
+	followeePhotos := timelineRepository.DB.Model(&domain.Photo{}).Select("id").Where("user_id = ?", followeeID)
+
+	if err = timelineRepository.DB.Where("user_id = ? AND photo_id IN (?)", userID, followeePhotos).Delete(&domain.TimelineEntry{}).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// FanOut adds a new photo to the timeline of every follower of its owner that has one
+func (timelineRepository *TimelineRepositoryDB) FanOut(photo domain.Photo) (err error) {
+
+	if err = timelineRepository.DB.Exec(`INSERT INTO timeline_entries (user_id, photo_id)
+		SELECT follows.follower_id, ? FROM follows
+		JOIN timelines ON timelines.user_id = follows.follower_id
+		WHERE follows.followee_id = ?
+		ON CONFLICT DO NOTHING`, photo.ID, photo.UserID).Error; err != nil {
+		return
+	}
+
+	return
+}
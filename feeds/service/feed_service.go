@@ -0,0 +1,121 @@
+package service
+
+import (
+	"log"
+
+	"mygram-api/config"
+	"mygram-api/database"
+	"mygram-api/feeds/repository"
+	followRepository "mygram-api/follows/repository"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+	photoRepository "mygram-api/photos/repository"
+)
+
+// timelineBackfill is how many of the latest photos are copied into a
+// timeline when it is created or its owner follows someone new
+const timelineBackfill = 1000
+
+type FeedService interface {
+	GetFeed(userID uint, list request.ListRequest) (photos []domain.Photo, page database.Page, err error)
+	FanOut(photo domain.Photo)
+	Followed(followerID, followeeID uint) (err error)
+	Unfollowed(followerID, followeeID uint) (err error)
+}
+
+// FeedServiceRepository serves the feed of most users on read, straight from
+// the photos of the users they follow. Users following at least
+// FanOutMinFollowing accounts, for whom that query gets expensive, get a
+// timeline instead which new photos are fanned out to on write.
+type FeedServiceRepository struct {
+	PhotoRepository    photoRepository.PhotoRepository
+	FollowRepository   followRepository.FollowRepository
+	TimelineRepository repository.TimelineRepository
+	Config             config.FeedConfig
+}
+
+func NewFeedService(photoRepository photoRepository.PhotoRepository, followRepository followRepository.FollowRepository, timelineRepository repository.TimelineRepository, feedConfig config.FeedConfig) FeedService {
+	return &FeedServiceRepository{PhotoRepository: photoRepository, FollowRepository: followRepository, TimelineRepository: timelineRepository, Config: feedConfig}
+}
+
+// GetFeed lists the photos of the users userID follows, newest first
+func (feedService *FeedServiceRepository) GetFeed(userID uint, list request.ListRequest) (photos []domain.Photo, page database.Page, err error) {
+
+	hasTimeline, err := feedService.hasTimeline(userID)
+	if err != nil {
+		return
+	}
+
+	if hasTimeline {
+		return feedService.PhotoRepository.GetAllInTimeline(userID, list)
+	}
+
+	return feedService.PhotoRepository.GetAllFollowedBy(userID, list)
+}
+
+// FanOut adds a new photo to the timelines of its owner's followers. It is best
+// effort, a photo missing from a timeline only means it is not in that feed.
+func (feedService *FeedServiceRepository) FanOut(photo domain.Photo) {
+
+	if !feedService.fanOutEnabled() {
+		return
+	}
+
+	if err := feedService.TimelineRepository.FanOut(photo); err != nil {
+		log.Printf("fanning out photo %d: %v", photo.ID, err)
+	}
+}
+
+// Followed keeps the timeline of the follower in sync with a new follow, and
+// creates it once the follower follows enough users
+func (feedService *FeedServiceRepository) Followed(followerID, followeeID uint) (err error) {
+
+	if !feedService.fanOutEnabled() {
+		return
+	}
+
+	hasTimeline, err := feedService.TimelineRepository.Exists(followerID)
+	if err != nil {
+		return
+	}
+
+	if hasTimeline {
+		return feedService.TimelineRepository.AddFollowee(followerID, followeeID, timelineBackfill)
+	}
+
+	following, err := feedService.FollowRepository.CountFollowing(followerID)
+	if err != nil {
+		return
+	}
+
+	if following < int64(feedService.Config.FanOutMinFollowing) {
+		return
+	}
+
+	return feedService.TimelineRepository.Create(followerID, timelineBackfill)
+}
+
+// Unfollowed drops the photos of the followee from the timeline of the follower.
+// A timeline is kept once created, even when its owner unfollows below the threshold.
+func (feedService *FeedServiceRepository) Unfollowed(followerID, followeeID uint) (err error) {
+
+	if !feedService.fanOutEnabled() {
+		return
+	}
+
+	return feedService.TimelineRepository.RemoveFollowee(followerID, followeeID)
+}
+
+func (feedService *FeedServiceRepository) fanOutEnabled() bool {
+	return feedService.Config.FanOutMinFollowing > 0
+}
+
+// hasTimeline reports whether the feed of userID is served from its timeline.
+// Timelines left over from before fan-out was disabled are stale and ignored.
+func (feedService *FeedServiceRepository) hasTimeline(userID uint) (bool, error) {
+	if !feedService.fanOutEnabled() {
+		return false, nil
+	}
+
+	return feedService.TimelineRepository.Exists(userID)
+}
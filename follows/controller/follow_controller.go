@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+
+	"mygram-api/auth"
+	"mygram-api/database"
+	"mygram-api/follows/service"
+	"mygram-api/helpers"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+	"mygram-api/models/response"
+)
+
+type FollowController interface {
+	Follow(c *gin.Context)
+	Unfollow(c *gin.Context)
+	GetFollowers(c *gin.Context)
+	GetFollowing(c *gin.Context)
+}
+
+type FollowControllerService struct {
+	FollowService service.FollowService
+}
+
+func NewFollowController(followService service.FollowService) FollowController {
+	return &FollowControllerService{FollowService: followService}
+}
+
+// Follow godoc
+// @Summary Follow a user
+// @Description Follow a user with authentication user, following it again changes nothing
+// @Tags follows
+// @Security Bearer
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /users/{username}/follow [post]
+func (followController *FollowControllerService) Follow(c *gin.Context) {
+
+	followee, err := followController.FollowService.Follow(auth.MustGetPrincipal(c).UserID, c.Param("username"))
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.FollowResponse{
+			UserID:    followee.ID,
+			Username:  followee.Username,
+			Following: true,
+		},
+	})
+}
+
+// Unfollow godoc
+// @Summary Unfollow a user
+// @Description Stop following a user with authentication user
+// @Tags follows
+// @Security Bearer
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /users/{username}/follow [delete]
+func (followController *FollowControllerService) Unfollow(c *gin.Context) {
+
+	followee, err := followController.FollowService.Unfollow(auth.MustGetPrincipal(c).UserID, c.Param("username"))
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.FollowResponse{
+			UserID:    followee.ID,
+			Username:  followee.Username,
+			Following: false,
+		},
+	})
+}
+
+// GetFollowers godoc
+// @Summary Get the followers of a user
+// @Description Get a page of the users following a user, the latest first
+// @Tags follows
+// @Security Bearer
+// @Produce json
+// @Param username path string true "Username"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "Cursor of the next page from a previous response"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /users/{username}/followers [get]
+func (followController *FollowControllerService) GetFollowers(c *gin.Context) {
+
+	followController.list(c, followController.FollowService.GetFollowers, func(follow domain.Follow) domain.User {
+		return follow.Follower
+	})
+}
+
+// GetFollowing godoc
+// @Summary Get the users a user follows
+// @Description Get a page of the users followed by a user, the latest first
+// @Tags follows
+// @Security Bearer
+// @Produce json
+// @Param username path string true "Username"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "Cursor of the next page from a previous response"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /users/{username}/following [get]
+func (followController *FollowControllerService) GetFollowing(c *gin.Context) {
+
+	followController.list(c, followController.FollowService.GetFollowing, func(follow domain.Follow) domain.User {
+		return follow.Followee
+	})
+}
+
+// list responds with a page of follows, showing the user userOf picks from each
+func (followController *FollowControllerService) list(
+	c *gin.Context,
+	getAll func(username string, list request.ListRequest) ([]domain.Follow, database.Page, error),
+	userOf func(follow domain.Follow) domain.User,
+) {
+
+	var cursorRequest request.CursorRequest
+
+	if err := c.ShouldBindQuery(&cursorRequest); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	list := cursorRequest.ListRequest()
+
+	follows, page, err := getAll(c.Param("username"), list)
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	followsResponse := []response.FollowGetAllResponse{}
+	for _, follow := range follows {
+		user := userOf(follow)
+
+		followsResponse = append(followsResponse, response.FollowGetAllResponse{
+			ID:         user.ID,
+			Username:   user.Username,
+			FollowedAt: follow.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data:       followsResponse,
+		Pagination: response.NewListPaginationResponse(list, page.Total, page.NextCursor),
+	})
+}
+
+func abortWithServiceError(c *gin.Context, err error) {
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.ErrorResponse{
+			Code:   http.StatusNotFound,
+			Status: "Not Found",
+			Errors: gin.H{
+				"message": "User not found",
+			},
+		})
+
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+		Code:   http.StatusBadRequest,
+		Status: "Bad Request",
+		Errors: err.Error(),
+	})
+}
+
+func abortWithBindError(c *gin.Context, err error) {
+
+	validationError, ok := err.(validator.ValidationErrors)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	fieldErrorResponse := make(map[string]interface{})
+
+	for _, v := range validationError {
+		fieldErrorResponse[strings.ToLower(v.Field())] = helpers.GetValidationErrorMsg(v)
+	}
+
+	c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+		Code:   http.StatusBadRequest,
+		Status: "Bad Request",
+		Errors: fieldErrorResponse,
+	})
+}
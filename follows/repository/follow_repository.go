@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"mygram-api/database"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+)
+
+type FollowRepository interface {
+	Create(follow *domain.Follow) (err error)
+	Delete(followerID, followeeID uint) (err error)
+	GetFollowers(userID uint, list request.ListRequest) (follows []domain.Follow, page database.Page, err error)
+	GetFollowing(userID uint, list request.ListRequest) (follows []domain.Follow, page database.Page, err error)
+	CountFollowers(userID uint) (count int64, err error)
+	CountFollowing(userID uint) (count int64, err error)
+}
+
+type FollowRepositoryDB struct {
+	DB *gorm.DB
+}
+
+func NewFollowRepository(db *gorm.DB) FollowRepository {
+	return &FollowRepositoryDB{DB: db}
+}
+
+// Create follows the followee, following it again is not an error
+func (followRepository *FollowRepositoryDB) Create(follow *domain.Follow) (err error) {
+
+	if err = followRepository.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(follow).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// Delete removes the follow, if any, of the follower on the followee
+func (followRepository *FollowRepositoryDB) Delete(followerID, followeeID uint) (err error) {
+
+	if err = followRepository.DB.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Delete(&domain.Follow{}).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// GetFollowers lists the follows on userID with their follower
+func (followRepository *FollowRepositoryDB) GetFollowers(userID uint, list request.ListRequest) (follows []domain.Follow, page database.Page, err error) {
+
+	query := followRepository.DB.Model(&domain.Follow{}).Preload("Follower", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "username")
+	}).Where("followee_id = ?", userID)
+
+	return database.Paginate(query, list, func(follow domain.Follow) request.Cursor {
+		return request.Cursor{CreatedAt: follow.CreatedAt, ID: follow.ID}
+	})
+}
+
+// GetFollowing lists the follows of userID with their followee
+func (followRepository *FollowRepositoryDB) GetFollowing(userID uint, list request.ListRequest) (follows []domain.Follow, page database.Page, err error) {
+
+	query := followRepository.DB.Model(&domain.Follow{}).Preload("Followee", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "username")
+	}).Where("follower_id = ?", userID)
+
+	return database.Paginate(query, list, func(follow domain.Follow) request.Cursor {
+		return request.Cursor{CreatedAt: follow.CreatedAt, ID: follow.ID}
+	})
+}
+
+func (followRepository *FollowRepositoryDB) CountFollowers(userID uint) (count int64, err error) {
+
+	if err = followRepository.DB.Model(&domain.Follow{}).Where("followee_id = ?", userID).Count(&count).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+func (followRepository *FollowRepositoryDB) CountFollowing(userID uint) (count int64, err error) {
+
+	if err = followRepository.DB.Model(&domain.Follow{}).Where("follower_id = ?", userID).Count(&count).Error; err != nil {
+		return
+	}
+
+	return
+}
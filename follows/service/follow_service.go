@@ -0,0 +1,113 @@
+package service
+
+import (
+	"errors"
+
+	"mygram-api/database"
+	"mygram-api/follows/repository"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+	userRepository "mygram-api/users/repository"
+)
+
+var ErrFollowSelf = errors.New("users cannot follow themselves")
+
+// TimelineSync is told about every follow and unfollow, the feed keeps the
+// materialized timelines in sync through it
+type TimelineSync interface {
+	Followed(followerID, followeeID uint) (err error)
+	Unfollowed(followerID, followeeID uint) (err error)
+}
+
+// FollowNotifier is told about every new follow and every follow taken back
+type FollowNotifier interface {
+	Followed(followerID, followeeID uint)
+	Unfollowed(followerID, followeeID uint)
+}
+
+type FollowService interface {
+	Follow(followerID uint, username string) (followee domain.User, err error)
+	Unfollow(followerID uint, username string) (followee domain.User, err error)
+	GetFollowers(username string, list request.ListRequest) (follows []domain.Follow, page database.Page, err error)
+	GetFollowing(username string, list request.ListRequest) (follows []domain.Follow, page database.Page, err error)
+}
+
+type FollowServiceRepository struct {
+	FollowRepository repository.FollowRepository
+	UserRepository   userRepository.UserRepository
+	TimelineSync     TimelineSync
+	FollowNotifier   FollowNotifier
+}
+
+func NewFollowService(followRepository repository.FollowRepository, userRepository userRepository.UserRepository, timelineSync TimelineSync, followNotifier FollowNotifier) FollowService {
+	return &FollowServiceRepository{FollowRepository: followRepository, UserRepository: userRepository, TimelineSync: timelineSync, FollowNotifier: followNotifier}
+}
+
+// Follow makes followerID follow the user named username, following twice is not an error
+func (followService *FollowServiceRepository) Follow(followerID uint, username string) (followee domain.User, err error) {
+
+	if followee, err = followService.UserRepository.GetByUsername(username); err != nil {
+		return
+	}
+
+	if followee.ID == followerID {
+		return followee, ErrFollowSelf
+	}
+
+	follow := domain.Follow{FollowerID: followerID, FolloweeID: followee.ID}
+
+	if err = followService.FollowRepository.Create(&follow); err != nil {
+		return
+	}
+
+	if err = followService.TimelineSync.Followed(followerID, followee.ID); err != nil {
+		return
+	}
+
+	// following again leaves the follow without an id, and notifies nobody
+	if follow.ID != 0 {
+		followService.FollowNotifier.Followed(followerID, followee.ID)
+	}
+
+	return
+}
+
+// Unfollow stops followerID from following the user named username, if it did
+func (followService *FollowServiceRepository) Unfollow(followerID uint, username string) (followee domain.User, err error) {
+
+	if followee, err = followService.UserRepository.GetByUsername(username); err != nil {
+		return
+	}
+
+	if err = followService.FollowRepository.Delete(followerID, followee.ID); err != nil {
+		return
+	}
+
+	if err = followService.TimelineSync.Unfollowed(followerID, followee.ID); err != nil {
+		return
+	}
+
+	followService.FollowNotifier.Unfollowed(followerID, followee.ID)
+
+	return
+}
+
+func (followService *FollowServiceRepository) GetFollowers(username string, list request.ListRequest) (follows []domain.Follow, page database.Page, err error) {
+
+	user, err := followService.UserRepository.GetByUsername(username)
+	if err != nil {
+		return
+	}
+
+	return followService.FollowRepository.GetFollowers(user.ID, list)
+}
+
+func (followService *FollowServiceRepository) GetFollowing(username string, list request.ListRequest) (follows []domain.Follow, page database.Page, err error) {
+
+	user, err := followService.UserRepository.GetByUsername(username)
+	if err != nil {
+		return
+	}
+
+	return followService.FollowRepository.GetFollowing(user.ID, list)
+}
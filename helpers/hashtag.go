@@ -0,0 +1,48 @@
+package helpers
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MaxHashtags is how many distinct hashtags of a caption are kept
+	MaxHashtags = 30
+
+	maxHashtagLength = 64
+)
+
+// hashtagPattern matches a # that does not follow a letter, digit or
+// underscore, so the fragment of page#section or the C# language are not tags
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_])#([\p{L}\p{N}_]+)`)
+
+// ParseHashtags returns the distinct hashtags of text lowercased and without
+// the #, in order of appearance. Tags made of digits only, like #1, and
+// tags longer than 64 characters are ignored.
+func ParseHashtags(text string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+
+	for _, match := range hashtagPattern.FindAllStringSubmatch(text, -1) {
+		tag := strings.ToLower(match[1])
+
+		if seen[tag] || utf8.RuneCountInString(tag) > maxHashtagLength || strings.IndexFunc(tag, isNotDigit) < 0 {
+			continue
+		}
+
+		seen[tag] = true
+		tags = append(tags, tag)
+
+		if len(tags) == MaxHashtags {
+			break
+		}
+	}
+
+	return tags
+}
+
+func isNotDigit(r rune) bool {
+	return !unicode.IsDigit(r)
+}
@@ -3,48 +3,109 @@ package helpers
 import (
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+
+	"mygram-api/config"
 )
 
-var secretKey = "rahasia"
+var errSignIn = errors.New("sign in to proceed")
+
+// TokenClaims represents the claims carried by an access token
+type TokenClaims struct {
+	ID        uint     `json:"id"`
+	Email     string   `json:"email"`
+	Roles     []string `json:"roles,omitempty"`
+	SessionID string   `json:"sid"`
+	jwt.StandardClaims
+}
+
+// Valid requires the standard time claims instead of treating them as optional
+func (claims TokenClaims) Valid() error {
+	if claims.ExpiresAt == 0 || claims.IssuedAt == 0 || claims.Id == "" {
+		return errSignIn
+	}
+
+	if claims.ID == 0 || claims.SessionID == "" {
+		return errSignIn
+	}
+
+	return claims.StandardClaims.Valid()
+}
+
+func GenerateToken(id uint, email string, roles []string, sessionID string, jwtConfig config.JWTConfig) (signedToken string, expiresAt time.Time, err error) {
+	tokenID, err := GenerateRandomToken(16)
+	if err != nil {
+		return
+	}
+
+	issuedAt := time.Now()
+	expiresAt = issuedAt.Add(jwtConfig.AccessTokenTTL)
 
-func GenerateToken(id uint, email string) string {
-	claims := jwt.MapClaims{
-		"id":    id,
-		"email": email,
+	claims := TokenClaims{
+		ID:        id,
+		Email:     email,
+		Roles:     roles,
+		SessionID: sessionID,
+		StandardClaims: jwt.StandardClaims{
+			Id:        tokenID,
+			Subject:   email,
+			IssuedAt:  issuedAt.Unix(),
+			NotBefore: issuedAt.Unix(),
+			ExpiresAt: expiresAt.Unix(),
+		},
 	}
 
 	parseToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	signedToken, _ := parseToken.SignedString([]byte(secretKey))
+	signedToken, err = parseToken.SignedString([]byte(jwtConfig.SecretKey))
 
-	return signedToken
+	return
 }
 
-func VerifyToken(ctx *gin.Context) (interface{}, error) {
-	errResponse := errors.New("sign in to proceed")
-	headerToken := ctx.Request.Header.Get("Authorization")
-	bearer := strings.HasPrefix(headerToken, "Bearer")
-
-	if !bearer {
-		return nil, errResponse
+// VerifyToken reads the bearer token of the request and returns its claims
+// only when the signature, algorithm and time claims are all valid
+func VerifyToken(ctx *gin.Context, secretKey string) (*TokenClaims, error) {
+	stringToken, ok := bearerToken(ctx.Request.Header.Get("Authorization"))
+	if !ok {
+		return nil, errSignIn
 	}
 
-	stringToken := strings.Split(headerToken, " ")[1]
+	return ParseToken(stringToken, secretKey)
+}
+
+// ParseToken validates a raw access token
+func ParseToken(stringToken string, secretKey string) (*TokenClaims, error) {
+	parser := jwt.Parser{ValidMethods: []string{jwt.SigningMethodHS256.Alg()}}
 
-	token, _ := jwt.Parse(stringToken, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errResponse
+	token, err := parser.ParseWithClaims(stringToken, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, errSignIn
 		}
 
 		return []byte(secretKey), nil
 	})
 
-	if _, ok := token.Claims.(jwt.MapClaims); !ok && !token.Valid {
-		return nil, errResponse
+	if err != nil || token == nil || !token.Valid {
+		return nil, errSignIn
 	}
 
-	return token.Claims.(jwt.MapClaims), nil
-}
\ No newline at end of file
+	claims, ok := token.Claims.(*TokenClaims)
+	if !ok {
+		return nil, errSignIn
+	}
+
+	return claims, nil
+}
+
+func bearerToken(header string) (string, bool) {
+	parts := strings.Fields(header)
+
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return "", false
+	}
+
+	return parts[1], true
+}
@@ -0,0 +1,39 @@
+package helpers
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MaxMentions is how many distinct users a caption or comment can mention
+const MaxMentions = 20
+
+// mentionPattern matches an @ that does not follow a letter, digit or
+// underscore, so email addresses are not mentions
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_])@([\p{L}\p{N}_.-]+)`)
+
+// ParseMentions returns the distinct usernames mentioned in text without the
+// @, in order of appearance, the same username written in another case
+// counting once. Dots and dashes ending a mention are taken as punctuation,
+// so "thanks @bob." mentions bob.
+func ParseMentions(text string) []string {
+	var usernames []string
+	seen := make(map[string]bool)
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		username := strings.TrimRight(match[1], ".-")
+
+		if username == "" || seen[strings.ToLower(username)] {
+			continue
+		}
+
+		seen[strings.ToLower(username)] = true
+		usernames = append(usernames, username)
+
+		if len(usernames) == MaxMentions {
+			break
+		}
+	}
+
+	return usernames
+}
@@ -0,0 +1,26 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"no mentions", nil},
+		{"@bob and @alice", []string{"bob", "alice"}},
+		{"thanks @bob.", []string{"bob"}},
+		{"mail bob@example.com", nil},
+		{"@Bob, @bob and @BOB", []string{"Bob"}},
+		{"@budi_s @budi.s", []string{"budi_s", "budi.s"}},
+	}
+
+	for _, test := range tests {
+		if got := ParseMentions(test.text); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseMentions(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
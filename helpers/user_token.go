@@ -0,0 +1,70 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidUserToken = errors.New("invalid or expired token")
+
+// SignUserToken returns a token for purpose, such as verifying an email, that
+// names the user and expires at expiresAt. The signature also covers state,
+// which is not part of the token: the token only verifies while the user is
+// still in that state, so using it once, which changes the state, spends it.
+func SignUserToken(secret, purpose string, userID uint, state string, expiresAt time.Time) string {
+	claims := fmt.Sprintf("%d.%d", userID, expiresAt.Unix())
+
+	return claims + "." + userTokenSignature(secret, purpose, claims, state)
+}
+
+// ParseUserToken returns the user a token names without verifying it, to
+// look up the state it is verified against
+func ParseUserToken(token string) (userID uint, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, ErrInvalidUserToken
+	}
+
+	id, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, ErrInvalidUserToken
+	}
+
+	return uint(id), nil
+}
+
+// VerifyUserToken checks that token was signed for purpose and state and has not expired
+func VerifyUserToken(secret, purpose, token, state string) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ErrInvalidUserToken
+	}
+
+	claims := parts[0] + "." + parts[1]
+
+	if !hmac.Equal([]byte(parts[2]), []byte(userTokenSignature(secret, purpose, claims, state))) {
+		return ErrInvalidUserToken
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return ErrInvalidUserToken
+	}
+
+	return nil
+}
+
+func userTokenSignature(secret, purpose, claims, state string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+
+	// NUL separated so that no two different inputs sign the same bytes
+	mac.Write([]byte(purpose + "\x00" + claims + "\x00" + state))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,78 @@
+package helpers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "test-secret"
+
+func TestUserToken(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+	token := SignUserToken(testSecret, "verify-email", 42, "user@example.com", expiresAt)
+
+	userID, err := ParseUserToken(token)
+	if err != nil || userID != 42 {
+		t.Fatalf("ParseUserToken = %d, %v, want 42", userID, err)
+	}
+
+	if err = VerifyUserToken(testSecret, "verify-email", token, "user@example.com"); err != nil {
+		t.Fatalf("a fresh token does not verify: %v", err)
+	}
+
+	parts := strings.Split(token, ".")
+
+	// flips the last character of the signature
+	signature := []byte(parts[2])
+	signature[len(signature)-1] ^= 1
+
+	tests := []struct {
+		name    string
+		secret  string
+		purpose string
+		token   string
+		state   string
+	}{
+		{"tampered signature", testSecret, "verify-email", parts[0] + "." + parts[1] + "." + string(signature), "user@example.com"},
+		{"another user", testSecret, "verify-email", "43." + parts[1] + "." + parts[2], "user@example.com"},
+		{"extended expiry", testSecret, "verify-email", parts[0] + "." + "9999999999." + parts[2], "user@example.com"},
+		{"wrong purpose", testSecret, "reset-password", token, "user@example.com"},
+		{"state changed", testSecret, "verify-email", token, "new@example.com"},
+		{"another secret", "other-secret", "verify-email", token, "user@example.com"},
+		{"truncated", testSecret, "verify-email", parts[0] + "." + parts[1], "user@example.com"},
+		{"empty", testSecret, "verify-email", "", "user@example.com"},
+	}
+
+	for _, test := range tests {
+		if err := VerifyUserToken(test.secret, test.purpose, test.token, test.state); !errors.Is(err, ErrInvalidUserToken) {
+			t.Errorf("%s: error = %v, want ErrInvalidUserToken", test.name, err)
+		}
+	}
+}
+
+func TestUserTokenExpires(t *testing.T) {
+	token := SignUserToken(testSecret, "reset-password", 1, "hash", time.Now().Add(-time.Second))
+
+	if err := VerifyUserToken(testSecret, "reset-password", token, "hash"); !errors.Is(err, ErrInvalidUserToken) {
+		t.Errorf("error = %v, want ErrInvalidUserToken for an expired token", err)
+	}
+}
+
+// a reset token is signed over the password hash, so resetting the password spends it
+func TestUserTokenIsSpentByTheStateChange(t *testing.T) {
+	token := SignUserToken(testSecret, "reset-password", 1, Hash("old password"), time.Now().Add(time.Hour))
+
+	if err := VerifyUserToken(testSecret, "reset-password", token, Hash("new password")); !errors.Is(err, ErrInvalidUserToken) {
+		t.Errorf("error = %v, want the token spent once the password changed", err)
+	}
+}
+
+func TestParseUserTokenRejectsMalformedTokens(t *testing.T) {
+	for _, token := range []string{"", "1.2", "a.2.sig", "-1.2.sig", "1.2.3.4"} {
+		if _, err := ParseUserToken(token); !errors.Is(err, ErrInvalidUserToken) {
+			t.Errorf("ParseUserToken(%q) error = %v, want ErrInvalidUserToken", token, err)
+		}
+	}
+}
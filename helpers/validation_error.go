@@ -2,6 +2,7 @@ package helpers
 
 import (
 	"fmt"
+	"reflect"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -14,7 +15,14 @@ func GetValidationErrorMsg(fieldError validator.FieldError) string {
 	case "email":
 		return fmt.Sprintf("Invalid %s address", fieldError.Field())
 	case "min":
+		if fieldError.Kind() != reflect.String {
+			return fmt.Sprintf("%s must be at least %s", fieldError.Field(), fieldError.Param())
+		}
 		return fmt.Sprintf("Your %s must be have at least %s characters long", fieldError.Field(), fieldError.Param())
+	case "max":
+		return fmt.Sprintf("%s must be at most %s", fieldError.Field(), fieldError.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", fieldError.Field(), fieldError.Param())
 	case "gt":
 		return fmt.Sprintf("Should be greater than %s", fieldError.Param())
 	}
@@ -0,0 +1,28 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"mygram-api/jobs/repository"
+)
+
+// CleanupJob removes the finished jobs kept for longer than the retention
+var CleanupJob = Type[struct{}]{Name: "jobs.cleanup", Queue: QueueDefault, MaxAttempts: 3}
+
+// CleanupHandler runs CleanupJob
+func CleanupHandler(jobRepository repository.JobRepository, retention time.Duration) Handler {
+	return CleanupJob.Handle(func(ctx context.Context, _ struct{}) error {
+		deleted, err := jobRepository.DeleteFinished(time.Now().Add(-retention))
+		if err != nil {
+			return err
+		}
+
+		if deleted > 0 {
+			log.Printf("removed %d finished jobs", deleted)
+		}
+
+		return nil
+	})
+}
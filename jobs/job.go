@@ -0,0 +1,145 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"mygram-api/jobs/repository"
+	"mygram-api/models/domain"
+)
+
+// Queues the jobs run on, each with its own workers so that a burst of one
+// kind of work does not hold up the others
+const (
+	QueueDefault  = "default"
+	QueueVariants = "variants"
+	QueueWebhooks = "webhooks"
+)
+
+const defaultMaxAttempts = 5
+
+// Type names a kind of job, the queue it runs on and the payload its handler takes
+type Type[T any] struct {
+	Name        string
+	Queue       string
+	MaxAttempts int
+}
+
+// Handle returns the handler running the jobs of this type with fn. A payload
+// that does not decode fails the job for good.
+func (jobType Type[T]) Handle(fn func(ctx context.Context, payload T) error) Handler {
+	return Handler{
+		Type:  jobType.Name,
+		Queue: jobType.Queue,
+		run: func(ctx context.Context, data []byte) error {
+			var payload T
+
+			if err := json.Unmarshal(data, &payload); err != nil {
+				return Permanent(err)
+			}
+
+			return fn(ctx, payload)
+		},
+	}
+}
+
+// Handler runs the jobs of one type
+type Handler struct {
+	Type  string
+	Queue string
+
+	run func(ctx context.Context, payload []byte) error
+}
+
+// Option changes a job before it is queued
+type Option func(job *domain.Job)
+
+// At delays a job until the given time
+func At(runAt time.Time) Option {
+	return func(job *domain.Job) {
+		job.RunAt = runAt
+	}
+}
+
+// In delays a job by the given duration
+func In(delay time.Duration) Option {
+	return func(job *domain.Job) {
+		job.RunAt = time.Now().Add(delay)
+	}
+}
+
+// Unique skips queueing the job while another with the same key is pending or running
+func Unique(key string) Option {
+	return func(job *domain.Job) {
+		job.UniqueKey = &key
+	}
+}
+
+// Client queues jobs, from the http server as well as from other jobs
+type Client struct {
+	JobRepository repository.JobRepository
+}
+
+func NewClient(jobRepository repository.JobRepository) *Client {
+	return &Client{JobRepository: jobRepository}
+}
+
+// Enqueue queues a job of the given type. The job keeps a zero ID when a
+// Unique job with the same key is already pending or running.
+func Enqueue[T any](client *Client, jobType Type[T], payload T, options ...Option) (job domain.Job, err error) {
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+
+	job = domain.Job{
+		Queue:       jobType.Queue,
+		Type:        jobType.Name,
+		Payload:     string(data),
+		Status:      domain.JobPending,
+		MaxAttempts: jobType.MaxAttempts,
+		RunAt:       time.Now(),
+	}
+
+	if job.MaxAttempts == 0 {
+		job.MaxAttempts = defaultMaxAttempts
+	}
+
+	for _, option := range options {
+		option(&job)
+	}
+
+	if err = client.JobRepository.Enqueue(&job); err != nil {
+		return
+	}
+
+	return
+}
+
+type permanentError struct {
+	err error
+}
+
+func (permanent permanentError) Error() string {
+	return permanent.err.Error()
+}
+
+func (permanent permanentError) Unwrap() error {
+	return permanent.err
+}
+
+// Permanent wraps the error of a job that would fail again however often it
+// is retried, such as one about a row that is gone, so that it dies right away
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether err was returned through Permanent
+func IsPermanent(err error) bool {
+	var permanent permanentError
+
+	return errors.As(err, &permanent)
+}
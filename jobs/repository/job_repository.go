@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"mygram-api/models/domain"
+)
+
+// ErrLeaseLost is returned when finishing a job whose lease ran out and that
+// another worker claimed since
+var ErrLeaseLost = errors.New("job lease lost")
+
+type JobRepository interface {
+	Enqueue(job *domain.Job) (err error)
+	Claim(queue string, limit int, lease time.Duration) (jobs []domain.Job, err error)
+	Finish(job domain.Job, lease time.Time) (err error)
+	DeleteFinished(before time.Time) (deleted int64, err error)
+}
+
+type JobRepositoryDB struct {
+	DB *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &JobRepositoryDB{DB: db}
+}
+
+// Enqueue stores a pending job. A job whose unique key is taken by a pending
+// or running job is not stored and keeps a zero ID.
+func (jobRepository *JobRepositoryDB) Enqueue(job *domain.Job) (err error) {
+
+	if err = jobRepository.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(job).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// Claim marks up to limit due jobs of queue running for lease and returns
+// them. A running job whose lease ran out, its worker having stopped, is due
+// again. Jobs locked by another worker are skipped rather than waited for.
+func (jobRepository *JobRepositoryDB) Claim(queue string, limit int, lease time.Duration) (jobs []domain.Job, err error) {
+
+	now := time.Now()
+
+	if err = jobRepository.DB.Raw(`UPDATE jobs SET status = ?, attempts = attempts + 1, locked_until = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM jobs
+			WHERE queue = ? AND ((status = ? AND run_at <= ?) OR (status = ? AND locked_until < ?))
+			ORDER BY run_at, id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		domain.JobRunning, now.Add(lease), now,
+		queue, domain.JobPending, now, domain.JobRunning, now,
+		limit,
+	).Scan(&jobs).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// Finish stores the outcome of a run: the job succeeded, is dead, or is
+// pending again until its RunAt, with the attempt given back when it was
+// interrupted. It only applies while the job still holds the lease it was
+// claimed with, a job reclaimed by another worker returns ErrLeaseLost.
+func (jobRepository *JobRepositoryDB) Finish(job domain.Job, lease time.Time) (err error) {
+
+	result := jobRepository.DB.Model(&job).
+		Where("status = ? AND locked_until = ?", domain.JobRunning, lease).
+		Select("status", "attempts", "run_at", "locked_until", "last_error", "finished_at").
+		Updates(job)
+
+	if err = result.Error; err != nil {
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrLeaseLost
+	}
+
+	return
+}
+
+// DeleteFinished removes the succeeded and dead jobs finished before the given time
+func (jobRepository *JobRepositoryDB) DeleteFinished(before time.Time) (deleted int64, err error) {
+
+	result := jobRepository.DB.Where("status IN ? AND finished_at < ?", []string{domain.JobSucceeded, domain.JobDead}, before).Delete(&domain.Job{})
+	if err = result.Error; err != nil {
+		return
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,233 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"mygram-api/config"
+	"mygram-api/jobs/repository"
+	"mygram-api/models/domain"
+)
+
+const (
+	retryBaseDelay = 10 * time.Second
+	maxRetryDelay  = time.Hour
+)
+
+var errAbandoned = errors.New("worker stopped during the last attempt")
+
+// Worker runs the jobs of the registered types, with a fixed number of
+// goroutines per queue each claiming one job at a time
+type Worker struct {
+	JobRepository repository.JobRepository
+	Config        config.JobsConfig
+
+	handlers    map[string]Handler
+	concurrency map[string]int
+	periodic    []func(ctx context.Context)
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWorker returns a worker running concurrency[queue] goroutines per queue
+func NewWorker(jobRepository repository.JobRepository, jobsConfig config.JobsConfig, concurrency map[string]int) *Worker {
+	return &Worker{
+		JobRepository: jobRepository,
+		Config:        jobsConfig,
+		handlers:      make(map[string]Handler),
+		concurrency:   concurrency,
+	}
+}
+
+// Register adds the handlers of job types, to be called before Start
+func (worker *Worker) Register(handlers ...Handler) {
+	for _, handler := range handlers {
+		worker.handlers[handler.Type] = handler
+	}
+}
+
+// Every runs fn every interval while the worker runs, starting right away,
+// typically to queue a Unique job so that a single instance does the work
+func (worker *Worker) Every(interval time.Duration, fn func()) {
+	worker.periodic = append(worker.periodic, func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			fn()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Start runs the workers of every queue that has a registered handler
+func (worker *Worker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	worker.cancel = cancel
+
+	queues := make(map[string]bool)
+	for _, handler := range worker.handlers {
+		queues[handler.Queue] = true
+	}
+
+	for queue := range queues {
+		concurrency, ok := worker.concurrency[queue]
+		if !ok {
+			concurrency = 1
+		}
+
+		for i := 0; i < concurrency; i++ {
+			worker.wg.Add(1)
+
+			go func(queue string) {
+				defer worker.wg.Done()
+				worker.run(ctx, queue)
+			}(queue)
+		}
+	}
+
+	for _, periodic := range worker.periodic {
+		worker.wg.Add(1)
+
+		go func(periodic func(ctx context.Context)) {
+			defer worker.wg.Done()
+			periodic(ctx)
+		}(periodic)
+	}
+}
+
+// Stop cancels the jobs in progress, which are due again right away, and
+// waits for the workers to return
+func (worker *Worker) Stop() error {
+	if worker.cancel != nil {
+		worker.cancel()
+	}
+
+	worker.wg.Wait()
+
+	return nil
+}
+
+func (worker *Worker) run(ctx context.Context, queue string) {
+	poll := time.NewTicker(worker.Config.PollInterval)
+	defer poll.Stop()
+
+	for {
+		// keep running while jobs are due, and only wait once none is left
+		if !worker.runNext(ctx, queue) {
+			select {
+			case <-ctx.Done():
+				return
+			case <-poll.C:
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// runNext runs one due job of queue and reports whether there was one
+func (worker *Worker) runNext(ctx context.Context, queue string) bool {
+	// the lease outlasts the timeout so a job is not taken again while it still runs
+	jobs, err := worker.JobRepository.Claim(queue, 1, worker.Config.Timeout+time.Minute)
+	if err != nil {
+		log.Printf("claiming jobs of queue %s: %v", queue, err)
+		return false
+	}
+
+	for _, job := range jobs {
+		worker.execute(ctx, job)
+	}
+
+	return len(jobs) > 0
+}
+
+func (worker *Worker) execute(ctx context.Context, job domain.Job) {
+	var err error
+
+	handler, ok := worker.handlers[job.Type]
+
+	switch {
+	case job.Attempts > job.MaxAttempts:
+		err = Permanent(errAbandoned)
+	case !ok:
+		// an instance running another version may know the type, leave it the remaining attempts
+		err = fmt.Errorf("no handler for job type %s", job.Type)
+	default:
+		err = worker.call(ctx, handler, job)
+	}
+
+	now := time.Now()
+	lease := *job.LockedUntil
+	job.LockedUntil = nil
+	job.LastError = ""
+
+	switch {
+	case ctx.Err() != nil:
+		// a job cut short by Stop is due again right away, without counting the attempt
+		job.Status = domain.JobPending
+		job.Attempts--
+		job.RunAt = now
+	case err == nil:
+		job.Status = domain.JobSucceeded
+		job.FinishedAt = &now
+	case IsPermanent(err) || job.Attempts >= job.MaxAttempts:
+		log.Printf("job %d of type %s died: %v", job.ID, job.Type, err)
+
+		job.Status = domain.JobDead
+		job.FinishedAt = &now
+		job.LastError = err.Error()
+	default:
+		job.Status = domain.JobPending
+		job.RunAt = now.Add(retryDelay(job.Attempts))
+		job.LastError = err.Error()
+	}
+
+	if err = worker.JobRepository.Finish(job, lease); errors.Is(err, repository.ErrLeaseLost) {
+		log.Printf("job %d ran past its lease and was claimed again, its outcome is dropped", job.ID)
+	} else if err != nil {
+		log.Printf("finishing job %d: %v", job.ID, err)
+	}
+}
+
+// call runs the handler within the timeout, turning a panic into an error so
+// that one bad job does not take the worker down
+func (worker *Worker) call(ctx context.Context, handler Handler, job domain.Job) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, worker.Config.Timeout)
+	defer cancel()
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+
+	return handler.run(ctx, []byte(job.Payload))
+}
+
+// retryDelay doubles from retryBaseDelay after each failed attempt
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	return delay
+}
@@ -0,0 +1,320 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"mygram-api/config"
+	"mygram-api/database/dbtest"
+	"mygram-api/jobs/repository"
+	"mygram-api/models/domain"
+)
+
+var testJob = Type[struct{}]{Name: "test.job", Queue: QueueDefault, MaxAttempts: 3}
+
+var testConfig = config.JobsConfig{PollInterval: 10 * time.Millisecond, Timeout: time.Minute}
+
+func openClient(t *testing.T) (*gorm.DB, *Client) {
+	t.Helper()
+
+	db := dbtest.Open(t)
+
+	return db, NewClient(repository.NewJobRepository(db))
+}
+
+func getJob(t *testing.T, db *gorm.DB, id uint) (job domain.Job) {
+	t.Helper()
+
+	if err := db.First(&job, id).Error; err != nil {
+		t.Fatalf("loading job %d: %v", id, err)
+	}
+
+	return
+}
+
+func TestClaimNeverHandsAJobToTwoWorkers(t *testing.T) {
+	db, client := openClient(t)
+
+	const total = 100
+
+	for i := 0; i < total; i++ {
+		if _, err := Enqueue(client, testJob, struct{}{}); err != nil {
+			t.Fatalf("enqueueing: %v", err)
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		claimed = make(map[uint]int)
+		wg      sync.WaitGroup
+	)
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			jobRepository := repository.NewJobRepository(db)
+
+			for {
+				jobs, err := jobRepository.Claim(QueueDefault, 3, time.Minute)
+				if err != nil {
+					t.Errorf("claiming: %v", err)
+					return
+				}
+
+				if len(jobs) == 0 {
+					return
+				}
+
+				mu.Lock()
+				for _, job := range jobs {
+					claimed[job.ID]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if len(claimed) != total {
+		t.Errorf("claimed %d jobs, want %d", len(claimed), total)
+	}
+
+	for id, times := range claimed {
+		if times != 1 {
+			t.Errorf("job %d claimed %d times", id, times)
+		}
+	}
+}
+
+func TestClaimTakesAJobBackOnceItsLeaseExpires(t *testing.T) {
+	db, client := openClient(t)
+
+	job, err := Enqueue(client, testJob, struct{}{})
+	if err != nil {
+		t.Fatalf("enqueueing: %v", err)
+	}
+
+	jobRepository := client.JobRepository
+
+	if jobs, _ := jobRepository.Claim(QueueDefault, 1, time.Hour); len(jobs) != 1 {
+		t.Fatalf("first claim got %d jobs, want 1", len(jobs))
+	}
+
+	if jobs, _ := jobRepository.Claim(QueueDefault, 1, time.Hour); len(jobs) != 0 {
+		t.Fatalf("claimed a job still leased")
+	}
+
+	// the worker holding it crashed and the lease ran out
+	if err = db.Model(&domain.Job{}).Where("id = ?", job.ID).Update("locked_until", time.Now().Add(-time.Second)).Error; err != nil {
+		t.Fatalf("expiring the lease: %v", err)
+	}
+
+	jobs, err := jobRepository.Claim(QueueDefault, 1, time.Hour)
+	if err != nil {
+		t.Fatalf("claiming: %v", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].ID != job.ID {
+		t.Fatalf("claimed %+v, want job %d back", jobs, job.ID)
+	}
+
+	if jobs[0].Attempts != 2 {
+		t.Errorf("Attempts = %d, want 2", jobs[0].Attempts)
+	}
+}
+
+func TestFinishIsRefusedOnceTheJobWasClaimedAgain(t *testing.T) {
+	db, client := openClient(t)
+
+	job, err := Enqueue(client, testJob, struct{}{})
+	if err != nil {
+		t.Fatalf("enqueueing: %v", err)
+	}
+
+	jobRepository := client.JobRepository
+
+	first, err := jobRepository.Claim(QueueDefault, 1, time.Hour)
+	if err != nil || len(first) != 1 {
+		t.Fatalf("first claim got %d jobs: %v", len(first), err)
+	}
+
+	// the first worker overran its lease and a second one took the job
+	if err = db.Model(&domain.Job{}).Where("id = ?", job.ID).Update("locked_until", time.Now().Add(-time.Second)).Error; err != nil {
+		t.Fatalf("expiring the lease: %v", err)
+	}
+
+	second, err := jobRepository.Claim(QueueDefault, 1, time.Hour)
+	if err != nil || len(second) != 1 {
+		t.Fatalf("second claim got %d jobs: %v", len(second), err)
+	}
+
+	late := first[0]
+	late.Status = domain.JobDead
+
+	if err = jobRepository.Finish(late, *first[0].LockedUntil); !errors.Is(err, repository.ErrLeaseLost) {
+		t.Fatalf("finishing with the expired lease = %v, want ErrLeaseLost", err)
+	}
+
+	if stored := getJob(t, db, job.ID); stored.Status != domain.JobRunning {
+		t.Fatalf("status = %s, want the job still running for its new owner", stored.Status)
+	}
+
+	done := second[0]
+	done.Status = domain.JobSucceeded
+	done.LockedUntil = nil
+
+	if err = jobRepository.Finish(done, *second[0].LockedUntil); err != nil {
+		t.Fatalf("finishing with the current lease: %v", err)
+	}
+
+	if stored := getJob(t, db, job.ID); stored.Status != domain.JobSucceeded {
+		t.Errorf("status = %s, want succeeded", stored.Status)
+	}
+}
+
+func TestJobDiesAfterMaxAttempts(t *testing.T) {
+	db, client := openClient(t)
+
+	worker := NewWorker(client.JobRepository, testConfig, nil)
+	worker.Register(testJob.Handle(func(ctx context.Context, _ struct{}) error {
+		return errors.New("endpoint down")
+	}))
+
+	job, err := Enqueue(client, testJob, struct{}{})
+	if err != nil {
+		t.Fatalf("enqueueing: %v", err)
+	}
+
+	for attempt := 1; attempt <= testJob.MaxAttempts; attempt++ {
+		if !worker.runNext(context.Background(), QueueDefault) {
+			t.Fatalf("attempt %d found no due job", attempt)
+		}
+
+		stored := getJob(t, db, job.ID)
+
+		if attempt < testJob.MaxAttempts {
+			if stored.Status != domain.JobPending || !stored.RunAt.After(time.Now()) {
+				t.Fatalf("after attempt %d: status %s due %v, want pending later", attempt, stored.Status, stored.RunAt)
+			}
+
+			// skip the retry delay
+			db.Model(&domain.Job{}).Where("id = ?", job.ID).Update("run_at", time.Now())
+		}
+	}
+
+	stored := getJob(t, db, job.ID)
+
+	if stored.Status != domain.JobDead || stored.Attempts != testJob.MaxAttempts {
+		t.Errorf("status %s after %d attempts, want dead after %d", stored.Status, stored.Attempts, testJob.MaxAttempts)
+	}
+
+	if stored.LastError != "endpoint down" || stored.FinishedAt == nil {
+		t.Errorf("last error %q finished at %v, want the error and a finish time", stored.LastError, stored.FinishedAt)
+	}
+
+	if worker.runNext(context.Background(), QueueDefault) {
+		t.Error("a dead job ran again")
+	}
+}
+
+func TestUniqueSkipsAJobWhileAnotherIsPending(t *testing.T) {
+	db, client := openClient(t)
+
+	first, err := Enqueue(client, testJob, struct{}{}, Unique("key"))
+	if err != nil || first.ID == 0 {
+		t.Fatalf("enqueueing the first job: %+v %v", first, err)
+	}
+
+	duplicate, err := Enqueue(client, testJob, struct{}{}, Unique("key"))
+	if err != nil {
+		t.Fatalf("enqueueing the duplicate: %v", err)
+	}
+
+	if duplicate.ID != 0 {
+		t.Errorf("duplicate stored as job %d", duplicate.ID)
+	}
+
+	other, err := Enqueue(client, testJob, struct{}{}, Unique("other"))
+	if err != nil || other.ID == 0 {
+		t.Errorf("a job with another key was skipped: %+v %v", other, err)
+	}
+
+	// the key is free again once the job finished
+	if err = db.Model(&domain.Job{}).Where("id = ?", first.ID).Update("status", domain.JobSucceeded).Error; err != nil {
+		t.Fatalf("finishing the first job: %v", err)
+	}
+
+	again, err := Enqueue(client, testJob, struct{}{}, Unique("key"))
+	if err != nil || again.ID == 0 {
+		t.Errorf("the key stayed taken after the job finished: %+v %v", again, err)
+	}
+}
+
+func TestStopHandsTheAttemptBack(t *testing.T) {
+	db, client := openClient(t)
+
+	started := make(chan struct{})
+
+	worker := NewWorker(client.JobRepository, testConfig, nil)
+	worker.Register(testJob.Handle(func(ctx context.Context, _ struct{}) error {
+		close(started)
+		<-ctx.Done()
+
+		return ctx.Err()
+	}))
+
+	job, err := Enqueue(client, testJob, struct{}{})
+	if err != nil {
+		t.Fatalf("enqueueing: %v", err)
+	}
+
+	worker.Start()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		worker.Stop()
+		t.Fatal("the job never started")
+	}
+
+	worker.Stop()
+
+	stored := getJob(t, db, job.ID)
+
+	if stored.Status != domain.JobPending || stored.Attempts != 0 {
+		t.Errorf("status %s with %d attempts, want pending with none", stored.Status, stored.Attempts)
+	}
+
+	if stored.LockedUntil != nil || stored.RunAt.After(time.Now()) {
+		t.Errorf("locked until %v due %v, want unlocked and due now", stored.LockedUntil, stored.RunAt)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{9, 2560 * time.Second},
+		{10, time.Hour},
+		{100, time.Hour},
+	}
+
+	for _, test := range tests {
+		if got := retryDelay(test.attempts); got != test.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", test.attempts, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"mygram-api/auth"
+	"mygram-api/helpers"
+	"mygram-api/likes/service"
+	"mygram-api/models/request"
+	"mygram-api/models/response"
+	photoService "mygram-api/photos/service"
+)
+
+type LikeController interface {
+	Like(c *gin.Context)
+	Unlike(c *gin.Context)
+	GetAll(c *gin.Context)
+}
+
+type LikeControllerService struct {
+	LikeService  service.LikeService
+	PhotoService photoService.PhotoService
+}
+
+func NewLikeController(likeService service.LikeService, photoService photoService.PhotoService) LikeController {
+	return &LikeControllerService{LikeService: likeService, PhotoService: photoService}
+}
+
+// Like photo godoc
+// @Summary Like a photo
+// @Description Like a photo with authentication user, liking it again changes nothing
+// @Tags likes
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Photo ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /photos/{id}/like [post]
+func (likeController *LikeControllerService) Like(c *gin.Context) {
+
+	photoID, ok := likeController.photoID(c)
+	if !ok {
+		return
+	}
+
+	summary, err := likeController.LikeService.Like(auth.MustGetPrincipal(c).UserID, photoID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.NewLikeResponse(summary),
+	})
+}
+
+// Unlike photo godoc
+// @Summary Unlike a photo
+// @Description Remove the like of the authentication user from a photo
+// @Tags likes
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Photo ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /photos/{id}/like [delete]
+func (likeController *LikeControllerService) Unlike(c *gin.Context) {
+
+	photoID, ok := likeController.photoID(c)
+	if !ok {
+		return
+	}
+
+	summary, err := likeController.LikeService.Unlike(auth.MustGetPrincipal(c).UserID, photoID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.NewLikeResponse(summary),
+	})
+}
+
+// GetAll likes godoc
+// @Summary Get the likes of a photo
+// @Description Get a page of the users who liked a photo, newest first by default
+// @Tags likes
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Photo ID"
+// @Param page query int false "Page number for offset pagination with a total, without it the first keyset page is returned. Ignored when cursor is set"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "Cursor of the next page from a previous response"
+// @Param sort query string false "Sort order" Enums(-created_at, created_at) default(-created_at)
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /photos/{id}/likes [get]
+func (likeController *LikeControllerService) GetAll(c *gin.Context) {
+
+	var list request.ListRequest
+
+	if err := c.ShouldBindQuery(&list); err != nil {
+		validationError, ok := err.(validator.ValidationErrors)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+				Code:   http.StatusBadRequest,
+				Status: "Bad Request",
+				Errors: err.Error(),
+			})
+
+			return
+		}
+
+		fieldErrorResponse := make(map[string]interface{})
+
+		for _, v := range validationError {
+			fieldErrorResponse[strings.ToLower(v.Field())] = helpers.GetValidationErrorMsg(v)
+		}
+
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: fieldErrorResponse,
+		})
+
+		return
+	}
+
+	list.Normalize()
+
+	photoID, ok := likeController.photoID(c)
+	if !ok {
+		return
+	}
+
+	likes, page, err := likeController.LikeService.GetAllByPhoto(photoID, list)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	likesResponse := []response.LikeGetAllResponse{}
+	for _, like := range likes {
+		likesResponse = append(likesResponse, response.LikeGetAllResponse{
+			ID: like.ID,
+			User: response.LikeUserGetAllResponse{
+				ID:       like.User.ID,
+				Username: like.User.Username,
+			},
+			CreatedAt: like.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data:       likesResponse,
+		Pagination: response.NewListPaginationResponse(list, page.Total, page.NextCursor),
+	})
+}
+
+// photoID parses the photo id of the path and checks the photo exists,
+// aborting the request otherwise
+func (likeController *LikeControllerService) photoID(c *gin.Context) (uint, bool) {
+	photoID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return 0, false
+	}
+
+	if _, err = likeController.PhotoService.GetOne(uint(photoID)); err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.ErrorResponse{
+			Code:   http.StatusNotFound,
+			Status: "Not Found",
+			Errors: gin.H{
+				"message": "Record not found",
+			},
+		})
+
+		return 0, false
+	}
+
+	return uint(photoID), true
+}
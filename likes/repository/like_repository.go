@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"mygram-api/database"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+)
+
+type LikeRepository interface {
+	Create(like *domain.Like) (err error)
+	Delete(userID, photoID uint) (err error)
+	GetAllByPhoto(photoID uint, list request.ListRequest) (likes []domain.Like, page database.Page, err error)
+	GetSummaries(photoIDs []uint, userID uint) (summaries []domain.LikeSummary, err error)
+}
+
+type LikeRepositoryDB struct {
+	DB *gorm.DB
+}
+
+func NewLikeRepository(db *gorm.DB) LikeRepository {
+	return &LikeRepositoryDB{DB: db}
+}
+
+// Create likes the photo, liking it again is not an error
+func (likeRepository *LikeRepositoryDB) Create(like *domain.Like) (err error) {
+
+	if err = likeRepository.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(like).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// Delete removes the like, if any, of the user on the photo
+func (likeRepository *LikeRepositoryDB) Delete(userID, photoID uint) (err error) {
+
+	if err = likeRepository.DB.Where("user_id = ? AND photo_id = ?", userID, photoID).Delete(&domain.Like{}).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+func (likeRepository *LikeRepositoryDB) GetAllByPhoto(photoID uint, list request.ListRequest) (likes []domain.Like, page database.Page, err error) {
+
+	query := likeRepository.DB.Model(&domain.Like{}).Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "username")
+	}).Where("photo_id = ?", photoID)
+
+	return database.Paginate(query, list, func(like domain.Like) request.Cursor {
+		return request.Cursor{CreatedAt: like.CreatedAt, ID: like.ID}
+	})
+}
+
+// GetSummaries counts the likes of every photo and whether userID is among them
+// in a single query. Photos without likes are left out.
+func (likeRepository *LikeRepositoryDB) GetSummaries(photoIDs []uint, userID uint) (summaries []domain.LikeSummary, err error) {
+
+	if len(photoIDs) == 0 {
+		return
+	}
+
+	if err = likeRepository.DB.Model(&domain.Like{}).
+		Select("photo_id, COUNT(*) AS like_count, BOOL_OR(user_id = ?) AS liked_by_me", userID).
+		Where("photo_id IN ?", photoIDs).
+		Group("photo_id").
+		Scan(&summaries).Error; err != nil {
+		return
+	}
+
+	return
+}
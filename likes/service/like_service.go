@@ -0,0 +1,110 @@
+package service
+
+import (
+	"mygram-api/database"
+	"mygram-api/likes/repository"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+)
+
+// LikeNotifier is told about every new like and every like taken back
+type LikeNotifier interface {
+	Liked(userID, photoID uint)
+	Unliked(userID, photoID uint)
+}
+
+type LikeService interface {
+	Like(userID, photoID uint) (summary domain.LikeSummary, err error)
+	Unlike(userID, photoID uint) (summary domain.LikeSummary, err error)
+	GetAllByPhoto(photoID uint, list request.ListRequest) (likes []domain.Like, page database.Page, err error)
+	GetSummary(photoID, userID uint) (summary domain.LikeSummary, err error)
+	GetSummaries(photos []domain.Photo, userID uint) (summaries map[uint]domain.LikeSummary, err error)
+}
+
+type LikeServiceRepository struct {
+	LikeRepository repository.LikeRepository
+	LikeNotifier   LikeNotifier
+}
+
+func NewLikeService(likeRepository repository.LikeRepository, likeNotifier LikeNotifier) LikeService {
+	return &LikeServiceRepository{LikeRepository: likeRepository, LikeNotifier: likeNotifier}
+}
+
+// Like likes the photo for the user and returns its updated summary
+func (likeService *LikeServiceRepository) Like(userID, photoID uint) (summary domain.LikeSummary, err error) {
+
+	like := domain.Like{UserID: userID, PhotoID: photoID}
+
+	if err = likeService.LikeRepository.Create(&like); err != nil {
+		return
+	}
+
+	// liking again leaves the like without an id, and notifies nobody
+	if like.ID != 0 {
+		likeService.LikeNotifier.Liked(userID, photoID)
+	}
+
+	return likeService.GetSummary(photoID, userID)
+}
+
+// Unlike removes the like of the user and returns the updated summary of the photo
+func (likeService *LikeServiceRepository) Unlike(userID, photoID uint) (summary domain.LikeSummary, err error) {
+
+	if err = likeService.LikeRepository.Delete(userID, photoID); err != nil {
+		return
+	}
+
+	likeService.LikeNotifier.Unliked(userID, photoID)
+
+	return likeService.GetSummary(photoID, userID)
+}
+
+func (likeService *LikeServiceRepository) GetAllByPhoto(photoID uint, list request.ListRequest) (likes []domain.Like, page database.Page, err error) {
+
+	if likes, page, err = likeService.LikeRepository.GetAllByPhoto(photoID, list); err != nil {
+		return
+	}
+
+	return
+}
+
+func (likeService *LikeServiceRepository) GetSummary(photoID, userID uint) (summary domain.LikeSummary, err error) {
+
+	summaries, err := likeService.getSummaries([]uint{photoID}, userID)
+	if err != nil {
+		return
+	}
+
+	return summaries[photoID], nil
+}
+
+// GetSummaries returns the like summary of every photo keyed by its id,
+// photos without likes get a zero summary
+func (likeService *LikeServiceRepository) GetSummaries(photos []domain.Photo, userID uint) (summaries map[uint]domain.LikeSummary, err error) {
+
+	photoIDs := make([]uint, 0, len(photos))
+	for _, photo := range photos {
+		photoIDs = append(photoIDs, photo.ID)
+	}
+
+	return likeService.getSummaries(photoIDs, userID)
+}
+
+func (likeService *LikeServiceRepository) getSummaries(photoIDs []uint, userID uint) (summaries map[uint]domain.LikeSummary, err error) {
+
+	rows, err := likeService.LikeRepository.GetSummaries(photoIDs, userID)
+	if err != nil {
+		return
+	}
+
+	summaries = make(map[uint]domain.LikeSummary, len(photoIDs))
+	for _, photoID := range photoIDs {
+		summaries[photoID] = domain.LikeSummary{PhotoID: photoID}
+	}
+
+	for _, row := range rows {
+		summaries[row.PhotoID] = row
+	}
+
+	return
+}
@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+
+	"mygram-api/helpers"
+)
+
+// FileMailer writes every email to a .eml file of a directory instead of
+// sending it, for development and tests
+type FileMailer struct {
+	Dir  string
+	From *mail.Address
+}
+
+func NewFileMailer(dir string, from *mail.Address) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating mail directory: %w", err)
+	}
+
+	return &FileMailer{Dir: dir, From: from}, nil
+}
+
+func (fileMailer *FileMailer) Send(ctx context.Context, message Message) (err error) {
+
+	now := time.Now()
+
+	data, err := compose(fileMailer.From, message, now)
+	if err != nil {
+		return
+	}
+
+	suffix, err := helpers.GenerateRandomToken(4)
+	if err != nil {
+		return
+	}
+
+	// named by time so the directory lists the emails in the order they were sent
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), suffix)
+
+	return os.WriteFile(filepath.Join(fileMailer.Dir, name), data, 0o600)
+}
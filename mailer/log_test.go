@@ -0,0 +1,22 @@
+package mailer
+
+import "testing"
+
+func TestRedactTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"no link here", "no link here"},
+		{"Open http://localhost:3000/verify-email?token=1.1700000000.c2ln to verify",
+			"Open http://localhost:3000/verify-email?token=REDACTED to verify"},
+		{"http://x/reset-password?token=abc&lang=en\nhttp://x/reset-password?token=def#top",
+			"http://x/reset-password?token=REDACTED&lang=en\nhttp://x/reset-password?token=REDACTED#top"},
+	}
+
+	for _, test := range tests {
+		if got := redactTokens(test.text); got != test.want {
+			t.Errorf("redactTokens(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+
+	"mygram-api/config"
+	"mygram-api/helpers"
+)
+
+var ErrInvalidMessage = errors.New("invalid email message")
+
+// Message is a plain text email to a single recipient
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(ctx context.Context, message Message) (err error)
+}
+
+// New builds the driver selected by MAIL_DRIVER
+func New(mailConfig config.MailConfig) (Mailer, error) {
+	from, err := mail.ParseAddress(mailConfig.From)
+	if err != nil {
+		return nil, fmt.Errorf("parsing MAIL_FROM: %w", err)
+	}
+
+	switch mailConfig.Driver {
+	case config.MailDriverSMTP:
+		return NewSMTPMailer(mailConfig.SMTP, from, mailConfig.Timeout), nil
+	case config.MailDriverFile:
+		return NewFileMailer(mailConfig.File.Dir, from)
+	case config.MailDriverLog:
+		log.Print("MAIL_DRIVER is log, emails are printed to the log and not sent")
+		return NewLogMailer(from), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", mailConfig.Driver)
+	}
+}
+
+// compose renders message as an RFC 5322 email from the given sender, with
+// the subject encoded for non-ASCII characters and the body quoted-printable
+func compose(from *mail.Address, message Message, now time.Time) ([]byte, error) {
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return nil, fmt.Errorf("%w: recipient: %v", ErrInvalidMessage, err)
+	}
+
+	if strings.ContainsAny(message.Subject, "\r\n") {
+		return nil, fmt.Errorf("%w: subject must not contain line breaks", ErrInvalidMessage)
+	}
+
+	id, err := helpers.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	domain := from.Address[strings.LastIndex(from.Address, "@")+1:]
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", id, domain)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	buf.WriteString("\r\n")
+
+	body := quotedprintable.NewWriter(&buf)
+	if _, err = body.Write([]byte(strings.ReplaceAll(message.Text, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+
+	if err = body.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,158 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testFrom = &mail.Address{Name: "MyGram", Address: "no-reply@mygram.test"}
+
+func parse(t *testing.T, data []byte) *mail.Message {
+	t.Helper()
+
+	message, err := mail.ReadMessage(bufio.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatalf("parsing the email: %v\n%s", err, data)
+	}
+
+	return message
+}
+
+func TestCompose(t *testing.T) {
+	now := time.Date(2023, 8, 8, 10, 0, 0, 0, time.UTC)
+	text := "Halo Budi,\n\nbuka tautan ini: http://localhost:3000/verify-email?token=1.1700000000.abc=def\n" + strings.Repeat("panjang ", 20)
+
+	data, err := compose(testFrom, Message{To: "Budi <budi@example.com>", Subject: "Verifikasi email — MyGram", Text: text}, now)
+	if err != nil {
+		t.Fatalf("composing: %v", err)
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\r\n"), "\r\n") {
+		if len(line) > 78 {
+			t.Errorf("line longer than 78 characters: %q", line)
+		}
+
+		if strings.Contains(line, "\n") {
+			t.Errorf("bare line feed in %q", line)
+		}
+	}
+
+	message := parse(t, data)
+
+	headers := map[string]string{
+		"From":                      `"MyGram" <no-reply@mygram.test>`,
+		"To":                        `"Budi" <budi@example.com>`,
+		"Date":                      "Tue, 08 Aug 2023 10:00:00 +0000",
+		"MIME-Version":              "1.0",
+		"Content-Type":              "text/plain; charset=utf-8",
+		"Content-Transfer-Encoding": "quoted-printable",
+	}
+
+	for name, want := range headers {
+		if got := message.Header.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	rawSubject := message.Header.Get("Subject")
+	if !strings.HasPrefix(rawSubject, "=?utf-8?q?") {
+		t.Errorf("Subject %q is not Q-encoded", rawSubject)
+	}
+
+	if subject, _ := new(mime.WordDecoder).DecodeHeader(rawSubject); subject != "Verifikasi email — MyGram" {
+		t.Errorf("Subject decodes to %q", subject)
+	}
+
+	if id := message.Header.Get("Message-ID"); !strings.HasSuffix(id, "@mygram.test>") {
+		t.Errorf("Message-ID = %q, want one at the domain of the sender", id)
+	}
+
+	body, err := io.ReadAll(quotedprintable.NewReader(message.Body))
+	if err != nil {
+		t.Fatalf("decoding the body: %v", err)
+	}
+
+	if got := strings.ReplaceAll(string(body), "\r\n", "\n"); got != text {
+		t.Errorf("body decodes to %q, want %q", got, text)
+	}
+}
+
+func TestComposeRejectsInvalidMessages(t *testing.T) {
+	messages := map[string]Message{
+		"recipient":         {To: "not an address", Subject: "Hi", Text: "Hi"},
+		"header injection":  {To: "user@example.com", Subject: "Hi\r\nBcc: victim@example.com", Text: "Hi"},
+		"line feed subject": {To: "user@example.com", Subject: "Hi\nthere", Text: "Hi"},
+	}
+
+	for name, message := range messages {
+		if _, err := compose(testFrom, message, time.Now()); !errors.Is(err, ErrInvalidMessage) {
+			t.Errorf("%s: error = %v, want ErrInvalidMessage", name, err)
+		}
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+
+	fileMailer, err := NewFileMailer(dir, testFrom)
+	if err != nil {
+		t.Fatalf("creating the mailer: %v", err)
+	}
+
+	for _, subject := range []string{"First", "Second"} {
+		if err = fileMailer.Send(context.Background(), Message{To: "user@example.com", Subject: subject, Text: "Hi"}); err != nil {
+			t.Fatalf("sending: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("listing the emails: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("wrote %d files, want 2", len(entries))
+	}
+
+	// the names sort in the order the emails were sent
+	for i, subject := range []string{"First", "Second"} {
+		entry := entries[i]
+
+		if filepath.Ext(entry.Name()) != ".eml" {
+			t.Errorf("file %s is not an .eml", entry.Name())
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			t.Fatalf("stat %s: %v", entry.Name(), err)
+		}
+
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("file %s has mode %v, want 0600 as it holds tokens", entry.Name(), info.Mode().Perm())
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatalf("reading %s: %v", entry.Name(), err)
+		}
+
+		if got := parse(t, data).Header.Get("Subject"); got != subject {
+			t.Errorf("file %d has subject %q, want %q", i, got, subject)
+		}
+	}
+
+	if err = fileMailer.Send(context.Background(), Message{To: "nobody", Subject: "Hi", Text: "Hi"}); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("error = %v, want ErrInvalidMessage", err)
+	}
+}
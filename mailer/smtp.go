@@ -0,0 +1,109 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"mygram-api/config"
+)
+
+var errNoStartTLS = errors.New("smtp server does not offer STARTTLS, which SMTP_REQUIRE_TLS requires")
+
+// SMTPMailer sends emails through an smtp server, with STARTTLS whenever the
+// server offers it or always when the config requires it
+type SMTPMailer struct {
+	Config  config.SMTPConfig
+	From    *mail.Address
+	Timeout time.Duration
+
+	// TLSConfig is used for STARTTLS when set, such as to trust a private
+	// certificate authority, otherwise the system roots verify the host
+	TLSConfig *tls.Config
+}
+
+func NewSMTPMailer(smtpConfig config.SMTPConfig, from *mail.Address, timeout time.Duration) *SMTPMailer {
+	return &SMTPMailer{Config: smtpConfig, From: from, Timeout: timeout}
+}
+
+func (smtpMailer *SMTPMailer) Send(ctx context.Context, message Message) (err error) {
+
+	data, err := compose(smtpMailer.From, message, time.Now())
+	if err != nil {
+		return
+	}
+
+	// compose checked the address already
+	to, _ := mail.ParseAddress(message.To)
+
+	ctx, cancel := context.WithTimeout(ctx, smtpMailer.Timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(smtpMailer.Config.Host, strconv.Itoa(smtpMailer.Config.Port)))
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// the smtp client takes no context, the deadline bounds the whole conversation
+	deadline, _ := ctx.Deadline()
+	if err = conn.SetDeadline(deadline); err != nil {
+		return
+	}
+
+	client, err := smtp.NewClient(conn, smtpMailer.Config.Host)
+	if err != nil {
+		return
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		tlsConfig := smtpMailer.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{ServerName: smtpMailer.Config.Host}
+		}
+
+		if err = client.StartTLS(tlsConfig); err != nil {
+			return
+		}
+	} else if smtpMailer.Config.RequireTLS {
+		return errNoStartTLS
+	}
+
+	// PlainAuth itself refuses to send the credentials in clear text to anything but localhost
+	if smtpMailer.Config.Username != "" {
+		if err = client.Auth(smtp.PlainAuth("", smtpMailer.Config.Username, smtpMailer.Config.Password, smtpMailer.Config.Host)); err != nil {
+			return
+		}
+	}
+
+	if err = client.Mail(smtpMailer.From.Address); err != nil {
+		return
+	}
+
+	if err = client.Rcpt(to.Address); err != nil {
+		return
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return
+	}
+
+	if _, err = writer.Write(data); err != nil {
+		return errors.Join(err, writer.Close())
+	}
+
+	if err = writer.Close(); err != nil {
+		return
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,280 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"mygram-api/config"
+)
+
+// smtpSink is an smtp server accepting one session, recording what it was sent
+type smtpSink struct {
+	// TLS is offered through STARTTLS when set
+	TLS        *tls.Config
+	RejectRcpt bool
+
+	listener net.Listener
+	done     chan struct{}
+
+	startedTLS bool
+	auth       string
+	from       string
+	rcpt       string
+	data       []byte
+	err        error
+}
+
+func newSMTPSink(t *testing.T, tlsConfig *tls.Config, rejectRcpt bool) *smtpSink {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+
+	sink := &smtpSink{TLS: tlsConfig, RejectRcpt: rejectRcpt, listener: listener, done: make(chan struct{})}
+
+	go sink.serve()
+
+	t.Cleanup(func() { listener.Close() })
+
+	return sink
+}
+
+func (sink *smtpSink) port() int {
+	return sink.listener.Addr().(*net.TCPAddr).Port
+}
+
+// wait returns once the session ended
+func (sink *smtpSink) wait(t *testing.T) {
+	t.Helper()
+
+	select {
+	case <-sink.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("smtp session never ended")
+	}
+}
+
+func (sink *smtpSink) serve() {
+	defer close(sink.done)
+
+	conn, err := sink.listener.Accept()
+	if err != nil {
+		sink.err = err
+		return
+	}
+	defer func() { conn.Close() }()
+
+	text := textproto.NewConn(conn)
+	reply := func(line string) { _ = text.PrintfLine("%s", line) }
+
+	reply("220 sink ESMTP")
+
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			reply("250-sink")
+
+			if sink.TLS != nil && !sink.startedTLS {
+				reply("250-STARTTLS")
+			}
+
+			reply("250 AUTH PLAIN")
+		case "STARTTLS":
+			reply("220 ready to start TLS")
+
+			tlsConn := tls.Server(conn, sink.TLS)
+			if err := tlsConn.Handshake(); err != nil {
+				sink.err = err
+				return
+			}
+
+			conn = tlsConn
+			text = textproto.NewConn(conn)
+			sink.startedTLS = true
+		case "AUTH":
+			sink.auth = arg
+			reply("235 authenticated")
+		case "MAIL":
+			sink.from = arg
+			reply("250 ok")
+		case "RCPT":
+			if sink.RejectRcpt {
+				reply("550 no such user")
+				continue
+			}
+
+			sink.rcpt = arg
+			reply("250 ok")
+		case "DATA":
+			reply("354 end with a dot")
+
+			if sink.data, err = text.ReadDotBytes(); err != nil {
+				sink.err = err
+				return
+			}
+
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+// sinkCertificate returns a certificate for 127.0.0.1 and a pool trusting it
+func sinkCertificate(t *testing.T) (*tls.Config, *x509.CertPool) {
+	t.Helper()
+
+	// the test server of net/http comes with such a certificate
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	return &tls.Config{Certificates: server.TLS.Certificates}, roots
+}
+
+func newTestSMTPMailer(sink *smtpSink, smtpConfig config.SMTPConfig) *SMTPMailer {
+	smtpConfig.Host = "127.0.0.1"
+	smtpConfig.Port = sink.port()
+
+	from := &mail.Address{Name: "MyGram", Address: "no-reply@mygram.test"}
+
+	return NewSMTPMailer(smtpConfig, from, 5*time.Second)
+}
+
+var testMessage = Message{To: "user@example.com", Subject: "Verify your email", Text: "Open the link"}
+
+func TestSMTPMailerSendsOverSTARTTLS(t *testing.T) {
+	serverTLS, roots := sinkCertificate(t)
+	sink := newSMTPSink(t, serverTLS, false)
+
+	smtpMailer := newTestSMTPMailer(sink, config.SMTPConfig{Username: "user", Password: "secret", RequireTLS: true})
+	smtpMailer.TLSConfig = &tls.Config{ServerName: "127.0.0.1", RootCAs: roots}
+
+	if err := smtpMailer.Send(context.Background(), testMessage); err != nil {
+		t.Fatalf("sending: %v", err)
+	}
+
+	sink.wait(t)
+
+	if sink.err != nil {
+		t.Fatalf("sink: %v", sink.err)
+	}
+
+	if !sink.startedTLS {
+		t.Error("the session did not switch to TLS")
+	}
+
+	if want := "PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00user\x00secret")); sink.auth != want {
+		t.Errorf("AUTH %q, want %q", sink.auth, want)
+	}
+
+	if sink.from != "FROM:<no-reply@mygram.test>" || sink.rcpt != "TO:<user@example.com>" {
+		t.Errorf("envelope %q %q", sink.from, sink.rcpt)
+	}
+
+	message, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(string(sink.data))))
+	if err != nil {
+		t.Fatalf("parsing the sent email: %v", err)
+	}
+
+	if got := message.Header.Get("Subject"); got != testMessage.Subject {
+		t.Errorf("Subject = %q, want %q", got, testMessage.Subject)
+	}
+}
+
+func TestSMTPMailerRequireTLSRefusesClearText(t *testing.T) {
+	sink := newSMTPSink(t, nil, false)
+
+	smtpMailer := newTestSMTPMailer(sink, config.SMTPConfig{RequireTLS: true})
+
+	if err := smtpMailer.Send(context.Background(), testMessage); !errors.Is(err, errNoStartTLS) {
+		t.Fatalf("error = %v, want %v", err, errNoStartTLS)
+	}
+
+	sink.wait(t)
+
+	if sink.from != "" || sink.data != nil {
+		t.Error("the email was sent in clear text")
+	}
+}
+
+func TestSMTPMailerSendsInClearTextUnlessRequired(t *testing.T) {
+	sink := newSMTPSink(t, nil, false)
+
+	if err := newTestSMTPMailer(sink, config.SMTPConfig{}).Send(context.Background(), testMessage); err != nil {
+		t.Fatalf("sending: %v", err)
+	}
+
+	sink.wait(t)
+
+	if sink.data == nil {
+		t.Error("nothing was sent")
+	}
+}
+
+func TestSMTPMailerReturnsTheRejection(t *testing.T) {
+	sink := newSMTPSink(t, nil, true)
+
+	err := newTestSMTPMailer(sink, config.SMTPConfig{}).Send(context.Background(), testMessage)
+
+	var protocolError *textproto.Error
+	if !errors.As(err, &protocolError) || protocolError.Code != 550 {
+		t.Fatalf("error = %v, want the 550 of the server", err)
+	}
+}
+
+func TestSMTPMailerTimesOut(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	defer listener.Close()
+
+	// a server that accepts and never greets
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(2 * time.Second)
+		}
+	}()
+
+	smtpConfig := config.SMTPConfig{Host: "127.0.0.1", Port: listener.Addr().(*net.TCPAddr).Port}
+	smtpMailer := NewSMTPMailer(smtpConfig, &mail.Address{Address: "no-reply@mygram.test"}, 100*time.Millisecond)
+
+	started := time.Now()
+
+	if err := smtpMailer.Send(context.Background(), testMessage); err == nil {
+		t.Fatal("sent to a server that never answered")
+	}
+
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("gave up after %v, want the 100ms timeout", elapsed)
+	}
+}
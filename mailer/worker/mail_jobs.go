@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net/textproto"
+
+	"mygram-api/jobs"
+	"mygram-api/mailer"
+)
+
+// MailJob sends an email. Its payload, links included, stays in the jobs
+// table until the cleanup, which is why the tokens of those links expire.
+var MailJob = jobs.Type[mailer.Message]{Name: "mail.send", Queue: jobs.QueueDefault, MaxAttempts: 5}
+
+// MailScheduler queues a MailJob for every email so requests do not wait on the mail server
+type MailScheduler struct {
+	Client *jobs.Client
+}
+
+func NewMailScheduler(client *jobs.Client) *MailScheduler {
+	return &MailScheduler{Client: client}
+}
+
+func (mailScheduler *MailScheduler) ScheduleMail(message mailer.Message) (err error) {
+
+	if _, err = jobs.Enqueue(mailScheduler.Client, MailJob, message); err != nil {
+		return
+	}
+
+	return
+}
+
+// MailHandler runs MailJob. An email the server rejects for good, with a 5xx
+// reply, or that is malformed is not retried.
+func MailHandler(sender mailer.Mailer) jobs.Handler {
+	return MailJob.Handle(func(ctx context.Context, message mailer.Message) error {
+		err := sender.Send(ctx, message)
+
+		var protocolErr *textproto.Error
+		if errors.Is(err, mailer.ErrInvalidMessage) || (errors.As(err, &protocolErr) && protocolErr.Code >= 500) {
+			return jobs.Permanent(err)
+		}
+
+		return err
+	})
+}
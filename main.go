@@ -1,13 +1,22 @@
 package main
 
 import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/gin-gonic/gin"
+	"github.com/urfave/cli/v2"
 
 	_ "mygram-api/docs"
 
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
+	"mygram-api/app"
+	"mygram-api/cmd"
+	"mygram-api/config"
 	"mygram-api/routes"
 )
 
@@ -28,17 +37,69 @@ import (
 // @description Authorization Bearer token
 func main() {
 
-	router := gin.Default()
+	cliApp := &cli.App{
+		Name:   "mygram-api",
+		Usage:  "MyGram is a social media application.",
+		Action: serve,
+		Commands: []*cli.Command{
+			{
+				Name:   "serve",
+				Usage:  "Start the HTTP server",
+				Action: serve,
+			},
+			cmd.MigrateCommand(),
+			cmd.WorkerCommand(),
+			cmd.UserCommand(),
+		},
+	}
+
+	if err := cliApp.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+
+}
+
+func serve(ctx *cli.Context) error {
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	container, err := app.NewContainer(cfg)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Jobs.RunInServer {
+		app.StartWorker(container)
+	}
+
+	// gin.Default logs the query strings, access tokens included
+	router := gin.New()
+	router.Use(app.RequestLogger(), gin.Recovery())
 
 	// Mount Swagger UI
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// Set up routes
-	routes.UserRoute(router)
-	routes.PhotoRoute(router)
-	routes.CommentRoute(router)
-	routes.SocialMediaRoute(router)
-	
-	router.Run(":8080")
+	routes.UserRoute(router, container)
+	routes.FollowRoute(router, container)
+	routes.FeedRoute(router, container)
+	routes.PhotoRoute(router, container)
+	routes.LikeRoute(router, container)
+	routes.TagRoute(router, container)
+	routes.SearchRoute(router, container)
+	routes.NotificationRoute(router, container)
+	routes.StreamRoute(router, container)
+	routes.WebhookRoute(router, container)
+	routes.CommentRoute(router, container)
+	routes.SocialMediaRoute(router, container)
+	routes.AdminRoute(router, container)
+
+	signalCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return app.Serve(signalCtx, app.NewServer(cfg.App, router), container)
 
 }
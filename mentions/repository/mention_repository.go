@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"mygram-api/models/domain"
+)
+
+type MentionRepository interface {
+	SetPhotoMentions(photoID uint, userIDs []uint) (added, removed []uint, err error)
+	SetCommentMentions(commentID uint, userIDs []uint) (added, removed []uint, err error)
+}
+
+type MentionRepositoryDB struct {
+	DB *gorm.DB
+}
+
+func NewMentionRepository(db *gorm.DB) MentionRepository {
+	return &MentionRepositoryDB{DB: db}
+}
+
+// SetPhotoMentions replaces the users mentioned in a photo caption with userIDs
+func (mentionRepository *MentionRepositoryDB) SetPhotoMentions(photoID uint, userIDs []uint) (added, removed []uint, err error) {
+
+	mentions := make([]domain.PhotoMention, 0, len(userIDs))
+	for _, userID := range userIDs {
+		mentions = append(mentions, domain.PhotoMention{PhotoID: photoID, UserID: userID})
+	}
+
+	return mentionRepository.set(&domain.PhotoMention{}, "photo_id", photoID, userIDs, &mentions)
+}
+
+// SetCommentMentions replaces the users mentioned in a comment with userIDs
+func (mentionRepository *MentionRepositoryDB) SetCommentMentions(commentID uint, userIDs []uint) (added, removed []uint, err error) {
+
+	mentions := make([]domain.CommentMention, 0, len(userIDs))
+	for _, userID := range userIDs {
+		mentions = append(mentions, domain.CommentMention{CommentID: commentID, UserID: userID})
+	}
+
+	return mentionRepository.set(&domain.CommentMention{}, "comment_id", commentID, userIDs, &mentions)
+}
+
+// set replaces the mention rows of model whose column is id with mentions,
+// which hold userIDs, and reports which users were added or removed
+func (mentionRepository *MentionRepositoryDB) set(model interface{}, column string, id uint, userIDs []uint, mentions interface{}) (added, removed []uint, err error) {
+
+	err = mentionRepository.DB.Transaction(func(tx *gorm.DB) error {
+		var current []uint
+
+		if err := tx.Model(model).Where(column+" = ?", id).Pluck("user_id", &current).Error; err != nil {
+			return err
+		}
+
+		added, removed = diff(current, userIDs)
+
+		if len(removed) > 0 {
+			if err := tx.Where(column+" = ? AND user_id IN ?", id, removed).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(userIDs) == 0 {
+			return nil
+		}
+
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(mentions).Error
+	})
+
+	return
+}
+
+// diff returns the ids of next missing from current, and those of current missing from next
+func diff(current, next []uint) (added, removed []uint) {
+	inCurrent := make(map[uint]bool, len(current))
+	for _, id := range current {
+		inCurrent[id] = true
+	}
+
+	inNext := make(map[uint]bool, len(next))
+	for _, id := range next {
+		inNext[id] = true
+
+		if !inCurrent[id] {
+			added = append(added, id)
+		}
+	}
+
+	for _, id := range current {
+		if !inNext[id] {
+			removed = append(removed, id)
+		}
+	}
+
+	return
+}
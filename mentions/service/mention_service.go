@@ -0,0 +1,116 @@
+package service
+
+import (
+	"strings"
+
+	"mygram-api/helpers"
+	"mygram-api/mentions/repository"
+	"mygram-api/models/domain"
+	userRepository "mygram-api/users/repository"
+)
+
+// MentionNotifier is told about the users newly mentioned in, or edited out
+// of, a photo caption or a comment. commentID is nil for a caption.
+type MentionNotifier interface {
+	Mentioned(actorID uint, userIDs []uint, photoID uint, commentID *uint)
+	Unmentioned(userIDs []uint, photoID uint, commentID *uint)
+}
+
+type MentionService interface {
+	SyncPhotoMentions(photo domain.Photo) (err error)
+	SyncCommentMentions(comment domain.Comment) (err error)
+}
+
+type MentionServiceRepository struct {
+	MentionRepository repository.MentionRepository
+	UserRepository    userRepository.UserRepository
+	MentionNotifier   MentionNotifier
+}
+
+func NewMentionService(mentionRepository repository.MentionRepository, userRepository userRepository.UserRepository, mentionNotifier MentionNotifier) MentionService {
+	return &MentionServiceRepository{MentionRepository: mentionRepository, UserRepository: userRepository, MentionNotifier: mentionNotifier}
+}
+
+// SyncPhotoMentions sets the mentions of a photo to the users its caption mentions
+func (mentionService *MentionServiceRepository) SyncPhotoMentions(photo domain.Photo) (err error) {
+
+	userIDs, err := mentionService.resolve(photo.Caption, photo.UserID)
+	if err != nil {
+		return
+	}
+
+	added, removed, err := mentionService.MentionRepository.SetPhotoMentions(photo.ID, userIDs)
+	if err != nil {
+		return
+	}
+
+	mentionService.notify(photo.UserID, added, removed, photo.ID, nil)
+
+	return
+}
+
+// SyncCommentMentions sets the mentions of a comment to the users its message mentions
+func (mentionService *MentionServiceRepository) SyncCommentMentions(comment domain.Comment) (err error) {
+
+	userIDs, err := mentionService.resolve(comment.Message, comment.UserID)
+	if err != nil {
+		return
+	}
+
+	added, removed, err := mentionService.MentionRepository.SetCommentMentions(comment.ID, userIDs)
+	if err != nil {
+		return
+	}
+
+	mentionService.notify(comment.UserID, added, removed, comment.PhotoID, &comment.ID)
+
+	return
+}
+
+// resolve returns the ids of the users mentioned in text by the author, who
+// does not mention themselves. Usernames match whatever their case, the user
+// whose username is written exactly winning over the ones differing in case
+// only. Unknown usernames stay plain text.
+func (mentionService *MentionServiceRepository) resolve(text string, authorID uint) (userIDs []uint, err error) {
+
+	usernames := helpers.ParseMentions(text)
+
+	users, err := mentionService.UserRepository.GetByUsernames(usernames)
+	if err != nil {
+		return
+	}
+
+	written := make(map[string]string, len(usernames))
+	for _, username := range usernames {
+		written[strings.ToLower(username)] = username
+	}
+
+	// the users come ordered by id, the oldest account wins among the inexact matches
+	mentioned := make(map[string]domain.User, len(usernames))
+	for _, user := range users {
+		key := strings.ToLower(user.Username)
+
+		if current, ok := mentioned[key]; !ok || (current.Username != written[key] && user.Username == written[key]) {
+			mentioned[key] = user
+		}
+	}
+
+	for _, username := range usernames {
+		if user, ok := mentioned[strings.ToLower(username)]; ok && user.ID != authorID {
+			userIDs = append(userIDs, user.ID)
+		}
+	}
+
+	return
+}
+
+func (mentionService *MentionServiceRepository) notify(actorID uint, added, removed []uint, photoID uint, commentID *uint) {
+
+	mentionService.MentionNotifier.Unmentioned(removed, photoID, commentID)
+
+	if len(added) == 0 {
+		return
+	}
+
+	mentionService.MentionNotifier.Mentioned(actorID, added, photoID, commentID)
+}
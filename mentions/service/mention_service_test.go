@@ -0,0 +1,109 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"mygram-api/models/domain"
+	userRepository "mygram-api/users/repository"
+)
+
+// memoryUsers looks usernames up like the database does, whatever their
+// case and ordered by id. The other methods are left to the nil interface.
+type memoryUsers struct {
+	userRepository.UserRepository
+
+	users []domain.User
+}
+
+func (memory *memoryUsers) GetByUsernames(usernames []string) (users []domain.User, err error) {
+	for _, user := range memory.users {
+		for _, username := range usernames {
+			if strings.EqualFold(user.Username, username) {
+				users = append(users, user)
+				break
+			}
+		}
+	}
+
+	return
+}
+
+// memoryMentions reports every user as newly mentioned
+type memoryMentions struct{}
+
+func (memoryMentions) SetPhotoMentions(photoID uint, userIDs []uint) (added, removed []uint, err error) {
+	return userIDs, nil, nil
+}
+
+func (memoryMentions) SetCommentMentions(commentID uint, userIDs []uint) (added, removed []uint, err error) {
+	return userIDs, nil, nil
+}
+
+type recordingNotifier struct {
+	mentioned []uint
+}
+
+func (notifier *recordingNotifier) Mentioned(actorID uint, userIDs []uint, photoID uint, commentID *uint) {
+	notifier.mentioned = append(notifier.mentioned, userIDs...)
+}
+
+func (notifier *recordingNotifier) Unmentioned(userIDs []uint, photoID uint, commentID *uint) {}
+
+func TestMentionsMatchUsernamesWhateverTheirCase(t *testing.T) {
+	users := &memoryUsers{users: []domain.User{
+		{ID: 1, Username: "author"},
+		{ID: 2, Username: "budi"},
+		{ID: 3, Username: "Sari"},
+		{ID: 4, Username: "sari"},
+		{ID: 5, Username: "Agus"},
+		{ID: 6, Username: "AGUS"},
+	}}
+	notifier := &recordingNotifier{}
+
+	mentionService := NewMentionService(memoryMentions{}, users, notifier)
+
+	tests := []struct {
+		caption string
+		want    []uint
+	}{
+		{"hi @Budi", []uint{2}},
+		{"hi @BUDI and @budi", []uint{2}},
+		// a user named exactly as written wins over one differing in case only
+		{"hi @sari", []uint{4}},
+		{"hi @Sari", []uint{3}},
+		// otherwise the oldest account does
+		{"hi @agus", []uint{5}},
+		{"hi @Author and @nobody", nil},
+	}
+
+	for _, test := range tests {
+		notifier.mentioned = nil
+
+		if err := mentionService.SyncPhotoMentions(domain.Photo{ID: 1, UserID: 1, Caption: test.caption}); err != nil {
+			t.Fatalf("syncing %q: %v", test.caption, err)
+		}
+
+		if !reflect.DeepEqual(notifier.mentioned, test.want) {
+			t.Errorf("%q mentioned %v, want %v", test.caption, notifier.mentioned, test.want)
+		}
+	}
+}
+
+type failingUsers struct {
+	userRepository.UserRepository
+}
+
+func (failingUsers) GetByUsernames([]string) ([]domain.User, error) {
+	return nil, errors.New("database unavailable")
+}
+
+func TestMentionLookupFailureIsReturned(t *testing.T) {
+	mentionService := NewMentionService(memoryMentions{}, failingUsers{}, &recordingNotifier{})
+
+	if err := mentionService.SyncCommentMentions(domain.Comment{ID: 1, Message: "hi @budi"}); err == nil {
+		t.Error("the lookup failure was hidden")
+	}
+}
@@ -0,0 +1,124 @@
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// noTransactionDirective marks a migration that must run outside a transaction,
+// e.g. one that uses CREATE INDEX CONCURRENTLY
+const noTransactionDirective = "-- migrate:no-transaction"
+
+//go:embed sql/*.sql
+var files embed.FS
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+var namePattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Migration represents one versioned schema change
+type Migration struct {
+	Version       int64
+	Name          string
+	Up            string
+	Down          string
+	NoTransaction bool
+}
+
+// Load returns the migrations embedded in the binary ordered by version
+func Load() ([]Migration, error) {
+	return Parse(files, "sql")
+}
+
+// Parse reads <version>_<name>.up.sql and <version>_<name>.down.sql pairs from dir
+func Parse(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s does not match <version>_<name>.(up|down).sql", entry.Name())
+		}
+
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+
+		content, err := fs.ReadFile(fsys, filepath.ToSlash(filepath.Join(dir, entry.Name())))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+			migration.NoTransaction = strings.HasPrefix(strings.TrimSpace(migration.Up), noTransactionDirective)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", migration.Version, migration.Name)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Create writes an empty up/down pair into dir and returns their paths
+func Create(dir, name string, now time.Time) (upPath, downPath string, err error) {
+	name = strings.Trim(namePattern.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", "", fmt.Errorf("migration name must contain letters or digits")
+	}
+
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", err
+	}
+
+	base := fmt.Sprintf("%s_%s", now.UTC().Format("20060102150405"), name)
+	upPath = filepath.Join(dir, base+".up.sql")
+	downPath = filepath.Join(dir, base+".down.sql")
+
+	for _, path := range []string{upPath, downPath} {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return "", "", err
+		}
+
+		file.Close()
+	}
+
+	return upPath, downPath, nil
+}
@@ -0,0 +1,206 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// advisoryLockKey serialises concurrent migration runs across instances
+const advisoryLockKey = 720_415_2023
+
+// SchemaMigration represents a row of the schema_migrations table
+type SchemaMigration struct {
+	Version   int64  `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"not null"`
+	AppliedAt time.Time
+}
+
+// MigrationStatus pairs a migration with the time it was applied, if any
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrator applies and rolls back migrations against a database
+type Migrator struct {
+	DB         *gorm.DB
+	Migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{DB: db, Migrations: migrations}, nil
+}
+
+// Up applies up to steps pending migrations, or all of them when steps is 0
+func (migrator *Migrator) Up(steps int) (applied []Migration, err error) {
+	err = migrator.withLock(func(db *gorm.DB) error {
+		done, err := migrator.applied(db)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrator.Migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+
+			if steps > 0 && len(applied) == steps {
+				break
+			}
+
+			if err = migrator.run(db, migration, migration.Up, func(tx *gorm.DB) error {
+				return tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+			}); err != nil {
+				return fmt.Errorf("applying %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// Down rolls back the last steps applied migrations, at least one
+func (migrator *Migrator) Down(steps int) (reverted []Migration, err error) {
+	if steps < 1 {
+		steps = 1
+	}
+
+	err = migrator.withLock(func(db *gorm.DB) error {
+		done, err := migrator.applied(db)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrator.Migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := migrator.Migrations[i]
+
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down script", migration.Version, migration.Name)
+			}
+
+			if err = migrator.run(db, migration, migration.Down, func(tx *gorm.DB) error {
+				return tx.Delete(&SchemaMigration{}, migration.Version).Error
+			}); err != nil {
+				return fmt.Errorf("reverting %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// Status lists every known migration with its applied time
+func (migrator *Migrator) Status() (statuses []MigrationStatus, err error) {
+	if err = migrator.DB.AutoMigrate(&SchemaMigration{}); err != nil {
+		return
+	}
+
+	done, err := migrator.applied(migrator.DB)
+	if err != nil {
+		return
+	}
+
+	for _, migration := range migrator.Migrations {
+		status := MigrationStatus{Migration: migration}
+
+		if row, ok := done[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return
+}
+
+// Pending returns the migrations that have not been applied yet
+func (migrator *Migrator) Pending() (pending []Migration, err error) {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return
+	}
+
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, status.Migration)
+		}
+	}
+
+	return
+}
+
+func (migrator *Migrator) withLock(fc func(db *gorm.DB) error) error {
+	return migrator.DB.Connection(func(db *gorm.DB) (err error) {
+		if err = db.Exec("SELECT pg_advisory_lock(?)", advisoryLockKey).Error; err != nil {
+			return
+		}
+
+		defer func() {
+			err = errors.Join(err, db.Exec("SELECT pg_advisory_unlock(?)", advisoryLockKey).Error)
+		}()
+
+		if err = db.AutoMigrate(&SchemaMigration{}); err != nil {
+			return
+		}
+
+		return fc(db)
+	})
+}
+
+func (migrator *Migrator) applied(db *gorm.DB) (map[int64]SchemaMigration, error) {
+	var rows []SchemaMigration
+
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	done := make(map[int64]SchemaMigration, len(rows))
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+
+	return done, nil
+}
+
+func (migrator *Migrator) run(db *gorm.DB, migration Migration, script string, record func(tx *gorm.DB) error) error {
+	if migration.NoTransaction {
+		// postgres runs a multi-statement query in an implicit transaction,
+		// so each statement is sent on its own
+		for _, statement := range splitStatements(script) {
+			if err := db.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		return record(db)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(script).Error; err != nil {
+			return err
+		}
+
+		return record(tx)
+	})
+}
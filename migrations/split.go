@@ -0,0 +1,93 @@
+package migrations
+
+import "strings"
+
+// splitStatements splits a script on semicolons that are not inside quotes,
+// dollar-quoted bodies or comments, and drops statements that are empty or
+// only contain comments
+func splitStatements(script string) (statements []string) {
+	var (
+		current     strings.Builder
+		dollarQuote string
+		hasCode     bool
+	)
+
+	flush := func() {
+		if hasCode {
+			statements = append(statements, strings.TrimSpace(current.String()))
+		}
+
+		current.Reset()
+		hasCode = false
+	}
+
+	for i := 0; i < len(script); i++ {
+		char := script[i]
+
+		switch {
+		case dollarQuote != "":
+			if strings.HasPrefix(script[i:], dollarQuote) {
+				current.WriteString(dollarQuote)
+				i += len(dollarQuote) - 1
+				dollarQuote = ""
+				continue
+			}
+		case char == '-' && strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+
+			current.WriteString(script[i : i+end])
+			i += end - 1
+			continue
+		case char == '\'' || char == '"':
+			// the length of the quoted text, both quotes included, or of the
+			// rest of an unterminated one
+			end := strings.IndexByte(script[i+1:], char) + 2
+			if end < 2 {
+				end = len(script) - i
+			}
+
+			current.WriteString(script[i : i+end])
+			i += end - 1
+			hasCode = true
+			continue
+		case char == '$':
+			if tag := dollarTag(script[i:]); tag != "" {
+				dollarQuote = tag
+				current.WriteString(tag)
+				i += len(tag) - 1
+				hasCode = true
+				continue
+			}
+		case char == ';':
+			flush()
+			continue
+		}
+
+		current.WriteByte(char)
+		if char != ' ' && char != '\t' && char != '\n' && char != '\r' {
+			hasCode = true
+		}
+	}
+
+	flush()
+
+	return
+}
+
+// dollarTag returns the opening $tag$ at the start of s, if any
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch char := s[i]; {
+		case char == '$':
+			return s[:i+1]
+		case char == '_' || char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || i > 1 && char >= '0' && char <= '9':
+		default:
+			return ""
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{"empty", "", nil},
+		{"blank", " \n\t;\n;", nil},
+		{"one without semicolon", "SELECT 1", []string{"SELECT 1"}},
+		{"several", "CREATE TABLE a (id int);\nCREATE TABLE b (id int);\n", []string{"CREATE TABLE a (id int)", "CREATE TABLE b (id int)"}},
+		{"semicolon in a string", "INSERT INTO a VALUES ('x;y'); SELECT 1", []string{"INSERT INTO a VALUES ('x;y')", "SELECT 1"}},
+		{"escaped quote in a string", "INSERT INTO a VALUES ('it''s; fine'); SELECT 1", []string{"INSERT INTO a VALUES ('it''s; fine')", "SELECT 1"}},
+		{"semicolon in an identifier", `CREATE TABLE "a;b" (id int)`, []string{`CREATE TABLE "a;b" (id int)`}},
+		{"unterminated string", "SELECT 'x;", []string{"SELECT 'x;"}},
+		{"unterminated identifier", `SELECT "`, []string{`SELECT "`}},
+		{"semicolon in a comment", "-- drop; this\nSELECT 1;", []string{"-- drop; this\nSELECT 1"}},
+		{"comment only statement", "SELECT 1;\n-- trailing note;\n", []string{"SELECT 1"}},
+		{"comment at the end without newline", "SELECT 1; -- done", []string{"SELECT 1"}},
+		{
+			"dollar-quoted body",
+			"CREATE FUNCTION f() RETURNS trigger AS $$ BEGIN x; y; END $$ LANGUAGE plpgsql; SELECT 1",
+			[]string{"CREATE FUNCTION f() RETURNS trigger AS $$ BEGIN x; y; END $$ LANGUAGE plpgsql", "SELECT 1"},
+		},
+		{
+			"tagged dollar quote containing $$",
+			"DO $body$ BEGIN PERFORM '$$;'; END $body$; SELECT 2",
+			[]string{"DO $body$ BEGIN PERFORM '$$;'; END $body$", "SELECT 2"},
+		},
+		{"positional parameter is not a quote", "SELECT $1; SELECT $2", []string{"SELECT $1", "SELECT $2"}},
+		{"unterminated dollar quote", "DO $$ BEGIN; END", []string{"DO $$ BEGIN; END"}},
+	}
+
+	for _, test := range tests {
+		if got := splitStatements(test.script); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: splitStatements(%q) = %q, want %q", test.name, test.script, got, test.want)
+		}
+	}
+}
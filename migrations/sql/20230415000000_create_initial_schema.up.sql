@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS users (
+    id         bigserial PRIMARY KEY,
+    username   text NOT NULL,
+    age        bigint NOT NULL,
+    email      text NOT NULL,
+    password   text NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+
+CREATE TABLE IF NOT EXISTS photos (
+    id         bigserial PRIMARY KEY,
+    title      text NOT NULL,
+    caption    text,
+    photo_url  text NOT NULL,
+    user_id    bigint NOT NULL,
+    updated_at timestamptz,
+    created_at timestamptz,
+    CONSTRAINT fk_photos_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE TABLE IF NOT EXISTS comments (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    photo_id   bigint NOT NULL,
+    message    text NOT NULL,
+    updated_at timestamptz,
+    created_at timestamptz,
+    CONSTRAINT fk_comments_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_comments_photo FOREIGN KEY (photo_id) REFERENCES photos (id)
+);
+
+CREATE TABLE IF NOT EXISTS social_media (
+    id               bigserial PRIMARY KEY,
+    name             text NOT NULL,
+    social_media_url text NOT NULL,
+    user_id          bigint NOT NULL,
+    updated_at       timestamptz,
+    created_at       timestamptz,
+    CONSTRAINT fk_social_media_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
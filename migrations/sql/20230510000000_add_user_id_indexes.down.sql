@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_social_media_user_id;
+DROP INDEX IF EXISTS idx_photos_user_id;
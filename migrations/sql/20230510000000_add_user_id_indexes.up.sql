@@ -0,0 +1,3 @@
+-- migrate:no-transaction
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_photos_user_id ON photos (user_id);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_social_media_user_id ON social_media (user_id);
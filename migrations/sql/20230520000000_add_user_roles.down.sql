@@ -0,0 +1,4 @@
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS chk_users_role,
+    DROP COLUMN IF EXISTS banned_at,
+    DROP COLUMN IF EXISTS role;
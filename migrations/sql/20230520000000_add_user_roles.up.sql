@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN role text NOT NULL DEFAULT 'user',
+    ADD COLUMN banned_at timestamptz,
+    ADD CONSTRAINT chk_users_role CHECK (role IN ('user', 'moderator', 'admin'));
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_comments_photo_id;
+DROP INDEX IF EXISTS idx_comments_user_id;
+DROP INDEX IF EXISTS idx_social_media_created_at_id;
+DROP INDEX IF EXISTS idx_comments_created_at_id;
+DROP INDEX IF EXISTS idx_photos_created_at_id;
@@ -0,0 +1,6 @@
+-- migrate:no-transaction
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_photos_created_at_id ON photos (created_at, id);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_comments_created_at_id ON comments (created_at, id);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_social_media_created_at_id ON social_media (created_at, id);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_comments_user_id ON comments (user_id);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_comments_photo_id ON comments (photo_id);
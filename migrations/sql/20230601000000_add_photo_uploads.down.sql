@@ -0,0 +1,6 @@
+ALTER TABLE photos
+    DROP COLUMN height,
+    DROP COLUMN width,
+    DROP COLUMN byte_size,
+    DROP COLUMN content_type,
+    DROP COLUMN storage_key;
@@ -0,0 +1,6 @@
+ALTER TABLE photos
+    ADD COLUMN storage_key text NOT NULL DEFAULT '',
+    ADD COLUMN content_type text NOT NULL DEFAULT '',
+    ADD COLUMN byte_size bigint NOT NULL DEFAULT 0,
+    ADD COLUMN width integer NOT NULL DEFAULT 0,
+    ADD COLUMN height integer NOT NULL DEFAULT 0;
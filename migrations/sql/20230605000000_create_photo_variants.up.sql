@@ -0,0 +1,15 @@
+CREATE TABLE photo_variants (
+    id           bigserial PRIMARY KEY,
+    photo_id     bigint NOT NULL,
+    name         text NOT NULL,
+    storage_key  text NOT NULL,
+    url          text NOT NULL,
+    content_type text NOT NULL,
+    byte_size    bigint NOT NULL,
+    width        bigint NOT NULL,
+    height       bigint NOT NULL,
+    created_at   timestamptz,
+    CONSTRAINT fk_photos_variants FOREIGN KEY (photo_id) REFERENCES photos (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_photo_variants_photo_id_name ON photo_variants (photo_id, name);
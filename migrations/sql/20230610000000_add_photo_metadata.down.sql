@@ -0,0 +1,6 @@
+ALTER TABLE photos
+    DROP COLUMN longitude,
+    DROP COLUMN latitude,
+    DROP COLUMN orientation,
+    DROP COLUMN camera_model,
+    DROP COLUMN taken_at;
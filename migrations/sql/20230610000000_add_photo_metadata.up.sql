@@ -0,0 +1,6 @@
+ALTER TABLE photos
+    ADD COLUMN taken_at timestamptz,
+    ADD COLUMN camera_model text NOT NULL DEFAULT '',
+    ADD COLUMN orientation integer NOT NULL DEFAULT 0,
+    ADD COLUMN latitude double precision,
+    ADD COLUMN longitude double precision;
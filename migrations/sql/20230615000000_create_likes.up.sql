@@ -0,0 +1,11 @@
+CREATE TABLE likes (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    photo_id   bigint NOT NULL,
+    created_at timestamptz,
+    CONSTRAINT fk_likes_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_likes_photo FOREIGN KEY (photo_id) REFERENCES photos (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_likes_user_id_photo_id ON likes (user_id, photo_id);
+CREATE INDEX idx_likes_photo_id_created_at_id ON likes (photo_id, created_at, id);
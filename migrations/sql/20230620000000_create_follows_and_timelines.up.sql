@@ -0,0 +1,29 @@
+CREATE TABLE follows (
+    id          bigserial PRIMARY KEY,
+    follower_id bigint NOT NULL,
+    followee_id bigint NOT NULL,
+    created_at  timestamptz,
+    CONSTRAINT fk_follows_follower FOREIGN KEY (follower_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_follows_followee FOREIGN KEY (followee_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT chk_follows_not_self CHECK (follower_id <> followee_id)
+);
+
+CREATE UNIQUE INDEX idx_follows_follower_id_followee_id ON follows (follower_id, followee_id);
+CREATE INDEX idx_follows_follower_id_created_at_id ON follows (follower_id, created_at, id);
+CREATE INDEX idx_follows_followee_id_created_at_id ON follows (followee_id, created_at, id);
+
+CREATE TABLE timelines (
+    user_id    bigint PRIMARY KEY,
+    created_at timestamptz,
+    CONSTRAINT fk_timelines_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE timeline_entries (
+    user_id  bigint NOT NULL,
+    photo_id bigint NOT NULL,
+    PRIMARY KEY (user_id, photo_id),
+    CONSTRAINT fk_timeline_entries_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_timeline_entries_photo FOREIGN KEY (photo_id) REFERENCES photos (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_timeline_entries_photo_id ON timeline_entries (photo_id);
@@ -0,0 +1,19 @@
+CREATE TABLE tags (
+    id         bigserial PRIMARY KEY,
+    name       text NOT NULL,
+    created_at timestamptz
+);
+
+CREATE UNIQUE INDEX idx_tags_name ON tags (name);
+-- lets the autocomplete use the index for name LIKE 'prefix%' whatever the collation
+CREATE INDEX idx_tags_name_pattern ON tags (name text_pattern_ops);
+
+CREATE TABLE photo_tags (
+    photo_id bigint NOT NULL,
+    tag_id   bigint NOT NULL,
+    PRIMARY KEY (photo_id, tag_id),
+    CONSTRAINT fk_photo_tags_photo FOREIGN KEY (photo_id) REFERENCES photos (id) ON DELETE CASCADE,
+    CONSTRAINT fk_photo_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_photo_tags_tag_id ON photo_tags (tag_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_users_search_vector;
+DROP INDEX IF EXISTS idx_comments_search_vector;
+DROP INDEX IF EXISTS idx_photos_search_vector;
+
+ALTER TABLE users DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE comments DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE photos DROP COLUMN IF EXISTS search_vector;
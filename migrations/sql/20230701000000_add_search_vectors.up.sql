@@ -0,0 +1,11 @@
+-- migrate:no-transaction
+ALTER TABLE photos ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (setweight(to_tsvector('simple', coalesce(title, '')), 'A') || setweight(to_tsvector('simple', coalesce(caption, '')), 'B')) STORED;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(message, ''))) STORED;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(username, ''))) STORED;
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_photos_search_vector ON photos USING gin (search_vector);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_comments_search_vector ON comments USING gin (search_vector);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_search_vector ON users USING gin (search_vector);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_comments_parent_id;
+ALTER TABLE comments
+    DROP CONSTRAINT fk_comments_replies,
+    DROP COLUMN deleted_at,
+    DROP COLUMN depth,
+    DROP COLUMN parent_id;
@@ -0,0 +1,7 @@
+-- migrate:no-transaction
+ALTER TABLE comments
+    ADD COLUMN parent_id bigint,
+    ADD COLUMN depth integer NOT NULL DEFAULT 0,
+    ADD COLUMN deleted_at timestamptz,
+    ADD CONSTRAINT fk_comments_replies FOREIGN KEY (parent_id) REFERENCES comments (id) ON DELETE SET NULL;
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_comments_parent_id ON comments (parent_id);
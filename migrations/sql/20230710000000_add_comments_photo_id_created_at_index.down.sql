@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_comments_photo_id ON comments (photo_id);
+DROP INDEX IF EXISTS idx_comments_photo_id_created_at_id;
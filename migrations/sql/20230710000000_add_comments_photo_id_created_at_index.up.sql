@@ -0,0 +1,3 @@
+-- migrate:no-transaction
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_comments_photo_id_created_at_id ON comments (photo_id, created_at, id);
+DROP INDEX CONCURRENTLY IF EXISTS idx_comments_photo_id;
@@ -0,0 +1,38 @@
+CREATE TABLE photo_mentions (
+    photo_id bigint NOT NULL,
+    user_id  bigint NOT NULL,
+    PRIMARY KEY (photo_id, user_id),
+    CONSTRAINT fk_photo_mentions_photo FOREIGN KEY (photo_id) REFERENCES photos (id) ON DELETE CASCADE,
+    CONSTRAINT fk_photo_mentions_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_photo_mentions_user_id ON photo_mentions (user_id);
+
+CREATE TABLE comment_mentions (
+    comment_id bigint NOT NULL,
+    user_id    bigint NOT NULL,
+    PRIMARY KEY (comment_id, user_id),
+    CONSTRAINT fk_comment_mentions_comment FOREIGN KEY (comment_id) REFERENCES comments (id) ON DELETE CASCADE,
+    CONSTRAINT fk_comment_mentions_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_comment_mentions_user_id ON comment_mentions (user_id);
+
+CREATE TABLE notifications (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    actor_id   bigint NOT NULL,
+    type       text NOT NULL,
+    photo_id   bigint,
+    comment_id bigint,
+    created_at timestamptz,
+    CONSTRAINT fk_notifications_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_notifications_actor FOREIGN KEY (actor_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_notifications_photo FOREIGN KEY (photo_id) REFERENCES photos (id) ON DELETE CASCADE,
+    CONSTRAINT fk_notifications_comment FOREIGN KEY (comment_id) REFERENCES comments (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_notifications_user_id_created_at_id ON notifications (user_id, created_at, id);
+CREATE INDEX idx_notifications_actor_id ON notifications (actor_id);
+CREATE INDEX idx_notifications_photo_id ON notifications (photo_id);
+CREATE INDEX idx_notifications_comment_id ON notifications (comment_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_notifications_user_id_unread;
+ALTER TABLE notifications DROP COLUMN read_at;
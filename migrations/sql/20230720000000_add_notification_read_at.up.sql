@@ -0,0 +1,4 @@
+ALTER TABLE notifications ADD COLUMN read_at timestamptz;
+
+-- keeps the unread count cheap however many notifications were read
+CREATE INDEX idx_notifications_user_id_unread ON notifications (user_id) WHERE read_at IS NULL;
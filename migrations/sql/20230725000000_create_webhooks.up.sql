@@ -0,0 +1,33 @@
+CREATE TABLE webhooks (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    url        text NOT NULL,
+    secret     text NOT NULL,
+    events     text NOT NULL,
+    active     boolean NOT NULL DEFAULT true,
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT fk_webhooks_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_webhooks_user_id ON webhooks (user_id);
+
+CREATE TABLE webhook_deliveries (
+    id              bigserial PRIMARY KEY,
+    webhook_id      bigint NOT NULL,
+    event           text NOT NULL,
+    payload         text NOT NULL,
+    status          text NOT NULL,
+    attempts        bigint NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz NOT NULL,
+    last_attempt_at timestamptz,
+    response_status bigint,
+    response_body   text,
+    last_error      text,
+    created_at      timestamptz,
+    updated_at      timestamptz,
+    CONSTRAINT fk_webhook_deliveries_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook_id_created_at_id ON webhook_deliveries (webhook_id, created_at, id);
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,28 @@
+CREATE TABLE jobs (
+    id           bigserial PRIMARY KEY,
+    queue        text NOT NULL,
+    type         text NOT NULL,
+    payload      text NOT NULL,
+    status       text NOT NULL,
+    attempts     bigint NOT NULL DEFAULT 0,
+    max_attempts bigint NOT NULL,
+    unique_key   text,
+    run_at       timestamptz NOT NULL,
+    locked_until timestamptz,
+    last_error   text,
+    finished_at  timestamptz,
+    created_at   timestamptz,
+    updated_at   timestamptz
+);
+
+CREATE INDEX idx_jobs_due ON jobs (queue, run_at, id) WHERE status = 'pending';
+CREATE INDEX idx_jobs_locked_until ON jobs (locked_until) WHERE status = 'running';
+CREATE UNIQUE INDEX idx_jobs_unique_key ON jobs (unique_key) WHERE status IN ('pending', 'running');
+CREATE INDEX idx_jobs_finished_at ON jobs (finished_at);
+
+-- webhook deliveries are scheduled as jobs now, starting with the pending ones
+INSERT INTO jobs (queue, type, payload, status, attempts, max_attempts, unique_key, run_at, created_at, updated_at)
+SELECT 'webhooks', 'webhooks.deliver', json_build_object('delivery_id', id)::text, 'pending', 0, 3,
+    'webhooks.deliver:' || id || ':' || attempts, next_attempt_at, now(), now()
+FROM webhook_deliveries
+WHERE status = 'pending';
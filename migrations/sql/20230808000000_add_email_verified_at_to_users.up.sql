@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN email_verified_at timestamptz;
+
+-- the accounts registered before verification existed were never sent a
+-- link, they are trusted as they were so requiring verification locks no one out
+UPDATE users SET email_verified_at = coalesce(created_at, now());
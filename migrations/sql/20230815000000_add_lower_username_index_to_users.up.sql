@@ -0,0 +1,2 @@
+-- mentions match usernames whatever their case
+CREATE INDEX idx_users_lower_username ON users (lower(username));
@@ -4,12 +4,25 @@ import "time"
 
 // Comment represents the model of a comment
 type Comment struct {
-	ID        uint   `gorm:"primaryKey"`
-	UserID    uint   `gorm:"not null"`
-	PhotoID   uint   `gorm:"not null"`
+	ID        uint   `gorm:"primaryKey;index:idx_comments_created_at_id,priority:2;index:idx_comments_photo_id_created_at_id,priority:3"`
+	UserID    uint   `gorm:"not null;index"`
+	PhotoID   uint   `gorm:"not null;index:idx_comments_photo_id_created_at_id,priority:1"`
 	Message   string `gorm:"not null"`
 	UpdatedAt time.Time
-	CreatedAt time.Time
-	User      User  `gorm:"foreignKey:UserID"`
-	Photo     Photo `gorm:"foreignKey:PhotoID"`
-}
\ No newline at end of file
+	CreatedAt time.Time `gorm:"index:idx_comments_created_at_id,priority:1;index:idx_comments_photo_id_created_at_id,priority:2"`
+	User      User      `gorm:"foreignKey:UserID"`
+	Photo     Photo     `gorm:"foreignKey:PhotoID"`
+
+	// ParentID is the comment replied to, nil for a top level comment. The
+	// replies to a comment removed with its author's account become top level.
+	ParentID *uint `gorm:"index"`
+	// Depth is the number of comments above this one, 0 for a top level comment
+	Depth int `gorm:"not null;default:0"`
+	// DeletedAt is set when a comment with replies is deleted, it is kept
+	// without its message so the replies stay in the thread
+	DeletedAt *time.Time
+	Replies   []Comment `gorm:"foreignKey:ParentID;constraint:OnDelete:SET NULL"`
+
+	// SearchVector is generated by postgres for the full-text search, it is never read or written
+	SearchVector string `gorm:"->:false;<-:false;type:tsvector GENERATED ALWAYS AS (to_tsvector('simple', coalesce(message, ''))) STORED;index:idx_comments_search_vector,type:gin"`
+}
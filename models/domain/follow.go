@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// Follow represents a user following another user
+type Follow struct {
+	ID         uint      `gorm:"primaryKey;index:idx_follows_follower_id_created_at_id,priority:3;index:idx_follows_followee_id_created_at_id,priority:3"`
+	FollowerID uint      `gorm:"not null;uniqueIndex:idx_follows_follower_id_followee_id;index:idx_follows_follower_id_created_at_id,priority:1"`
+	FolloweeID uint      `gorm:"not null;uniqueIndex:idx_follows_follower_id_followee_id;index:idx_follows_followee_id_created_at_id,priority:1"`
+	CreatedAt  time.Time `gorm:"index:idx_follows_follower_id_created_at_id,priority:2;index:idx_follows_followee_id_created_at_id,priority:2"`
+	Follower   User      `gorm:"foreignKey:FollowerID;constraint:OnDelete:CASCADE"`
+	Followee   User      `gorm:"foreignKey:FolloweeID;constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// States of a background job
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	// JobDead is final, for a job that failed every attempt or cannot succeed,
+	// kept with its last error for inspection
+	JobDead = "dead"
+)
+
+// Job represents a unit of background work queued in the database
+type Job struct {
+	ID    uint   `gorm:"primaryKey;index:idx_jobs_due,priority:3,where:status = 'pending'"`
+	Queue string `gorm:"not null;index:idx_jobs_due,priority:1,where:status = 'pending'"`
+	Type  string `gorm:"not null"`
+	// Payload is the JSON argument of the handler of the type
+	Payload     string `gorm:"not null;type:text"`
+	Status      string `gorm:"not null"`
+	Attempts    int    `gorm:"not null"`
+	MaxAttempts int    `gorm:"not null"`
+	// UniqueKey keeps a job from being queued again while one with the same key is pending or running
+	UniqueKey *string `gorm:"uniqueIndex:idx_jobs_unique_key,where:status IN ('pending'\\,'running')"`
+	// RunAt is when a pending job is due
+	RunAt time.Time `gorm:"not null;index:idx_jobs_due,priority:2,where:status = 'pending'"`
+	// LockedUntil is when a running job is given up on, should its worker have stopped
+	LockedUntil *time.Time `gorm:"index:idx_jobs_locked_until,where:status = 'running'"`
+	LastError   string     `gorm:"type:text"`
+	FinishedAt  *time.Time `gorm:"index"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// Like represents a user liking a photo, at most once per photo
+type Like struct {
+	ID        uint      `gorm:"primaryKey;index:idx_likes_photo_id_created_at_id,priority:3"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_likes_user_id_photo_id"`
+	PhotoID   uint      `gorm:"not null;uniqueIndex:idx_likes_user_id_photo_id;index:idx_likes_photo_id_created_at_id,priority:1"`
+	CreatedAt time.Time `gorm:"index:idx_likes_photo_id_created_at_id,priority:2"`
+	User      User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Photo     Photo     `gorm:"foreignKey:PhotoID;constraint:OnDelete:CASCADE"`
+}
+
+// LikeSummary represents the likes of a photo as seen by one user, it is not a table
+type LikeSummary struct {
+	PhotoID   uint
+	LikeCount int64
+	LikedByMe bool
+}
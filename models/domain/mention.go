@@ -0,0 +1,17 @@
+package domain
+
+// PhotoMention represents a user mentioned in the caption of a photo
+type PhotoMention struct {
+	PhotoID uint  `gorm:"primaryKey;autoIncrement:false"`
+	UserID  uint  `gorm:"primaryKey;autoIncrement:false;index"`
+	Photo   Photo `gorm:"foreignKey:PhotoID;constraint:OnDelete:CASCADE"`
+	User    User  `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// CommentMention represents a user mentioned in the message of a comment
+type CommentMention struct {
+	CommentID uint    `gorm:"primaryKey;autoIncrement:false"`
+	UserID    uint    `gorm:"primaryKey;autoIncrement:false;index"`
+	Comment   Comment `gorm:"foreignKey:CommentID;constraint:OnDelete:CASCADE"`
+	User      User    `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+const (
+	// NotificationTypeComment is sent to the owner of a photo someone commented on
+	NotificationTypeComment = "comment"
+	// NotificationTypeReply is sent to the author of a comment someone replied to
+	NotificationTypeReply = "reply"
+	// NotificationTypeLike is sent to the owner of a photo someone liked
+	NotificationTypeLike = "like"
+	// NotificationTypeFollow is sent to a user someone started following
+	NotificationTypeFollow = "follow"
+	// NotificationTypeMention is sent to a user mentioned in a caption or a comment
+	NotificationTypeMention = "mention"
+)
+
+// Notification represents something that happened to a user, caused by the actor
+type Notification struct {
+	ID        uint      `gorm:"primaryKey;index:idx_notifications_user_id_created_at_id,priority:3"`
+	UserID    uint      `gorm:"not null;index:idx_notifications_user_id_created_at_id,priority:1;index:idx_notifications_user_id_unread,where:read_at IS NULL"`
+	ActorID   uint      `gorm:"not null;index"`
+	Type      string    `gorm:"not null"`
+	PhotoID   *uint     `gorm:"index"`
+	CommentID *uint     `gorm:"index"`
+	CreatedAt time.Time `gorm:"index:idx_notifications_user_id_created_at_id,priority:2"`
+	// ReadAt is nil until the user marks the notification read
+	ReadAt  *time.Time
+	User    User     `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Actor   User     `gorm:"foreignKey:ActorID;constraint:OnDelete:CASCADE"`
+	Photo   *Photo   `gorm:"foreignKey:PhotoID;constraint:OnDelete:CASCADE"`
+	Comment *Comment `gorm:"foreignKey:CommentID;constraint:OnDelete:CASCADE"`
+}
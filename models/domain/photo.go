@@ -4,12 +4,35 @@ import "time"
 
 // Photo represents the model of a photo
 type Photo struct {
-	ID        uint   `gorm:"primaryKey"`
+	ID        uint   `gorm:"primaryKey;index:idx_photos_created_at_id,priority:2"`
 	Title     string `gorm:"not null"`
 	Caption   string
 	PhotoUrl  string `gorm:"not null"`
-	UserID    uint   `gorm:"not null"`
+	UserID    uint   `gorm:"not null;index"`
 	User      User   `gorm:"foreignKey:UserID"`
 	UpdatedAt time.Time
-	CreatedAt time.Time
-}
\ No newline at end of file
+	CreatedAt time.Time `gorm:"index:idx_photos_created_at_id,priority:1"`
+
+	// StorageKey is only set for uploaded photos, PhotoUrl then points at the blob
+	StorageKey  string `gorm:"not null;default:''"`
+	ContentType string `gorm:"not null;default:''"`
+	ByteSize    int64  `gorm:"not null;default:0"`
+	Width       int    `gorm:"not null;default:0"`
+	Height      int    `gorm:"not null;default:0"`
+
+	// Metadata kept from the EXIF of an upload, the rest is stripped
+	TakenAt     *time.Time
+	CameraModel string `gorm:"not null;default:''"`
+	Orientation int    `gorm:"not null;default:0"`
+	Latitude    *float64
+	Longitude   *float64
+
+	// SearchVector is generated by postgres for the full-text search, it is never read or written
+	SearchVector string `gorm:"->:false;<-:false;type:tsvector GENERATED ALWAYS AS (setweight(to_tsvector('simple', coalesce(title, '')), 'A') || setweight(to_tsvector('simple', coalesce(caption, '')), 'B')) STORED;index:idx_photos_search_vector,type:gin"`
+
+	// CommentCount is only loaded by the photo queries that select it
+	CommentCount int64 `gorm:"->;-:migration"`
+
+	Variants []PhotoVariant `gorm:"foreignKey:PhotoID;constraint:OnDelete:CASCADE"`
+	Tags     []Tag          `gorm:"many2many:photo_tags"`
+}
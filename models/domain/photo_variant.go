@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// PhotoVariant represents a resized copy of an uploaded photo, such as small or small_webp
+type PhotoVariant struct {
+	ID          uint   `gorm:"primaryKey"`
+	PhotoID     uint   `gorm:"not null;uniqueIndex:idx_photo_variants_photo_id_name"`
+	Name        string `gorm:"not null;uniqueIndex:idx_photo_variants_photo_id_name"`
+	StorageKey  string `gorm:"not null"`
+	Url         string `gorm:"not null"`
+	ContentType string `gorm:"not null"`
+	ByteSize    int64  `gorm:"not null"`
+	Width       int    `gorm:"not null"`
+	Height      int    `gorm:"not null"`
+	CreatedAt   time.Time
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// RefreshToken represents the model of a refresh token. Only the hash of the
+// token is stored, and every rotation of a login shares the same FamilyID.
+type RefreshToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	FamilyID  string    `gorm:"not null;index"`
+	TokenHash string    `gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+	User      User `gorm:"foreignKey:UserID"`
+}
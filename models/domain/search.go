@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// Markers postgres puts around the matched words of a search highlight. They
+// are private use characters so the highlight can be escaped as a whole and
+// the markers replaced afterwards, whatever the text contains.
+const (
+	HighlightStart = "\uE000"
+	HighlightStop  = "\uE001"
+)
+
+// SearchHit represents a row matching a full-text search, it is not a table
+type SearchHit struct {
+	ID        uint
+	CreatedAt time.Time
+	Rank      float64
+	Highlight string
+}
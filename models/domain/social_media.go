@@ -4,11 +4,11 @@ import "time"
 
 // SocialMedia represents the model of a social media
 type SocialMedia struct {
-	ID             uint   `gorm:"primaryKey"`
+	ID             uint   `gorm:"primaryKey;index:idx_social_media_created_at_id,priority:2"`
 	Name           string `gorm:"not null"`
 	SocialMediaUrl string `gorm:"not null;type:text"`
-	UserID         uint   `gorm:"not null"`
+	UserID         uint   `gorm:"not null;index"`
 	UpdatedAt      time.Time
-	CreatedAt      time.Time
-	User           User `gorm:"foreignKey:UserID"`
-}
\ No newline at end of file
+	CreatedAt      time.Time `gorm:"index:idx_social_media_created_at_id,priority:1"`
+	User           User      `gorm:"foreignKey:UserID"`
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// Tag represents a hashtag used in photo captions, its name is stored lowercased without the #
+type Tag struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"not null;uniqueIndex"`
+	CreatedAt time.Time
+}
+
+// PhotoTag represents the join table between photos and the tags of their caption
+type PhotoTag struct {
+	PhotoID uint  `gorm:"primaryKey;autoIncrement:false"`
+	TagID   uint  `gorm:"primaryKey;autoIncrement:false;index"`
+	Photo   Photo `gorm:"foreignKey:PhotoID;constraint:OnDelete:CASCADE"`
+	Tag     Tag   `gorm:"foreignKey:TagID;constraint:OnDelete:CASCADE"`
+}
+
+// TagCount represents a tag with the number of photos using it, it is not a table
+type TagCount struct {
+	Name       string
+	PhotoCount int64
+}
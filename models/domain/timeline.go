@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// Timeline marks a user whose feed is materialized in timeline_entries
+// instead of being queried from the photos of the followed users
+type Timeline struct {
+	UserID    uint `gorm:"primaryKey;autoIncrement:false"`
+	CreatedAt time.Time
+	User      User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// TimelineEntry represents a photo fanned out to the timeline of a user
+type TimelineEntry struct {
+	UserID  uint  `gorm:"primaryKey;autoIncrement:false"`
+	PhotoID uint  `gorm:"primaryKey;autoIncrement:false;index"`
+	User    User  `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Photo   Photo `gorm:"foreignKey:PhotoID;constraint:OnDelete:CASCADE"`
+}
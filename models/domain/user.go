@@ -8,20 +8,55 @@ import (
 	"mygram-api/helpers"
 )
 
+// Roles a user can hold, moderators and admins may act on any user's content
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
 // User represents the model of a user
 type User struct {
 	ID        uint       `gorm:"primaryKey"`
-	Username  string     `gorm:"not null;uniqueIndex"`
+	Username  string     `gorm:"not null;uniqueIndex;index:idx_users_lower_username,expression:lower(username)"`
 	Age       int        `gorm:"not null"`
 	Email     string     `gorm:"not null;uniqueIndex"`
 	Password  string     `gorm:"not null"`
+	Role      string     `gorm:"not null;default:user"`
+	BannedAt  *time.Time
 	CreatedAt time.Time
 	UpdatedAt time.Time
+
+	// EmailVerifiedAt is set once the user opens the verification link mailed
+	// to Email, and cleared whenever Email changes
+	EmailVerifiedAt *time.Time
+
+	// SearchVector is generated by postgres for the full-text search, it is never read or written
+	SearchVector string `gorm:"->:false;<-:false;type:tsvector GENERATED ALWAYS AS (to_tsvector('simple', coalesce(username, ''))) STORED;index:idx_users_search_vector,type:gin"`
 }
 
 func (user *User) BeforeCreate(db *gorm.DB) error {
 	
 	user.Password = helpers.Hash(user.Password)
 
+	if user.Role == "" {
+		user.Role = RoleUser
+	}
+
 	return nil
 }
+
+// IsValidRole reports whether role is one of the known roles
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleModerator || role == RoleAdmin
+}
+
+// IsBanned reports whether an admin banned the user
+func (user User) IsBanned() bool {
+	return user.BannedAt != nil
+}
+
+// IsEmailVerified reports whether the user proved they own their email address
+func (user User) IsEmailVerified() bool {
+	return user.EmailVerifiedAt != nil
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Events a webhook can subscribe to
+const (
+	WebhookEventPhotoCreated       = "photo.created"
+	WebhookEventPhotoUpdated       = "photo.updated"
+	WebhookEventPhotoDeleted       = "photo.deleted"
+	WebhookEventCommentCreated     = "comment.created"
+	WebhookEventCommentUpdated     = "comment.updated"
+	WebhookEventCommentDeleted     = "comment.deleted"
+	WebhookEventSocialMediaCreated = "social_media.created"
+	WebhookEventSocialMediaUpdated = "social_media.updated"
+	WebhookEventSocialMediaDeleted = "social_media.deleted"
+)
+
+// States of a webhook delivery
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	// WebhookDeliveryFailed is final, once every attempt failed
+	WebhookDeliveryFailed = "failed"
+)
+
+var webhookEvents = map[string]bool{
+	WebhookEventPhotoCreated:       true,
+	WebhookEventPhotoUpdated:       true,
+	WebhookEventPhotoDeleted:       true,
+	WebhookEventCommentCreated:     true,
+	WebhookEventCommentUpdated:     true,
+	WebhookEventCommentDeleted:     true,
+	WebhookEventSocialMediaCreated: true,
+	WebhookEventSocialMediaUpdated: true,
+	WebhookEventSocialMediaDeleted: true,
+}
+
+// Webhook represents an endpoint of a user that is sent the events of their
+// photos, comments and social media
+type Webhook struct {
+	ID     uint   `gorm:"primaryKey"`
+	UserID uint   `gorm:"not null;index"`
+	URL    string `gorm:"not null;type:text"`
+	// Secret signs the payloads so the endpoint can tell they come from us
+	Secret string `gorm:"not null"`
+	// Events is the comma separated list of the subscribed events
+	Events    string `gorm:"not null;type:text"`
+	Active    bool   `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	User      User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// EventList returns the subscribed events
+func (webhook Webhook) EventList() []string {
+	if webhook.Events == "" {
+		return []string{}
+	}
+
+	return strings.Split(webhook.Events, ",")
+}
+
+// IsValidWebhookEvent reports whether event is one a webhook can subscribe to
+func IsValidWebhookEvent(event string) bool {
+	return webhookEvents[event]
+}
+
+// JoinWebhookEvents returns events sorted and without duplicates, as stored in Webhook.Events
+func JoinWebhookEvents(events []string) string {
+	unique := make(map[string]bool, len(events))
+	joined := make([]string, 0, len(events))
+
+	for _, event := range events {
+		if !unique[event] {
+			unique[event] = true
+			joined = append(joined, event)
+		}
+	}
+
+	sort.Strings(joined)
+
+	return strings.Join(joined, ",")
+}
+
+// WebhookDelivery represents an event queued for, or sent to, a webhook
+type WebhookDelivery struct {
+	ID        uint   `gorm:"primaryKey;index:idx_webhook_deliveries_webhook_id_created_at_id,priority:3"`
+	WebhookID uint   `gorm:"not null;index:idx_webhook_deliveries_webhook_id_created_at_id,priority:1"`
+	Event     string `gorm:"not null"`
+	// Payload is the JSON body sent, the same on every attempt
+	Payload  string `gorm:"not null;type:text"`
+	Status   string `gorm:"not null"`
+	Attempts int    `gorm:"not null"`
+	// NextAttemptAt is when a pending delivery is due
+	NextAttemptAt  time.Time `gorm:"not null;index:idx_webhook_deliveries_due,where:status = 'pending'"`
+	LastAttemptAt  *time.Time
+	ResponseStatus int
+	ResponseBody   string    `gorm:"type:text"`
+	LastError      string    `gorm:"type:text"`
+	CreatedAt      time.Time `gorm:"index:idx_webhook_deliveries_webhook_id_created_at_id,priority:2"`
+	UpdatedAt      time.Time
+	Webhook        Webhook `gorm:"foreignKey:WebhookID;constraint:OnDelete:CASCADE"`
+}
@@ -4,6 +4,15 @@ package request
 type CommentCreateRequest struct {
 	Message string `binding:"required" json:"message" form:"message"`
 	PhotoID uint   `json:"photo_id" form:"photo_id"`
+	// ParentID is the comment replied to, empty for a top level comment
+	ParentID *uint `json:"parent_id" form:"parent_id"`
+}
+
+// PhotoCommentCreateRequest represents the request to comment on the photo in the path
+type PhotoCommentCreateRequest struct {
+	Message string `binding:"required" json:"message" form:"message"`
+	// ParentID is the comment replied to, empty for a top level comment
+	ParentID *uint `json:"parent_id" form:"parent_id"`
 }
 
 // CommentUpdateRequest represents the comment update request
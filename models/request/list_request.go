@@ -0,0 +1,108 @@
+package request
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	SortNewest = "-created_at"
+	SortOldest = "created_at"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListRequest represents the pagination, sorting and filter query shared by list endpoints.
+// A cursor switches from offset to keyset pagination and takes precedence over page.
+type ListRequest struct {
+	PaginationRequest
+	Cursor       string    `form:"cursor"`
+	Sort         string    `binding:"omitempty,oneof=created_at -created_at" form:"sort"`
+	UserID       uint      `form:"user_id"`
+	CreatedAfter time.Time `form:"created_after"`
+}
+
+// CommentListRequest represents the query of the comment list endpoint
+type CommentListRequest struct {
+	ListRequest
+	PhotoID uint `form:"photo_id"`
+}
+
+// CursorRequest represents the query of lists that are only paged by cursor,
+// newest first, such as the feed or the followers of a user
+type CursorRequest struct {
+	Limit  int    `binding:"omitempty,min=1,max=100" form:"limit"`
+	Cursor string `form:"cursor"`
+}
+
+// ListRequest returns the list of the first page, or the page after the cursor, without a total
+func (cursorRequest CursorRequest) ListRequest() ListRequest {
+	list := ListRequest{
+		PaginationRequest: PaginationRequest{Limit: cursorRequest.Limit},
+		Cursor:            cursorRequest.Cursor,
+		Sort:              SortNewest,
+	}
+
+	list.PaginationRequest.Normalize()
+	list.Page = 0
+
+	return list
+}
+
+// Cursor represents the position of the last row of a keyset page
+type Cursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        uint      `json:"id"`
+}
+
+// Normalize fills in the default limit and sort. The page stays zero unless
+// one is asked for without a cursor, the list is then paged by keyset.
+func (list *ListRequest) Normalize() {
+	page := list.Page
+	list.PaginationRequest.Normalize()
+
+	if list.Sort == "" {
+		list.Sort = SortNewest
+	}
+
+	if page < 1 || list.Cursor != "" {
+		list.Page = 0
+	}
+}
+
+// Descending reports whether newer rows come first
+func (list ListRequest) Descending() bool {
+	return list.Sort != SortOldest
+}
+
+// Offset returns the number of rows to skip, zero for keyset pages
+func (list ListRequest) Offset() int {
+	if list.Page < 1 {
+		return 0
+	}
+
+	return list.PaginationRequest.Offset()
+}
+
+// EncodeCursor returns the opaque cursor handed out to clients
+func EncodeCursor(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor returned by EncodeCursor
+func DecodeCursor(value string) (cursor Cursor, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+
+	if err = json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 {
+		return cursor, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
@@ -0,0 +1,100 @@
+package request
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	// postgres keeps microseconds, a cursor must not lose them or rows
+	// created within the same second would be skipped or repeated
+	cursors := []Cursor{
+		{CreatedAt: time.Date(2023, 8, 1, 12, 0, 0, 123456000, time.UTC), ID: 1},
+		{CreatedAt: time.Date(2023, 8, 1, 19, 0, 0, 1000, time.FixedZone("WIB", 7*60*60)), ID: 1<<32 + 1},
+	}
+
+	for _, cursor := range cursors {
+		encoded := EncodeCursor(cursor)
+
+		decoded, err := DecodeCursor(encoded)
+		if err != nil {
+			t.Fatalf("DecodeCursor(%q) = %v", encoded, err)
+		}
+
+		if !decoded.CreatedAt.Equal(cursor.CreatedAt) || decoded.ID != cursor.ID {
+			t.Errorf("DecodeCursor(EncodeCursor(%+v)) = %+v", cursor, decoded)
+		}
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+
+	values := []string{
+		"",
+		"not base64!",
+		base64.StdEncoding.EncodeToString([]byte(`{"created_at":"2023-08-01T12:00:00Z","id":1}`)),
+		encode("not json"),
+		encode(`{"created_at":"yesterday","id":1}`),
+		encode(`{"created_at":"2023-08-01T12:00:00Z"}`),
+		encode(`{"created_at":"2023-08-01T12:00:00Z","id":-1}`),
+	}
+
+	for _, value := range values {
+		if _, err := DecodeCursor(value); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("DecodeCursor(%q) = %v, want ErrInvalidCursor", value, err)
+		}
+	}
+}
+
+func TestListRequestNormalize(t *testing.T) {
+	tests := []struct {
+		list       ListRequest
+		wantPage   int
+		wantLimit  int
+		wantOffset int
+	}{
+		// without a page the list is paged by keyset, and not counted
+		{ListRequest{}, 0, DefaultPageLimit, 0},
+		{ListRequest{PaginationRequest: PaginationRequest{Page: 1}}, 1, DefaultPageLimit, 0},
+		{ListRequest{PaginationRequest: PaginationRequest{Page: 3, Limit: 500}}, 3, MaxPageLimit, 2 * MaxPageLimit},
+		// a cursor takes precedence over the page
+		{ListRequest{PaginationRequest: PaginationRequest{Page: 3, Limit: 10}, Cursor: "c"}, 0, 10, 0},
+	}
+
+	for _, test := range tests {
+		list := test.list
+		list.Normalize()
+
+		if list.Page != test.wantPage || list.Limit != test.wantLimit || list.Offset() != test.wantOffset || list.Sort != SortNewest {
+			t.Errorf("Normalize(%+v) = page %d, limit %d, offset %d, sort %q", test.list, list.Page, list.Limit, list.Offset(), list.Sort)
+		}
+	}
+}
+
+func TestSearchRequestNormalize(t *testing.T) {
+	tests := []struct {
+		search   SearchRequest
+		wantPage int
+	}{
+		// results sorted by rank are always paged by offset
+		{SearchRequest{}, 1},
+		{SearchRequest{Cursor: "c"}, 1},
+		{SearchRequest{Sort: SortNewest}, 0},
+		{SearchRequest{Sort: SortNewest, PaginationRequest: PaginationRequest{Page: 2}}, 2},
+		{SearchRequest{Sort: SortOldest, PaginationRequest: PaginationRequest{Page: 2}, Cursor: "c"}, 0},
+	}
+
+	for _, test := range tests {
+		search := test.search
+		search.Normalize()
+
+		if search.Page != test.wantPage {
+			t.Errorf("Normalize(%+v) = page %d, want %d", test.search, search.Page, test.wantPage)
+		}
+	}
+}
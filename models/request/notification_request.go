@@ -0,0 +1,13 @@
+package request
+
+// NotificationListRequest represents the query of the notification list, newest first
+type NotificationListRequest struct {
+	CursorRequest
+	Unread bool `form:"unread"`
+}
+
+// NotificationReadRequest represents the notifications to mark read, all of
+// them when IDs is empty
+type NotificationReadRequest struct {
+	IDs []uint `binding:"max=100" json:"ids" form:"ids"`
+}
@@ -0,0 +1,32 @@
+package request
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// PaginationRequest represents the pagination query of list endpoints
+type PaginationRequest struct {
+	Page  int `binding:"omitempty,min=1" form:"page"`
+	Limit int `binding:"omitempty,min=1,max=100" form:"limit"`
+}
+
+// Normalize fills in the first page and the default limit
+func (pagination *PaginationRequest) Normalize() {
+	if pagination.Page < 1 {
+		pagination.Page = 1
+	}
+
+	if pagination.Limit < 1 {
+		pagination.Limit = DefaultPageLimit
+	}
+
+	if pagination.Limit > MaxPageLimit {
+		pagination.Limit = MaxPageLimit
+	}
+}
+
+// Offset returns the number of rows to skip for the requested page
+func (pagination PaginationRequest) Offset() int {
+	return (pagination.Page - 1) * pagination.Limit
+}
@@ -1,5 +1,7 @@
 package request
 
+import "mime/multipart"
+
 // PhotoCreateRequest represents the photo create request
 type PhotoCreateRequest struct {
 	Title    string `binding:"required" json:"title" form:"title"`
@@ -7,6 +9,16 @@ type PhotoCreateRequest struct {
 	PhotoUrl string `binding:"required" json:"photo_url" form:"photo_url"`
 }
 
+// PhotoUploadRequest represents the multipart photo upload request
+type PhotoUploadRequest struct {
+	Title   string                `binding:"required" form:"title"`
+	Caption string                `form:"caption"`
+	Photo   *multipart.FileHeader `binding:"required" form:"photo" swaggerignore:"true"`
+
+	// ShareLocation keeps a coarse location from the EXIF of the photo
+	ShareLocation bool `form:"share_location"`
+}
+
 // PhotoUpdateRequest represents the photo update request
 type PhotoUpdateRequest struct {
 	Title    string `binding:"required" json:"title,omitempty" form:"title,omitempty"`
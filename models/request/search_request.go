@@ -0,0 +1,66 @@
+package request
+
+import "time"
+
+const (
+	SearchTypePhotos   = "photos"
+	SearchTypeComments = "comments"
+	SearchTypeUsers    = "users"
+
+	// SortRank orders search results by relevance, best first
+	SortRank = "rank"
+)
+
+// SearchRequest represents the query of the search endpoint. Results sorted by
+// rank are paged by offset, results sorted by creation time like the other lists.
+type SearchRequest struct {
+	PaginationRequest
+	Q            string    `binding:"required,max=200" form:"q"`
+	Type         string    `binding:"omitempty,oneof=photos comments users" form:"type"`
+	Cursor       string    `form:"cursor"`
+	Sort         string    `binding:"omitempty,oneof=rank created_at -created_at" form:"sort"`
+	UserID       uint      `form:"user_id"`
+	CreatedAfter time.Time `form:"created_after"`
+}
+
+// Normalize fills in the default type, limit and sort, and the page of
+// results sorted by rank
+func (search *SearchRequest) Normalize() {
+	if search.Type == "" {
+		search.Type = SearchTypePhotos
+	}
+
+	if search.Sort == "" {
+		search.Sort = SortRank
+	}
+
+	// a cursor only makes sense for results sorted by creation time
+	if search.Sort == SortRank {
+		search.Cursor = ""
+	}
+
+	page := search.Page
+	search.PaginationRequest.Normalize()
+
+	// results sorted by rank are always paged by offset, the others like a list
+	if search.Sort != SortRank && (page < 1 || search.Cursor != "") {
+		search.Page = 0
+	}
+}
+
+// ListRequest returns the pagination and filters of the search, with the
+// sort left empty when results are sorted by rank
+func (search SearchRequest) ListRequest() ListRequest {
+	list := ListRequest{
+		PaginationRequest: search.PaginationRequest,
+		Cursor:            search.Cursor,
+		UserID:            search.UserID,
+		CreatedAfter:      search.CreatedAfter,
+	}
+
+	if search.Sort != SortRank {
+		list.Sort = search.Sort
+	}
+
+	return list
+}
@@ -0,0 +1,13 @@
+package request
+
+// StreamRequest represents the photos whose new comments a stream starts subscribed to
+type StreamRequest struct {
+	PhotoIDs []uint `binding:"max=50,dive,min=1" form:"photo_id"`
+}
+
+// StreamCommand represents a message sent by a WebSocket client to follow or
+// stop following the new comments of a photo
+type StreamCommand struct {
+	Action  string `binding:"required,oneof=subscribe unsubscribe" json:"action"`
+	PhotoID uint   `binding:"required" json:"photo_id"`
+}
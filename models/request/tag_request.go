@@ -0,0 +1,17 @@
+package request
+
+// DefaultTagSearchLimit is the number of tags suggested when no limit is given
+const DefaultTagSearchLimit = 10
+
+// TagSearchRequest represents the query of the tag autocomplete
+type TagSearchRequest struct {
+	Prefix string `form:"prefix"`
+	Limit  int    `binding:"omitempty,min=1,max=100" form:"limit"`
+}
+
+// Normalize fills in the default limit
+func (search *TagSearchRequest) Normalize() {
+	if search.Limit < 1 {
+		search.Limit = DefaultTagSearchLimit
+	}
+}
@@ -13,3 +13,42 @@ type UserLoginRequest struct {
 	Email    string `binding:"required" json:"email" form:"email"`
 	Password string `binding:"required" json:"password" form:"password"`
 }
+
+// UserRefreshTokenRequest represents the user refresh and logout request
+type UserRefreshTokenRequest struct {
+	RefreshToken string `binding:"required" json:"refresh_token" form:"refresh_token"`
+}
+
+// UserUpdateRequest represents the user profile update request
+type UserUpdateRequest struct {
+	Username string `binding:"required" json:"username" form:"username"`
+	Age      int    `binding:"required,gt=8" json:"age" form:"age"`
+	Email    string `binding:"required,email" json:"email" form:"email"`
+}
+
+// UserChangePasswordRequest represents the user change password request
+type UserChangePasswordRequest struct {
+	OldPassword string `binding:"required" json:"old_password" form:"old_password"`
+	NewPassword string `binding:"required,min=6" json:"new_password" form:"new_password"`
+}
+
+// UserVerifyEmailRequest represents the email verification request
+type UserVerifyEmailRequest struct {
+	Token string `binding:"required" json:"token" form:"token"`
+}
+
+// UserEmailRequest represents the resend verification and forgot password request
+type UserEmailRequest struct {
+	Email string `binding:"required,email" json:"email" form:"email"`
+}
+
+// UserResetPasswordRequest represents the password reset request
+type UserResetPasswordRequest struct {
+	Token       string `binding:"required" json:"token" form:"token"`
+	NewPassword string `binding:"required,min=6" json:"new_password" form:"new_password"`
+}
+
+// UserSetRoleRequest represents the admin set role request
+type UserSetRoleRequest struct {
+	Role string `binding:"required,oneof=user moderator admin" json:"role" form:"role"`
+}
@@ -0,0 +1,19 @@
+package request
+
+// WebhookCreateRequest represents a webhook to register, its secret is
+// generated when left empty and it is active unless active is false
+type WebhookCreateRequest struct {
+	URL    string   `binding:"required,url,max=2048" json:"url"`
+	Secret string   `binding:"omitempty,min=16,max=256" json:"secret"`
+	Events []string `binding:"required,min=1,max=20" json:"events"`
+	Active *bool    `json:"active"`
+}
+
+// WebhookUpdateRequest represents the new settings of a webhook, its secret
+// is only replaced when set
+type WebhookUpdateRequest struct {
+	URL    string   `binding:"required,url,max=2048" json:"url"`
+	Secret string   `binding:"omitempty,min=16,max=256" json:"secret"`
+	Events []string `binding:"required,min=1,max=20" json:"events"`
+	Active *bool    `binding:"required" json:"active"`
+}
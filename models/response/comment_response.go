@@ -1,12 +1,20 @@
 package response
 
-import "time"
+import (
+	"time"
+
+	"mygram-api/models/domain"
+)
+
+// DeletedCommentMessage replaces the message of a deleted comment kept for its replies
+const DeletedCommentMessage = "[deleted]"
 
 // CommentCreateResponse represents the comment create response
 type CommentCreateResponse struct {
 	ID        uint      `json:"id"`
 	Message   string    `json:"message"`
 	PhotoID   uint      `json:"photo_id"`
+	ParentID  *uint     `json:"parent_id"`
 	UserID    uint      `json:"user_id"`
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -28,10 +36,12 @@ type CommentGetAllResponse struct {
 	ID        uint                       `json:"id"`
 	Message   string                     `json:"message"`
 	PhotoID   uint                       `json:"photo_id"`
-	UserID    uint                       `json:"user_id"`
+	ParentID  *uint                      `json:"parent_id"`
+	Deleted   bool                       `json:"deleted"`
+	UserID    uint                       `json:"user_id,omitempty"`
 	CreatedAt time.Time                  `json:"created_at"`
 	UpdatedAt time.Time                  `json:"updated_at"`
-	User      CommentUserGetAllResponse  `json:"user"`
+	User      *CommentUserGetAllResponse `json:"user,omitempty"`
 	Photo     CommentPhotoGetAllResponse `json:"photo"`
 }
 
@@ -60,3 +70,80 @@ type CommentUpdateResponse struct {
 type CommentDeleteResponse struct {
 	Message string `json:"message"`
 }
+
+// CommentThreadResponse represents a comment of a photo with its replies
+type CommentThreadResponse struct {
+	ID         uint                       `json:"id"`
+	Message    string                     `json:"message"`
+	ParentID   *uint                      `json:"parent_id"`
+	Depth      int                        `json:"depth"`
+	Deleted    bool                       `json:"deleted"`
+	UserID     uint                       `json:"user_id,omitempty"`
+	User       *CommentUserGetAllResponse `json:"user,omitempty"`
+	ReplyCount int64                      `json:"reply_count"`
+	Replies    []CommentThreadResponse    `json:"replies"`
+	CreatedAt  time.Time                  `json:"created_at"`
+	UpdatedAt  time.Time                  `json:"updated_at"`
+}
+
+// NewCommentMessage returns the message of a comment, or the placeholder of a deleted one
+func NewCommentMessage(comment domain.Comment) string {
+	if comment.DeletedAt != nil {
+		return DeletedCommentMessage
+	}
+
+	return comment.Message
+}
+
+// NewCommentGetAllResponse builds the response of a comment loaded with its
+// author and photo, hiding the author of a deleted comment
+func NewCommentGetAllResponse(comment domain.Comment) CommentGetAllResponse {
+	commentResponse := CommentGetAllResponse{
+		ID:       comment.ID,
+		Message:  NewCommentMessage(comment),
+		PhotoID:  comment.PhotoID,
+		ParentID: comment.ParentID,
+		Deleted:  comment.DeletedAt != nil,
+		Photo: CommentPhotoGetAllResponse{
+			Title:    comment.Photo.Title,
+			Caption:  comment.Photo.Caption,
+			PhotoUrl: comment.Photo.PhotoUrl,
+		},
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	}
+
+	if !commentResponse.Deleted {
+		commentResponse.UserID = comment.UserID
+		commentResponse.User = &CommentUserGetAllResponse{Username: comment.User.Username}
+	}
+
+	return commentResponse
+}
+
+// NewCommentThreadResponse builds the response of a comment and its loaded
+// replies, hiding the author of a deleted comment
+func NewCommentThreadResponse(comment domain.Comment, replyCounts map[uint]int64) CommentThreadResponse {
+	thread := CommentThreadResponse{
+		ID:         comment.ID,
+		Message:    NewCommentMessage(comment),
+		ParentID:   comment.ParentID,
+		Depth:      comment.Depth,
+		Deleted:    comment.DeletedAt != nil,
+		ReplyCount: replyCounts[comment.ID],
+		Replies:    make([]CommentThreadResponse, 0, len(comment.Replies)),
+		CreatedAt:  comment.CreatedAt,
+		UpdatedAt:  comment.UpdatedAt,
+	}
+
+	if !thread.Deleted {
+		thread.UserID = comment.UserID
+		thread.User = &CommentUserGetAllResponse{Username: comment.User.Username}
+	}
+
+	for _, reply := range comment.Replies {
+		thread.Replies = append(thread.Replies, NewCommentThreadResponse(reply, replyCounts))
+	}
+
+	return thread
+}
@@ -0,0 +1,62 @@
+package response
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"mygram-api/models/domain"
+)
+
+func TestDeletedCommentsHideTheirAuthor(t *testing.T) {
+	deletedAt := time.Now()
+
+	comment := domain.Comment{
+		ID:        1,
+		UserID:    7,
+		PhotoID:   3,
+		Message:   "",
+		DeletedAt: &deletedAt,
+		User:      domain.User{ID: 7, Username: "budi"},
+		Replies:   []domain.Comment{{ID: 2, UserID: 8, PhotoID: 3, Message: "reply", User: domain.User{ID: 8, Username: "sari"}}},
+	}
+
+	responses := map[string]interface{}{
+		"get all": NewCommentGetAllResponse(comment),
+		"search":  SearchCommentResponse{CommentGetAllResponse: NewCommentGetAllResponse(comment)},
+		"thread":  NewCommentThreadResponse(comment, nil),
+	}
+
+	for name, commentResponse := range responses {
+		data, err := json.Marshal(commentResponse)
+		if err != nil {
+			t.Fatalf("%s: encoding: %v", name, err)
+		}
+
+		body := string(data)
+
+		if !strings.Contains(body, `"message":"[deleted]"`) || !strings.Contains(body, `"deleted":true`) {
+			t.Errorf("%s: %s is not a placeholder", name, body)
+		}
+
+		if strings.Contains(body, "budi") || strings.Contains(body, `"user_id":7`) {
+			t.Errorf("%s: %s reveals the author of the deleted comment", name, body)
+		}
+	}
+
+	// the replies keep theirs
+	if thread := NewCommentThreadResponse(comment, nil); thread.Replies[0].User == nil || thread.Replies[0].User.Username != "sari" {
+		t.Errorf("reply lost its author: %+v", thread.Replies[0])
+	}
+}
+
+func TestCommentsShowTheirAuthor(t *testing.T) {
+	comment := domain.Comment{ID: 1, UserID: 7, PhotoID: 3, Message: "hi", User: domain.User{ID: 7, Username: "budi"}}
+
+	commentResponse := NewCommentGetAllResponse(comment)
+
+	if commentResponse.Deleted || commentResponse.Message != "hi" || commentResponse.UserID != 7 || commentResponse.User == nil || commentResponse.User.Username != "budi" {
+		t.Errorf("got %+v, want the comment with its author", commentResponse)
+	}
+}
@@ -0,0 +1,17 @@
+package response
+
+import "time"
+
+// FollowResponse represents the followed user after following or unfollowing it
+type FollowResponse struct {
+	UserID    uint   `json:"user_id"`
+	Username  string `json:"username"`
+	Following bool   `json:"following"`
+}
+
+// FollowGetAllResponse represents a user of the followers or following list
+type FollowGetAllResponse struct {
+	ID         uint      `json:"id"`
+	Username   string    `json:"username"`
+	FollowedAt time.Time `json:"followed_at"`
+}
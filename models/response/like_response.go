@@ -0,0 +1,36 @@
+package response
+
+import (
+	"time"
+
+	"mygram-api/models/domain"
+)
+
+// LikeResponse represents the likes of a photo after liking or unliking it
+type LikeResponse struct {
+	PhotoID   uint  `json:"photo_id"`
+	LikeCount int64 `json:"like_count"`
+	LikedByMe bool  `json:"liked_by_me"`
+}
+
+// NewLikeResponse returns the like response of a photo summary
+func NewLikeResponse(summary domain.LikeSummary) LikeResponse {
+	return LikeResponse{
+		PhotoID:   summary.PhotoID,
+		LikeCount: summary.LikeCount,
+		LikedByMe: summary.LikedByMe,
+	}
+}
+
+// LikeUserGetAllResponse represents the user of the like get all response
+type LikeUserGetAllResponse struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
+// LikeGetAllResponse represents the like get all response
+type LikeGetAllResponse struct {
+	ID        uint                   `json:"id"`
+	User      LikeUserGetAllResponse `json:"user"`
+	CreatedAt time.Time              `json:"created_at"`
+}
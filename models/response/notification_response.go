@@ -0,0 +1,51 @@
+package response
+
+import (
+	"time"
+
+	"mygram-api/models/domain"
+)
+
+// NotificationActorResponse represents the user who caused a notification
+type NotificationActorResponse struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
+// NotificationResponse represents a notification of the authentication user
+type NotificationResponse struct {
+	ID        uint                      `json:"id"`
+	Type      string                    `json:"type"`
+	Actor     NotificationActorResponse `json:"actor"`
+	PhotoID   *uint                     `json:"photo_id,omitempty"`
+	CommentID *uint                     `json:"comment_id,omitempty"`
+	Read      bool                      `json:"read"`
+	CreatedAt time.Time                 `json:"created_at"`
+}
+
+// NewNotificationResponse returns the response of a notification loaded with its actor
+func NewNotificationResponse(notification domain.Notification) NotificationResponse {
+	return NotificationResponse{
+		ID:   notification.ID,
+		Type: notification.Type,
+		Actor: NotificationActorResponse{
+			ID:       notification.Actor.ID,
+			Username: notification.Actor.Username,
+		},
+		PhotoID:   notification.PhotoID,
+		CommentID: notification.CommentID,
+		Read:      notification.ReadAt != nil,
+		CreatedAt: notification.CreatedAt,
+	}
+}
+
+// NotificationUnreadResponse represents the number of unread notifications
+type NotificationUnreadResponse struct {
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// NotificationReadResponse represents the result of marking notifications read
+type NotificationReadResponse struct {
+	Updated     int64 `json:"updated"`
+	UnreadCount int64 `json:"unread_count"`
+}
@@ -0,0 +1,41 @@
+package response
+
+import "mygram-api/models/request"
+
+// PaginationResponse represents the pagination metadata of a list response.
+// Keyset pages carry no page number or total, only the cursor of the next page.
+type PaginationResponse struct {
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	Total      *int64 `json:"total,omitempty"`
+	TotalPages *int64 `json:"total_pages,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// NewPaginationResponse computes the page count for total rows
+func NewPaginationResponse(page, limit int, total int64) *PaginationResponse {
+	totalPages := int64(0)
+	if limit > 0 {
+		totalPages = (total + int64(limit) - 1) / int64(limit)
+	}
+
+	return &PaginationResponse{
+		Page:       page,
+		Limit:      limit,
+		Total:      &total,
+		TotalPages: &totalPages,
+	}
+}
+
+// NewListPaginationResponse describes a page of a list endpoint, total is nil for keyset pages
+func NewListPaginationResponse(list request.ListRequest, total *int64, nextCursor string) *PaginationResponse {
+	pagination := &PaginationResponse{Limit: list.Limit}
+
+	if total != nil {
+		pagination = NewPaginationResponse(list.Page, list.Limit, *total)
+	}
+
+	pagination.NextCursor = nextCursor
+
+	return pagination
+}
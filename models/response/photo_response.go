@@ -2,6 +2,8 @@ package response
 
 import (
 	"time"
+
+	"mygram-api/models/domain"
 )
 
 // PhotoCreateResponse represents the photo create response
@@ -12,6 +14,12 @@ type PhotoCreateResponse struct {
 	PhotoUrl  string    `json:"photo_url"`
 	UserID    uint      `json:"user_id"`
 	CreatedAt time.Time `json:"created_at"`
+	Tags      []string  `json:"tags"`
+
+	ContentType string `json:"content_type,omitempty"`
+	ByteSize    int64  `json:"byte_size,omitempty"`
+	Width       int    `json:"width,omitempty"`
+	Height      int    `json:"height,omitempty"`
 }
 
 // PhotoUserGetAllReponse represents the photo user get all response
@@ -29,6 +37,19 @@ type PhotoGetAllResponse struct {
 	CreatedAt time.Time              `json:"created_at"`
 	UpdatedAt time.Time              `json:"updated_at"`
 	User      PhotoUserGetAllReponse `json:"user"`
+	Tags      []string               `json:"tags"`
+
+	ContentType string `json:"content_type,omitempty"`
+	ByteSize    int64  `json:"byte_size,omitempty"`
+	Width       int    `json:"width,omitempty"`
+	Height      int    `json:"height,omitempty"`
+
+	Variants map[string]PhotoVariantResponse `json:"variants,omitempty"`
+
+	LikeCount int64 `json:"like_count"`
+	LikedByMe bool  `json:"liked_by_me"`
+
+	CommentCount int64 `json:"comment_count"`
 }
 
 // PhotoGetOneResponse represents the photo get one response
@@ -41,6 +62,64 @@ type PhotoGetOneResponse struct {
 	CreatedAt time.Time              `json:"created_at"`
 	UpdatedAt time.Time              `json:"updated_at"`
 	User      PhotoUserGetAllReponse `json:"user"`
+	Tags      []string               `json:"tags"`
+
+	ContentType string `json:"content_type,omitempty"`
+	ByteSize    int64  `json:"byte_size,omitempty"`
+	Width       int    `json:"width,omitempty"`
+	Height      int    `json:"height,omitempty"`
+
+	TakenAt     *time.Time             `json:"taken_at,omitempty"`
+	CameraModel string                 `json:"camera_model,omitempty"`
+	Orientation int                    `json:"orientation,omitempty"`
+	Location    *PhotoLocationResponse `json:"location,omitempty"`
+
+	Variants map[string]PhotoVariantResponse `json:"variants,omitempty"`
+
+	LikeCount int64 `json:"like_count"`
+	LikedByMe bool  `json:"liked_by_me"`
+
+	CommentCount int64 `json:"comment_count"`
+}
+
+// PhotoLocationResponse represents the coarse location a photo was taken at
+type PhotoLocationResponse struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// NewPhotoLocationResponse returns nil unless the owner shared the location of the photo
+func NewPhotoLocationResponse(photo domain.Photo) *PhotoLocationResponse {
+	if photo.Latitude == nil || photo.Longitude == nil {
+		return nil
+	}
+
+	return &PhotoLocationResponse{Latitude: *photo.Latitude, Longitude: *photo.Longitude}
+}
+
+// NewPhotoGetAllResponse returns the list item of a photo with its likes as seen by the requesting user
+func NewPhotoGetAllResponse(photo domain.Photo, likes domain.LikeSummary) PhotoGetAllResponse {
+	return PhotoGetAllResponse{
+		ID:        photo.ID,
+		Title:     photo.Title,
+		Caption:   photo.Caption,
+		PhotoUrl:  photo.PhotoUrl,
+		UserID:    photo.UserID,
+		CreatedAt: photo.CreatedAt,
+		UpdatedAt: photo.UpdatedAt,
+		User: PhotoUserGetAllReponse{
+			Username: photo.User.Username,
+		},
+		Tags:         NewPhotoTagsResponse(photo.Tags),
+		ContentType:  photo.ContentType,
+		ByteSize:     photo.ByteSize,
+		Width:        photo.Width,
+		Height:       photo.Height,
+		Variants:     NewPhotoVariantsResponse(photo.Variants),
+		LikeCount:    likes.LikeCount,
+		LikedByMe:    likes.LikedByMe,
+		CommentCount: photo.CommentCount,
+	}
 }
 
 // PhotoUpdateResponse represents the photo update response
@@ -51,9 +130,49 @@ type PhotoUpdateResponse struct {
 	PhotoUrl  string    `json:"photo_url"`
 	UserID    uint      `json:"user_id"`
 	UpdatedAt time.Time `json:"updated_at"`
+	Tags      []string  `json:"tags"`
 }
 
 // PhotoDeleteResponse represents the photo delete response
 type PhotoDeleteResponse struct {
 	Message string `json:"message"`
-}
\ No newline at end of file
+}
+
+// PhotoVariantResponse represents a resized copy of an uploaded photo
+type PhotoVariantResponse struct {
+	Url         string `json:"url"`
+	ContentType string `json:"content_type"`
+	ByteSize    int64  `json:"byte_size"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+}
+
+// NewPhotoTagsResponse lists the tag names of a photo, empty rather than null without tags
+func NewPhotoTagsResponse(tags []domain.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+
+	return names
+}
+
+// NewPhotoVariantsResponse keys the variants of a photo by name, such as small or small_webp
+func NewPhotoVariantsResponse(variants []domain.PhotoVariant) map[string]PhotoVariantResponse {
+	if len(variants) == 0 {
+		return nil
+	}
+
+	variantsResponse := make(map[string]PhotoVariantResponse, len(variants))
+	for _, variant := range variants {
+		variantsResponse[variant.Name] = PhotoVariantResponse{
+			Url:         variant.Url,
+			ContentType: variant.ContentType,
+			ByteSize:    variant.ByteSize,
+			Width:       variant.Width,
+			Height:      variant.Height,
+		}
+	}
+
+	return variantsResponse
+}
@@ -0,0 +1,41 @@
+package response
+
+import (
+	"html"
+	"strings"
+	"time"
+
+	"mygram-api/models/domain"
+)
+
+// SearchPhotoResponse represents a photo matching a search
+type SearchPhotoResponse struct {
+	PhotoGetAllResponse
+	Rank      float64 `json:"rank,omitempty"`
+	Highlight string  `json:"highlight"`
+}
+
+// SearchCommentResponse represents a comment matching a search
+type SearchCommentResponse struct {
+	CommentGetAllResponse
+	Rank      float64 `json:"rank,omitempty"`
+	Highlight string  `json:"highlight"`
+}
+
+// SearchUserResponse represents a user matching a search
+type SearchUserResponse struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+	Rank      float64   `json:"rank,omitempty"`
+	Highlight string    `json:"highlight"`
+}
+
+// NewSearchHighlight escapes the matched text for HTML and wraps the matched
+// words in <mark>, so clients can render it as is
+func NewSearchHighlight(headline string) string {
+	return strings.NewReplacer(
+		domain.HighlightStart, "<mark>",
+		domain.HighlightStop, "</mark>",
+	).Replace(html.EscapeString(headline))
+}
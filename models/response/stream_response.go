@@ -0,0 +1,6 @@
+package response
+
+// StreamSubscriptionResponse acknowledges a subscribe or unsubscribe command of a WebSocket client
+type StreamSubscriptionResponse struct {
+	PhotoID uint `json:"photo_id"`
+}
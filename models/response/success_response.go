@@ -2,5 +2,6 @@ package response
 
 // SuccessResponse represents the success response
 type SuccessResponse struct {
-	Data   interface{} `json:"data"`
-}
\ No newline at end of file
+	Data       interface{}         `json:"data"`
+	Pagination *PaginationResponse `json:"pagination,omitempty"`
+}
@@ -0,0 +1,7 @@
+package response
+
+// TagGetAllResponse represents a tag suggested by the autocomplete
+type TagGetAllResponse struct {
+	Name       string `json:"name"`
+	PhotoCount int64  `json:"photo_count"`
+}
@@ -1,5 +1,7 @@
 package response
 
+import "time"
+
 // UserRegisterResponse represents the user register response
 type UserRegisterResponse struct {
 	ID       uint   `json:"id"`
@@ -8,7 +10,56 @@ type UserRegisterResponse struct {
 	Age      int    `json:"age"`
 }
 
-// UserLoginResponse represents the user login response
+// UserLoginResponse represents the user login and refresh response
 type UserLoginResponse struct {
-	Token string `json:"token"`
+	Token                 string    `json:"token"`
+	ExpiresAt             time.Time `json:"expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+// UserProfileResponse represents the user profile response
+type UserProfileResponse struct {
+	ID            uint      `json:"id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	Age           int       `json:"age"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// UserMessageResponse represents the user logout, password change, delete, verification and password reset response
+type UserMessageResponse struct {
+	Message string `json:"message"`
+}
+
+
+// UserSocialMediaResponse represents the social media of a public profile
+type UserSocialMediaResponse struct {
+	ID             uint   `json:"id"`
+	Name           string `json:"name"`
+	SocialMediaUrl string `json:"social_media_url"`
+}
+
+// UserPublicProfileResponse represents the public profile response
+type UserPublicProfileResponse struct {
+	ID                    uint                      `json:"id"`
+	Username              string                    `json:"username"`
+	CreatedAt             time.Time                 `json:"created_at"`
+	PhotoCount            int64                     `json:"photo_count"`
+	CommentsReceivedCount int64                     `json:"comments_received_count"`
+	FollowerCount         int64                     `json:"follower_count"`
+	FollowingCount        int64                     `json:"following_count"`
+	SocialMedias          []UserSocialMediaResponse `json:"social_medias"`
+}
+
+// UserAdminResponse represents the user as seen by admins and moderators
+type UserAdminResponse struct {
+	ID        uint       `json:"id"`
+	Username  string     `json:"username"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	BannedAt  *time.Time `json:"banned_at"`
+	CreatedAt time.Time  `json:"created_at"`
 }
@@ -0,0 +1,147 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+
+	"mygram-api/models/domain"
+)
+
+// WebhookResponse represents a webhook of the authentication user, without its secret
+type WebhookResponse struct {
+	ID        uint      `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookCreateResponse represents a new webhook, the only time its secret is returned
+type WebhookCreateResponse struct {
+	WebhookResponse
+	Secret string `json:"secret"`
+}
+
+func NewWebhookResponse(webhook domain.Webhook) WebhookResponse {
+	return WebhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    webhook.EventList(),
+		Active:    webhook.Active,
+		CreatedAt: webhook.CreatedAt,
+		UpdatedAt: webhook.UpdatedAt,
+	}
+}
+
+// WebhookDeliveryResponse represents an event queued for, or sent to, a webhook
+type WebhookDeliveryResponse struct {
+	ID             uint            `json:"id"`
+	Event          string          `json:"event"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	ResponseBody   string          `json:"response_body,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func NewWebhookDeliveryResponse(delivery domain.WebhookDelivery) WebhookDeliveryResponse {
+	deliveryResponse := WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		Event:          delivery.Event,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastAttemptAt:  delivery.LastAttemptAt,
+		ResponseStatus: delivery.ResponseStatus,
+		ResponseBody:   delivery.ResponseBody,
+		LastError:      delivery.LastError,
+		Payload:        json.RawMessage(delivery.Payload),
+		CreatedAt:      delivery.CreatedAt,
+	}
+
+	if delivery.Status == domain.WebhookDeliveryPending {
+		deliveryResponse.NextAttemptAt = &delivery.NextAttemptAt
+	}
+
+	return deliveryResponse
+}
+
+// WebhookPayload represents the body posted to a webhook
+type WebhookPayload struct {
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookPhotoData represents the photo of a photo event
+type WebhookPhotoData struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	Title     string    `json:"title"`
+	Caption   string    `json:"caption"`
+	PhotoUrl  string    `json:"photo_url"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewWebhookPhotoData(photo domain.Photo) WebhookPhotoData {
+	return WebhookPhotoData{
+		ID:        photo.ID,
+		UserID:    photo.UserID,
+		Title:     photo.Title,
+		Caption:   photo.Caption,
+		PhotoUrl:  photo.PhotoUrl,
+		Tags:      NewPhotoTagsResponse(photo.Tags),
+		CreatedAt: photo.CreatedAt,
+		UpdatedAt: photo.UpdatedAt,
+	}
+}
+
+// WebhookCommentData represents the comment of a comment event
+type WebhookCommentData struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	PhotoID   uint      `json:"photo_id"`
+	ParentID  *uint     `json:"parent_id"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewWebhookCommentData(comment domain.Comment) WebhookCommentData {
+	return WebhookCommentData{
+		ID:        comment.ID,
+		UserID:    comment.UserID,
+		PhotoID:   comment.PhotoID,
+		ParentID:  comment.ParentID,
+		Message:   NewCommentMessage(comment),
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	}
+}
+
+// WebhookSocialMediaData represents the social media of a social media event
+type WebhookSocialMediaData struct {
+	ID             uint      `json:"id"`
+	UserID         uint      `json:"user_id"`
+	Name           string    `json:"name"`
+	SocialMediaUrl string    `json:"social_media_url"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func NewWebhookSocialMediaData(socialMedia domain.SocialMedia) WebhookSocialMediaData {
+	return WebhookSocialMediaData{
+		ID:             socialMedia.ID,
+		UserID:         socialMedia.UserID,
+		Name:           socialMedia.Name,
+		SocialMediaUrl: socialMedia.SocialMediaUrl,
+		CreatedAt:      socialMedia.CreatedAt,
+		UpdatedAt:      socialMedia.UpdatedAt,
+	}
+}
@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"mygram-api/auth"
+	"mygram-api/helpers"
+	"mygram-api/models/request"
+	"mygram-api/models/response"
+	"mygram-api/notifications/service"
+)
+
+type NotificationController interface {
+	GetAll(c *gin.Context)
+	GetUnreadCount(c *gin.Context)
+	MarkRead(c *gin.Context)
+}
+
+type NotificationControllerService struct {
+	NotificationService service.NotificationService
+}
+
+func NewNotificationController(notificationService service.NotificationService) NotificationController {
+	return &NotificationControllerService{NotificationService: notificationService}
+}
+
+// GetAll notification godoc
+// @Summary Get the notifications
+// @Description Get a page of the notifications of the authentication user, newest first.
+// @Description Types are comment, reply, like, follow and mention.
+// @Tags notifications
+// @Security Bearer
+// @Produce json
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "Cursor of the next page from a previous response"
+// @Param unread query bool false "Only unread notifications"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /notifications [get]
+func (notificationController *NotificationControllerService) GetAll(c *gin.Context) {
+
+	var list request.NotificationListRequest
+
+	if err := c.ShouldBindQuery(&list); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	notifications, page, err := notificationController.NotificationService.GetAll(auth.MustGetPrincipal(c).UserID, list.Unread, list.ListRequest())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	notificationsResponse := []response.NotificationResponse{}
+	for _, notification := range notifications {
+		notificationsResponse = append(notificationsResponse, response.NewNotificationResponse(notification))
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data:       notificationsResponse,
+		Pagination: response.NewListPaginationResponse(list.ListRequest(), page.Total, page.NextCursor),
+	})
+}
+
+// GetUnreadCount notification godoc
+// @Summary Count the unread notifications
+// @Description Get the number of unread notifications of the authentication user
+// @Tags notifications
+// @Security Bearer
+// @Produce json
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /notifications/unread-count [get]
+func (notificationController *NotificationControllerService) GetUnreadCount(c *gin.Context) {
+
+	count, err := notificationController.NotificationService.CountUnread(auth.MustGetPrincipal(c).UserID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.NotificationUnreadResponse{
+			UnreadCount: count,
+		},
+	})
+}
+
+// MarkRead notification godoc
+// @Summary Mark notifications read
+// @Description Mark the given notifications of the authentication user read, or all of them when ids is empty
+// @Tags notifications
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param json body request.NotificationReadRequest false "Notifications to mark read"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /notifications/read [post]
+func (notificationController *NotificationControllerService) MarkRead(c *gin.Context) {
+
+	var req request.NotificationReadRequest
+
+	// an empty body marks every notification read
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBind(&req); err != nil {
+			abortWithBindError(c, err)
+			return
+		}
+	}
+
+	userID := auth.MustGetPrincipal(c).UserID
+
+	updated, err := notificationController.NotificationService.MarkRead(userID, req.IDs)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	count, err := notificationController.NotificationService.CountUnread(userID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.NotificationReadResponse{
+			Updated:     updated,
+			UnreadCount: count,
+		},
+	})
+}
+
+func abortWithBindError(c *gin.Context, err error) {
+
+	validationError, ok := err.(validator.ValidationErrors)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	fieldErrorResponse := make(map[string]interface{})
+
+	for _, v := range validationError {
+		fieldErrorResponse[strings.ToLower(v.Field())] = helpers.GetValidationErrorMsg(v)
+	}
+
+	c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+		Code:   http.StatusBadRequest,
+		Status: "Bad Request",
+		Errors: fieldErrorResponse,
+	})
+}
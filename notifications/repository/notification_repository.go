@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"mygram-api/database"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+)
+
+type NotificationRepository interface {
+	Create(notifications []domain.Notification) (err error)
+	GetByIDs(ids []uint) (notifications []domain.Notification, err error)
+	GetAll(userID uint, unread bool, list request.ListRequest) (notifications []domain.Notification, page database.Page, err error)
+	CountUnread(userID uint) (count int64, err error)
+	MarkRead(userID uint, ids []uint) (updated int64, err error)
+	Withdraw(match domain.Notification) (err error)
+	DeleteMentions(userIDs []uint, photoID uint, commentID *uint) (err error)
+}
+
+type NotificationRepositoryDB struct {
+	DB *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &NotificationRepositoryDB{DB: db}
+}
+
+func (notificationRepository *NotificationRepositoryDB) Create(notifications []domain.Notification) (err error) {
+
+	if len(notifications) == 0 {
+		return
+	}
+
+	if err = notificationRepository.DB.Create(&notifications).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// GetByIDs loads the notifications with the given ids and their actor, in no particular order
+func (notificationRepository *NotificationRepositoryDB) GetByIDs(ids []uint) (notifications []domain.Notification, err error) {
+
+	if err = notificationRepository.DB.Preload("Actor", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "username")
+	}).Find(&notifications, ids).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// GetAll lists the notifications of userID with their actor, only the unread ones when unread is set
+func (notificationRepository *NotificationRepositoryDB) GetAll(userID uint, unread bool, list request.ListRequest) (notifications []domain.Notification, page database.Page, err error) {
+
+	query := notificationRepository.DB.Model(&domain.Notification{}).Preload("Actor", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "username")
+	}).Where("user_id = ?", userID)
+
+	if unread {
+		query = query.Where("read_at IS NULL")
+	}
+
+	// the recipient is the user_id, it is not a filter here
+	list.UserID = 0
+
+	return database.Paginate(query, list, func(notification domain.Notification) request.Cursor {
+		return request.Cursor{CreatedAt: notification.CreatedAt, ID: notification.ID}
+	})
+}
+
+func (notificationRepository *NotificationRepositoryDB) CountUnread(userID uint) (count int64, err error) {
+
+	if err = notificationRepository.DB.Model(&domain.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// MarkRead marks the unread notifications of userID with the given ids read,
+// or all of them when ids is empty, and returns how many were marked
+func (notificationRepository *NotificationRepositoryDB) MarkRead(userID uint, ids []uint) (updated int64, err error) {
+
+	query := notificationRepository.DB.Model(&domain.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)
+
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+
+	result := query.Update("read_at", time.Now())
+	if err = result.Error; err != nil {
+		return
+	}
+
+	return result.RowsAffected, nil
+}
+
+// Withdraw deletes the notifications matching the non-zero fields of match,
+// such as the like notification of an unliked photo
+func (notificationRepository *NotificationRepositoryDB) Withdraw(match domain.Notification) (err error) {
+
+	if err = notificationRepository.DB.Where(&match).Delete(&domain.Notification{}).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// DeleteMentions withdraws the mention notifications of userIDs about a photo
+// caption, or about a comment when commentID is set
+func (notificationRepository *NotificationRepositoryDB) DeleteMentions(userIDs []uint, photoID uint, commentID *uint) (err error) {
+
+	query := notificationRepository.DB.Where("type = ? AND user_id IN ? AND photo_id = ?", domain.NotificationTypeMention, userIDs, photoID)
+
+	if commentID != nil {
+		query = query.Where("comment_id = ?", *commentID)
+	} else {
+		query = query.Where("comment_id IS NULL")
+	}
+
+	if err = query.Delete(&domain.Notification{}).Error; err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"log"
+
+	commentRepository "mygram-api/comments/repository"
+	"mygram-api/database"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+	"mygram-api/notifications/repository"
+	photoRepository "mygram-api/photos/repository"
+)
+
+// NotificationService stores the notifications of a user. The comment, like
+// and follow events are best-effort: a notification that cannot be stored is
+// logged and does not fail the action that caused it.
+type NotificationService interface {
+	GetAll(userID uint, unread bool, list request.ListRequest) (notifications []domain.Notification, page database.Page, err error)
+	CountUnread(userID uint) (count int64, err error)
+	MarkRead(userID uint, ids []uint) (updated int64, err error)
+
+	Commented(comment domain.Comment)
+	Liked(userID, photoID uint)
+	Unliked(userID, photoID uint)
+	Followed(followerID, followeeID uint)
+	Unfollowed(followerID, followeeID uint)
+	Mentioned(actorID uint, userIDs []uint, photoID uint, commentID *uint)
+	Unmentioned(userIDs []uint, photoID uint, commentID *uint)
+}
+
+// NotificationPublisher pushes the new notifications, loaded with their
+// actor, to the connected clients of their users
+type NotificationPublisher interface {
+	NotificationsCreated(notifications []domain.Notification)
+}
+
+type NotificationServiceRepository struct {
+	NotificationRepository repository.NotificationRepository
+	PhotoRepository        photoRepository.PhotoRepository
+	CommentRepository      commentRepository.CommentRepository
+	NotificationPublisher  NotificationPublisher
+}
+
+func NewNotificationService(notificationRepository repository.NotificationRepository, photoRepository photoRepository.PhotoRepository, commentRepository commentRepository.CommentRepository, notificationPublisher NotificationPublisher) NotificationService {
+	return &NotificationServiceRepository{NotificationRepository: notificationRepository, PhotoRepository: photoRepository, CommentRepository: commentRepository, NotificationPublisher: notificationPublisher}
+}
+
+func (notificationService *NotificationServiceRepository) GetAll(userID uint, unread bool, list request.ListRequest) (notifications []domain.Notification, page database.Page, err error) {
+
+	if notifications, page, err = notificationService.NotificationRepository.GetAll(userID, unread, list); err != nil {
+		return
+	}
+
+	return
+}
+
+func (notificationService *NotificationServiceRepository) CountUnread(userID uint) (count int64, err error) {
+
+	if count, err = notificationService.NotificationRepository.CountUnread(userID); err != nil {
+		return
+	}
+
+	return
+}
+
+// MarkRead marks the given notifications of userID read, all of them when ids is empty
+func (notificationService *NotificationServiceRepository) MarkRead(userID uint, ids []uint) (updated int64, err error) {
+
+	if updated, err = notificationService.NotificationRepository.MarkRead(userID, ids); err != nil {
+		return
+	}
+
+	return
+}
+
+// Commented notifies the author of the comment replied to, if any, and the
+// owner of the photo, each once and never the author of the new comment
+func (notificationService *NotificationServiceRepository) Commented(comment domain.Comment) {
+
+	var notifications []domain.Notification
+	notified := map[uint]bool{comment.UserID: true}
+
+	if comment.ParentID != nil {
+		parent, err := notificationService.CommentRepository.GetOne(*comment.ParentID)
+		if err != nil {
+			log.Printf("notifying reply %d: %v", comment.ID, err)
+			return
+		}
+
+		if !notified[parent.UserID] {
+			notified[parent.UserID] = true
+			notifications = append(notifications, domain.Notification{
+				UserID:    parent.UserID,
+				ActorID:   comment.UserID,
+				Type:      domain.NotificationTypeReply,
+				PhotoID:   &comment.PhotoID,
+				CommentID: &comment.ID,
+			})
+		}
+	}
+
+	photo, err := notificationService.PhotoRepository.GetOne(comment.PhotoID)
+	if err != nil {
+		log.Printf("notifying comment %d: %v", comment.ID, err)
+		return
+	}
+
+	if !notified[photo.UserID] {
+		notifications = append(notifications, domain.Notification{
+			UserID:    photo.UserID,
+			ActorID:   comment.UserID,
+			Type:      domain.NotificationTypeComment,
+			PhotoID:   &comment.PhotoID,
+			CommentID: &comment.ID,
+		})
+	}
+
+	if err = notificationService.create(notifications); err != nil {
+		log.Printf("notifying comment %d: %v", comment.ID, err)
+	}
+}
+
+// Liked notifies the owner of the photo, unless they liked it themselves
+func (notificationService *NotificationServiceRepository) Liked(userID, photoID uint) {
+
+	photo, err := notificationService.PhotoRepository.GetOne(photoID)
+	if err != nil {
+		log.Printf("notifying like of photo %d: %v", photoID, err)
+		return
+	}
+
+	if photo.UserID == userID {
+		return
+	}
+
+	if err = notificationService.create([]domain.Notification{{
+		UserID:  photo.UserID,
+		ActorID: userID,
+		Type:    domain.NotificationTypeLike,
+		PhotoID: &photoID,
+	}}); err != nil {
+		log.Printf("notifying like of photo %d: %v", photoID, err)
+	}
+}
+
+// Unliked withdraws the notification of a like taken back
+func (notificationService *NotificationServiceRepository) Unliked(userID, photoID uint) {
+
+	if err := notificationService.NotificationRepository.Withdraw(domain.Notification{
+		ActorID: userID,
+		Type:    domain.NotificationTypeLike,
+		PhotoID: &photoID,
+	}); err != nil {
+		log.Printf("withdrawing like of photo %d: %v", photoID, err)
+	}
+}
+
+// Followed notifies the followed user
+func (notificationService *NotificationServiceRepository) Followed(followerID, followeeID uint) {
+
+	if err := notificationService.create([]domain.Notification{{
+		UserID:  followeeID,
+		ActorID: followerID,
+		Type:    domain.NotificationTypeFollow,
+	}}); err != nil {
+		log.Printf("notifying follow of user %d: %v", followeeID, err)
+	}
+}
+
+// Unfollowed withdraws the notification of a follow taken back
+func (notificationService *NotificationServiceRepository) Unfollowed(followerID, followeeID uint) {
+
+	if err := notificationService.NotificationRepository.Withdraw(domain.Notification{
+		UserID:  followeeID,
+		ActorID: followerID,
+		Type:    domain.NotificationTypeFollow,
+	}); err != nil {
+		log.Printf("withdrawing follow of user %d: %v", followeeID, err)
+	}
+}
+
+// Mentioned notifies userIDs that actorID mentioned them in a photo caption,
+// or in a comment on the photo when commentID is set
+func (notificationService *NotificationServiceRepository) Mentioned(actorID uint, userIDs []uint, photoID uint, commentID *uint) {
+
+	notifications := make([]domain.Notification, 0, len(userIDs))
+	for _, userID := range userIDs {
+		notifications = append(notifications, domain.Notification{
+			UserID:    userID,
+			ActorID:   actorID,
+			Type:      domain.NotificationTypeMention,
+			PhotoID:   &photoID,
+			CommentID: commentID,
+		})
+	}
+
+	if err := notificationService.create(notifications); err != nil {
+		log.Printf("notifying mentions in photo %d: %v", photoID, err)
+	}
+}
+
+// Unmentioned withdraws the notifications of users no longer mentioned
+func (notificationService *NotificationServiceRepository) Unmentioned(userIDs []uint, photoID uint, commentID *uint) {
+
+	if len(userIDs) == 0 {
+		return
+	}
+
+	if err := notificationService.NotificationRepository.DeleteMentions(userIDs, photoID, commentID); err != nil {
+		log.Printf("withdrawing mentions in photo %d: %v", photoID, err)
+	}
+}
+
+// create stores the notifications and pushes them to their users, who are
+// still notified on their next visit when the push fails
+func (notificationService *NotificationServiceRepository) create(notifications []domain.Notification) (err error) {
+
+	if err = notificationService.NotificationRepository.Create(notifications); err != nil || len(notifications) == 0 {
+		return
+	}
+
+	ids := make([]uint, 0, len(notifications))
+	for _, notification := range notifications {
+		ids = append(ids, notification.ID)
+	}
+
+	created, err := notificationService.NotificationRepository.GetByIDs(ids)
+	if err != nil {
+		log.Printf("publishing notifications %v: %v", ids, err)
+		return nil
+	}
+
+	notificationService.NotificationPublisher.NotificationsCreated(created)
+	return
+}
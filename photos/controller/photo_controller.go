@@ -1,15 +1,19 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 
+	"mygram-api/auth"
 	"mygram-api/helpers"
+	likeService "mygram-api/likes/service"
 	"mygram-api/models/domain"
 	"mygram-api/models/request"
 	"mygram-api/models/response"
@@ -25,32 +29,45 @@ type PhotoController interface {
 	Delete(c *gin.Context)
 }
 
+// multipartOverhead leaves room for the boundaries and text fields of an upload
+const multipartOverhead = 1 << 20
+
 type PhotoControllerService struct {
-	PhotoService service.PhotoService
+	PhotoService  service.PhotoService
+	LikeService   likeService.LikeService
+	MaxUploadSize int
 }
 
-func NewPhotoController(photoService service.PhotoService) PhotoController {
-	return &PhotoControllerService{PhotoService: photoService}
+func NewPhotoController(photoService service.PhotoService, likeService likeService.LikeService, maxUploadSize int) PhotoController {
+	return &PhotoControllerService{PhotoService: photoService, LikeService: likeService, MaxUploadSize: maxUploadSize}
 }
 
 // Create photo godoc
 // @Summary Create a photo
-// @Description Create and store a new photo with authentication user
+// @Description Create and store a new photo with authentication user, either from a photo_url
+// @Description or by uploading the image itself as multipart/form-data with the title, caption and photo fields.
+// @Description Uploads are stripped of their EXIF, set share_location=true to keep a coarse location on the photo
 // @Tags photos
-// @Accept json
+// @Accept json,x-www-form-urlencoded,mpfd
 // @Produce json
 // @Param json body request.PhotoCreateRequest true "Add Photo"
 // @Success 201 {object} response.SuccessResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
+// @Failure 413 {object} response.ErrorResponse
+// @Failure 415 {object} response.ErrorResponse
 // @Security Bearer
 // @Router /photos [post]
 func (photoController *PhotoControllerService) Create(c *gin.Context) {
 
 	var req request.PhotoCreateRequest
 
-	userData := c.MustGet("userData").(jwt.MapClaims)
-	userID := uint(userData["id"].(float64))
+	userID := auth.MustGetPrincipal(c).UserID
+
+	if c.ContentType() == "multipart/form-data" {
+		photoController.upload(c, userID)
+		return
+	}
 
 	contentType := helpers.GetContentType(c)
 
@@ -124,16 +141,161 @@ func (photoController *PhotoControllerService) Create(c *gin.Context) {
 			PhotoUrl:  photo.PhotoUrl,
 			UserID:    photo.UserID,
 			CreatedAt: photo.CreatedAt,
+			Tags:      response.NewPhotoTagsResponse(photo.Tags),
 		},
 	})
 }
 
+// upload creates a photo from a multipart/form-data request carrying the image itself
+func (photoController *PhotoControllerService) upload(c *gin.Context, userID uint) {
+
+	var req request.PhotoUploadRequest
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(photoController.MaxUploadSize+multipartOverhead))
+
+	if err := c.ShouldBind(&req); err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			photoController.abortTooLarge(c)
+			return
+		}
+
+		validationError, ok := err.(validator.ValidationErrors)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+				Code:   http.StatusBadRequest,
+				Status: "Bad Request",
+				Errors: err.Error(),
+			})
+
+			return
+		}
+
+		fieldErrorResponse := make(map[string]interface{})
+
+		for _, v := range validationError {
+			fieldErrorResponse[strings.ToLower(v.Field())] = helpers.GetValidationErrorMsg(v)
+		}
+
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: fieldErrorResponse,
+		})
+
+		return
+	}
+
+	if req.Photo.Size > int64(photoController.MaxUploadSize) {
+		photoController.abortTooLarge(c)
+		return
+	}
+
+	file, err := req.Photo.Open()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, int64(photoController.MaxUploadSize)+1))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	if len(data) > photoController.MaxUploadSize {
+		photoController.abortTooLarge(c)
+		return
+	}
+
+	photo := domain.Photo{
+		Title:   req.Title,
+		Caption: req.Caption,
+		UserID:  userID,
+	}
+
+	if err = photoController.PhotoService.Upload(c.Request.Context(), &photo, data, req.ShareLocation); err != nil {
+		if errors.Is(err, service.ErrImageTooLarge) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, response.ErrorResponse{
+				Code:   http.StatusRequestEntityTooLarge,
+				Status: "Request Entity Too Large",
+				Errors: err.Error(),
+			})
+
+			return
+		}
+
+		if errors.Is(err, service.ErrUnsupportedImage) {
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, response.ErrorResponse{
+				Code:   http.StatusUnsupportedMediaType,
+				Status: "Unsupported Media Type",
+				Errors: err.Error(),
+			})
+
+			return
+		}
+
+		// recorded for the request log, clients only see the generic message
+		_ = c.Error(err)
+
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.ErrorResponse{
+			Code:   http.StatusInternalServerError,
+			Status: "Internal Server Error",
+			Errors: "Failed to store the photo",
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.SuccessResponse{
+		Data: response.PhotoCreateResponse{
+			ID:          photo.ID,
+			Title:       photo.Title,
+			Caption:     photo.Caption,
+			PhotoUrl:    photo.PhotoUrl,
+			UserID:      photo.UserID,
+			CreatedAt:   photo.CreatedAt,
+			Tags:        response.NewPhotoTagsResponse(photo.Tags),
+			ContentType: photo.ContentType,
+			ByteSize:    photo.ByteSize,
+			Width:       photo.Width,
+			Height:      photo.Height,
+		},
+	})
+}
+
+func (photoController *PhotoControllerService) abortTooLarge(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, response.ErrorResponse{
+		Code:   http.StatusRequestEntityTooLarge,
+		Status: "Request Entity Too Large",
+		Errors: fmt.Sprintf("Photo must not be larger than %d bytes", photoController.MaxUploadSize),
+	})
+}
+
 // GetAll photo godoc
 // @Summary Get all photos
-// @Description Get all photos with authentication user
+// @Description Get a page of photos with authentication user
 // @Tags photos
 // @Accept json
 // @Produce json
+// @Param page query int false "Page number for offset pagination with a total, without it the first keyset page is returned. Ignored when cursor is set"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "Cursor of the next page from a previous response"
+// @Param sort query string false "Sort order" Enums(-created_at, created_at) default(-created_at)
+// @Param user_id query int false "Only items of this user"
+// @Param created_after query string false "Only items created after this RFC 3339 time"
 // @Success 200 {object} response.SuccessResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
@@ -141,34 +303,68 @@ func (photoController *PhotoControllerService) Create(c *gin.Context) {
 // @Router /photos [get]
 func (photoController *PhotoControllerService) GetAll(c *gin.Context) {
 
-	photos, err := photoController.PhotoService.GetAll()
+	var list request.ListRequest
+
+	if err := c.ShouldBindQuery(&list); err != nil {
+		validationError, ok := err.(validator.ValidationErrors)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+				Code:   http.StatusBadRequest,
+				Status: "Bad Request",
+				Errors: err.Error(),
+			})
+
+			return
+		}
+
+		fieldErrorResponse := make(map[string]interface{})
+
+		for _, v := range validationError {
+			fieldErrorResponse[strings.ToLower(v.Field())] = helpers.GetValidationErrorMsg(v)
+		}
+
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: fieldErrorResponse,
+		})
+
+		return
+	}
+
+	list.Normalize()
+
+	photos, page, err := photoController.PhotoService.GetAll(list)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
 			Code:   http.StatusBadRequest,
 			Status: "Bad Request",
 			Errors: err.Error(),
 		})
+
+		return
+	}
+
+	likeSummaries, err := photoController.LikeService.GetSummaries(photos, auth.MustGetPrincipal(c).UserID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
 	}
 
 	photosResponse := []response.PhotoGetAllResponse{}
 	for _, photo := range photos {
-		photosResponse = append(photosResponse, response.PhotoGetAllResponse{
-			ID:        photo.ID,
-			Title:     photo.Title,
-			Caption:   photo.Caption,
-			PhotoUrl:  photo.PhotoUrl,
-			UserID:    photo.UserID,
-			CreatedAt: photo.CreatedAt,
-			UpdatedAt: photo.UpdatedAt,
-			User: response.PhotoUserGetAllReponse{
-				Username: photo.User.Username,
-			},
-		})
+		photosResponse = append(photosResponse, response.NewPhotoGetAllResponse(photo, likeSummaries[photo.ID]))
 	}
 
 	c.JSON(http.StatusOK, response.SuccessResponse{
-		Data:   photosResponse,
+		Data:       photosResponse,
+		Pagination: response.NewListPaginationResponse(list, page.Total, page.NextCursor),
 	})
 }
 
@@ -205,6 +401,16 @@ func (photoController *PhotoControllerService) GetOne(c *gin.Context) {
         return
     }
 
+    likeSummary, err := photoController.LikeService.GetSummary(photo.ID, auth.MustGetPrincipal(c).UserID)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, response.ErrorResponse{
+            Code:   http.StatusBadRequest,
+            Status: "Bad Request",
+            Errors: err.Error(),
+        })
+        return
+    }
+
     photoResponse := response.PhotoGetOneResponse{
         ID:        photo.ID,
         Title:     photo.Title,
@@ -216,6 +422,19 @@ func (photoController *PhotoControllerService) GetOne(c *gin.Context) {
         User: response.PhotoUserGetAllReponse{
             Username: photo.User.Username,
         },
+        Tags:         response.NewPhotoTagsResponse(photo.Tags),
+        ContentType:  photo.ContentType,
+        ByteSize:     photo.ByteSize,
+        Width:        photo.Width,
+        Height:       photo.Height,
+        TakenAt:      photo.TakenAt,
+        CameraModel:  photo.CameraModel,
+        Orientation:  photo.Orientation,
+        Location:     response.NewPhotoLocationResponse(photo),
+        Variants:     response.NewPhotoVariantsResponse(photo.Variants),
+        LikeCount:    likeSummary.LikeCount,
+        LikedByMe:    likeSummary.LikedByMe,
+        CommentCount: photo.CommentCount,
     }
 
     c.JSON(http.StatusOK, response.SuccessResponse{
@@ -303,6 +522,7 @@ func (photoController *PhotoControllerService) Update(c *gin.Context) {
 			PhotoUrl:  updatedPhoto.PhotoUrl,
 			Caption:   updatedPhoto.Caption,
 			UpdatedAt: updatedPhoto.UpdatedAt,
+			Tags:      response.NewPhotoTagsResponse(updatedPhoto.Tags),
 		},
 	})
 }
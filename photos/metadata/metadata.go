@@ -0,0 +1,75 @@
+// Package metadata extracts the EXIF fields that are safe to keep from an
+// uploaded photo and strips everything else before the photo is stored.
+package metadata
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// locationPrecision rounds coordinates to one decimal, about 11 km, enough
+// to tell the city but not the street a photo was taken in
+const locationPrecision = 10
+
+// Metadata represents the EXIF fields of a photo considered safe to keep
+type Metadata struct {
+	TakenAt     *time.Time
+	CameraModel string
+	Orientation int
+	Latitude    *float64
+	Longitude   *float64
+}
+
+// Extract reads the EXIF of a JPEG or TIFF-based image. It is best effort,
+// data without readable EXIF yields empty Metadata. Coordinates are only
+// read, rounded to a coarse location, when withLocation is set.
+func Extract(data []byte, withLocation bool) (metadata Metadata) {
+	// goexif panics on some malformed inputs, which must not fail the upload
+	defer func() {
+		if recover() != nil {
+			metadata = Metadata{}
+		}
+	}()
+
+	x, err := exif.Decode(bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+
+	if takenAt, err := x.DateTime(); err == nil {
+		// without a time zone in the EXIF keep the camera's wall clock as UTC
+		// rather than whatever zone the server runs in
+		if takenAt.Location() == time.Local {
+			takenAt = time.Date(takenAt.Year(), takenAt.Month(), takenAt.Day(), takenAt.Hour(), takenAt.Minute(), takenAt.Second(), 0, time.UTC)
+		}
+
+		metadata.TakenAt = &takenAt
+	}
+
+	if tag, err := x.Get(exif.Model); err == nil {
+		if model, err := tag.StringVal(); err == nil {
+			metadata.CameraModel = strings.TrimSpace(strings.TrimRight(model, "\x00"))
+		}
+	}
+
+	if tag, err := x.Get(exif.Orientation); err == nil {
+		if orientation, err := tag.Int(0); err == nil && orientation >= 1 && orientation <= 8 {
+			metadata.Orientation = orientation
+		}
+	}
+
+	if withLocation {
+		if latitude, longitude, err := x.LatLong(); err == nil && !math.IsNaN(latitude) && !math.IsNaN(longitude) {
+			latitude = math.Round(latitude*locationPrecision) / locationPrecision
+			longitude = math.Round(longitude*locationPrecision) / locationPrecision
+
+			metadata.Latitude, metadata.Longitude = &latitude, &longitude
+		}
+	}
+
+	return
+}
@@ -0,0 +1,53 @@
+package metadata
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Orient turns img upright according to an EXIF orientation between 1 and 8
+func Orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	src := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	width, height := bounds.Dx(), bounds.Dy()
+
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dstX, dstY int
+
+			switch orientation {
+			case 2: // mirrored horizontally
+				dstX, dstY = width-1-x, y
+			case 3: // rotated 180°
+				dstX, dstY = width-1-x, height-1-y
+			case 4: // mirrored vertically
+				dstX, dstY = x, height-1-y
+			case 5: // transposed
+				dstX, dstY = y, x
+			case 6: // needs a 90° clockwise turn
+				dstX, dstY = height-1-y, x
+			case 7: // transversed
+				dstX, dstY = height-1-y, width-1-x
+			case 8: // needs a 90° counter-clockwise turn
+				dstX, dstY = y, width-1-x
+			}
+
+			copy(dst.Pix[dst.PixOffset(dstX, dstY):][:4], src.Pix[src.PixOffset(x, y):][:4])
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,68 @@
+package metadata
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestOrient(t *testing.T) {
+	// a 3x2 image whose top-left pixel is red and the one next to it green,
+	// enough to tell every rotation and mirror apart
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	red := color.NRGBA{R: 255, A: 255}
+	green := color.NRGBA{G: 255, A: 255}
+	img.SetNRGBA(0, 0, red)
+	img.SetNRGBA(1, 0, green)
+
+	tests := []struct {
+		orientation   int
+		width, height int
+		red, green    image.Point
+	}{
+		{0, 3, 2, image.Pt(0, 0), image.Pt(1, 0)},
+		{1, 3, 2, image.Pt(0, 0), image.Pt(1, 0)},
+		{2, 3, 2, image.Pt(2, 0), image.Pt(1, 0)},
+		{3, 3, 2, image.Pt(2, 1), image.Pt(1, 1)},
+		{4, 3, 2, image.Pt(0, 1), image.Pt(1, 1)},
+		{5, 2, 3, image.Pt(0, 0), image.Pt(0, 1)},
+		{6, 2, 3, image.Pt(1, 0), image.Pt(1, 1)},
+		{7, 2, 3, image.Pt(1, 2), image.Pt(1, 1)},
+		{8, 2, 3, image.Pt(0, 2), image.Pt(0, 1)},
+		{9, 3, 2, image.Pt(0, 0), image.Pt(1, 0)},
+	}
+
+	for _, test := range tests {
+		oriented := Orient(img, test.orientation)
+
+		bounds := oriented.Bounds()
+		if bounds.Dx() != test.width || bounds.Dy() != test.height {
+			t.Errorf("Orient(%d) is %dx%d, want %dx%d", test.orientation, bounds.Dx(), bounds.Dy(), test.width, test.height)
+			continue
+		}
+
+		if got := color.NRGBAModel.Convert(oriented.At(test.red.X, test.red.Y)); got != red {
+			t.Errorf("Orient(%d) at %v = %v, want red", test.orientation, test.red, got)
+		}
+
+		if got := color.NRGBAModel.Convert(oriented.At(test.green.X, test.green.Y)); got != green {
+			t.Errorf("Orient(%d) at %v = %v, want green", test.orientation, test.green, got)
+		}
+	}
+}
+
+func TestOrientSubImage(t *testing.T) {
+	// bounds that do not start at the origin, as a cropped decode may return
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	img.SetNRGBA(1, 1, color.NRGBA{B: 255, A: 255})
+
+	oriented := Orient(img.SubImage(image.Rect(1, 1, 3, 2)), 6)
+
+	if bounds := oriented.Bounds(); bounds != image.Rect(0, 0, 1, 2) {
+		t.Fatalf("Orient bounds = %v, want 1x2 at the origin", bounds)
+	}
+
+	if got := color.NRGBAModel.Convert(oriented.At(0, 0)); got != (color.NRGBA{B: 255, A: 255}) {
+		t.Errorf("Orient at (0, 0) = %v, want blue", got)
+	}
+}
@@ -0,0 +1,290 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var ErrMalformedImage = errors.New("malformed image")
+
+var (
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+	// pngMetadataChunks carry EXIF, XMP (inside iTXt) and free text
+	pngMetadataChunks = map[string]bool{"eXIf": true, "tEXt": true, "iTXt": true, "zTXt": true, "tIME": true}
+)
+
+const (
+	webpExifFlag = 0x08
+	webpXMPFlag  = 0x04
+)
+
+// Strip removes EXIF, XMP, IPTC and comments from an image without
+// re-encoding its pixels. Unknown content types are returned unchanged.
+func Strip(data []byte, contentType string) ([]byte, error) {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		return stripPNG(data)
+	case "image/webp":
+		return stripWebP(data)
+	case "image/gif":
+		return stripGIF(data)
+	default:
+		return data, nil
+	}
+}
+
+// stripJPEG keeps APP0 (JFIF), APP2 (ICC profile) and APP14 (Adobe colour
+// transform) and drops every other APPn segment and comments
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, ErrMalformedImage
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+
+	for i := 2; i < len(data); {
+		if data[i] != 0xFF {
+			return nil, ErrMalformedImage
+		}
+
+		// markers may be preceded by any number of 0xFF fill bytes
+		for i < len(data) && data[i] == 0xFF {
+			i++
+		}
+
+		if i >= len(data) {
+			return nil, ErrMalformedImage
+		}
+
+		marker := data[i]
+		i++
+
+		// standalone markers have no length
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			out.Write([]byte{0xFF, marker})
+			continue
+		}
+
+		if marker == 0xD9 {
+			out.Write([]byte{0xFF, marker})
+			return out.Bytes(), nil
+		}
+
+		if i+2 > len(data) {
+			return nil, ErrMalformedImage
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i:]))
+		if length < 2 || i+length > len(data) {
+			return nil, ErrMalformedImage
+		}
+
+		segment := data[i-2 : i+length]
+		i += length
+
+		// the entropy-coded scan and everything after it carry no metadata
+		if marker == 0xDA {
+			out.Write(segment)
+			out.Write(data[i:])
+			return out.Bytes(), nil
+		}
+
+		isApp := marker >= 0xE0 && marker <= 0xEF
+		keep := !isApp || marker == 0xE0 || marker == 0xE2 || marker == 0xEE
+
+		if keep && marker != 0xFE {
+			out.Write(segment)
+		}
+	}
+
+	return out.Bytes(), nil
+}
+
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, ErrMalformedImage
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngSignature)
+
+	for i := len(pngSignature); i < len(data); {
+		if i+8 > len(data) {
+			return nil, ErrMalformedImage
+		}
+
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		chunkType := string(data[i+4 : i+8])
+
+		// length, type, data and crc, compared before adding so that a
+		// forged length cannot overflow
+		if length < 0 || length > len(data)-i-12 {
+			return nil, ErrMalformedImage
+		}
+
+		end := i + 12 + length
+
+		if !pngMetadataChunks[chunkType] {
+			out.Write(data[i:end])
+		}
+
+		i = end
+
+		if chunkType == "IEND" {
+			break
+		}
+	}
+
+	return out.Bytes(), nil
+}
+
+// stripWebP drops the EXIF and XMP chunks of a RIFF container and clears
+// their flags in the VP8X header
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrMalformedImage
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:12])
+
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, ErrMalformedImage
+		}
+
+		fourCC := string(data[i : i+4])
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		if size < 0 || size > len(data)-i-8 {
+			return nil, ErrMalformedImage
+		}
+
+		// chunks are padded to an even size, some encoders omit the last padding byte
+		end := i + 8 + size + size%2
+		if size%2 == 1 && end == len(data)+1 {
+			end = len(data)
+		}
+
+		if end > len(data) {
+			return nil, ErrMalformedImage
+		}
+
+		switch fourCC {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), data[i:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= webpExifFlag | webpXMPFlag
+			}
+
+			out.Write(chunk)
+		default:
+			out.Write(data[i:end])
+		}
+
+		i = end
+	}
+
+	stripped := out.Bytes()
+	binary.LittleEndian.PutUint32(stripped[4:], uint32(len(stripped)-8))
+
+	return stripped, nil
+}
+
+// stripGIF drops comment extensions and XMP application extensions
+func stripGIF(data []byte) ([]byte, error) {
+	if len(data) < 13 || (string(data[:6]) != "GIF87a" && string(data[:6]) != "GIF89a") {
+		return nil, ErrMalformedImage
+	}
+
+	// header and logical screen descriptor, followed by the global colour table
+	i := 13
+	if data[10]&0x80 != 0 {
+		i += 3 << (data[10]&0x07 + 1)
+	}
+
+	if i > len(data) {
+		return nil, ErrMalformedImage
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:i])
+
+	for i < len(data) {
+		start := i
+
+		switch data[i] {
+		case 0x3B: // trailer
+			out.WriteByte(0x3B)
+			return out.Bytes(), nil
+
+		case 0x21: // extension: introducer, label and data sub-blocks
+			if i+2 > len(data) {
+				return nil, ErrMalformedImage
+			}
+
+			label := data[i+1]
+
+			end, err := skipSubBlocks(data, i+2)
+			if err != nil {
+				return nil, err
+			}
+
+			isComment := label == 0xFE
+			isXMP := label == 0xFF && i+3+11 <= len(data) && string(data[i+3:i+3+11]) == "XMP DataXMP"
+
+			if !isComment && !isXMP {
+				out.Write(data[start:end])
+			}
+
+			i = end
+
+		case 0x2C: // image descriptor, optional local colour table, LZW code size and data
+			if i+10 > len(data) {
+				return nil, ErrMalformedImage
+			}
+
+			next := i + 10
+			if data[i+9]&0x80 != 0 {
+				next += 3 << (data[i+9]&0x07 + 1)
+			}
+
+			end, err := skipSubBlocks(data, next+1)
+			if err != nil {
+				return nil, err
+			}
+
+			out.Write(data[start:end])
+			i = end
+
+		default:
+			return nil, ErrMalformedImage
+		}
+	}
+
+	return out.Bytes(), nil
+}
+
+// skipSubBlocks returns the offset right after the zero-length block ending
+// the data sub-blocks that start at i
+func skipSubBlocks(data []byte, i int) (int, error) {
+	for {
+		if i >= len(data) {
+			return 0, ErrMalformedImage
+		}
+
+		size := int(data[i])
+		i++
+
+		if size == 0 {
+			return i, nil
+		}
+
+		i += size
+	}
+}
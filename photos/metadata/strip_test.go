@@ -0,0 +1,291 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// exifSegment is an APP1 segment holding a big-endian TIFF header and a
+// single IFD entry, an orientation of 6
+var exifSegment = jpegSegment(0xE1, append([]byte("Exif\x00\x00MM\x00\x2A\x00\x00\x00\x08"),
+	0x00, 0x01, // one entry
+	0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, 0x06, 0x00, 0x00, // orientation, short, 1 value: 6
+	0x00, 0x00, 0x00, 0x00, // no next IFD
+))
+
+func testImage() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for i := range img.Pix {
+		img.Pix[i] = byte(i * 7)
+	}
+
+	return img
+}
+
+func jpegSegment(marker byte, payload []byte) []byte {
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+
+	return append(segment, payload...)
+}
+
+// jpegFixture encodes a small JPEG and inserts segments right after its SOI
+func jpegFixture(t *testing.T, segments ...[]byte) []byte {
+	t.Helper()
+
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, testImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	data := append([]byte(nil), encoded.Bytes()[:2]...)
+	for _, segment := range segments {
+		data = append(data, segment...)
+	}
+
+	return append(data, encoded.Bytes()[2:]...)
+}
+
+func pngChunk(chunkType string, payload []byte) []byte {
+	chunk := make([]byte, 8, 12+len(payload))
+	binary.BigEndian.PutUint32(chunk, uint32(len(payload)))
+	copy(chunk[4:], chunkType)
+	chunk = append(chunk, payload...)
+
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// pngFixture encodes a small PNG and inserts chunks right after its IHDR
+func pngFixture(t *testing.T, chunks ...[]byte) []byte {
+	t.Helper()
+
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, testImage()); err != nil {
+		t.Fatal(err)
+	}
+
+	// signature and the 13 bytes IHDR chunk
+	headerEnd := len(pngSignature) + 12 + 13
+
+	data := append([]byte(nil), encoded.Bytes()[:headerEnd]...)
+	for _, chunk := range chunks {
+		data = append(data, chunk...)
+	}
+
+	return append(data, encoded.Bytes()[headerEnd:]...)
+}
+
+func webpChunk(fourCC string, payload []byte) []byte {
+	chunk := make([]byte, 8, 9+len(payload))
+	copy(chunk, fourCC)
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(payload)))
+	chunk = append(chunk, payload...)
+
+	if len(payload)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+
+	return chunk
+}
+
+func webpFixture(chunks ...[]byte) []byte {
+	data := []byte("RIFF\x00\x00\x00\x00WEBP")
+	for _, chunk := range chunks {
+		data = append(data, chunk...)
+	}
+
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(data)-8))
+
+	return data
+}
+
+// gifFixture encodes a small GIF and inserts extensions right before its
+// trailer
+func gifFixture(t *testing.T, extensions ...[]byte) []byte {
+	t.Helper()
+
+	palette := color.Palette{color.Black, color.White}
+	img := image.NewPaletted(image.Rect(0, 0, 4, 2), palette)
+	img.SetColorIndex(1, 1, 1)
+
+	var encoded bytes.Buffer
+	if err := gif.Encode(&encoded, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	data := append([]byte(nil), encoded.Bytes()[:encoded.Len()-1]...)
+	for _, extension := range extensions {
+		data = append(data, extension...)
+	}
+
+	return append(data, 0x3B)
+}
+
+func TestStripJPEG(t *testing.T) {
+	icc := jpegSegment(0xE2, []byte("ICC_PROFILE\x00\x01\x01profile"))
+	data := jpegFixture(t,
+		exifSegment,
+		jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta/>")),
+		jpegSegment(0xED, []byte("Photoshop 3.0\x00iptc")),
+		icc,
+		jpegSegment(0xFE, []byte("shot by someone")),
+	)
+
+	if Extract(data, false).Orientation != 6 {
+		t.Fatal("fixture has no readable orientation")
+	}
+
+	stripped, err := Strip(data, "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, leaked := range []string{"Exif", "xmpmeta", "Photoshop", "shot by someone"} {
+		if bytes.Contains(stripped, []byte(leaked)) {
+			t.Errorf("stripped JPEG still holds %q", leaked)
+		}
+	}
+
+	if !bytes.Contains(stripped, icc) {
+		t.Error("stripped JPEG lost its ICC profile")
+	}
+
+	if Extract(stripped, false) != (Metadata{}) {
+		t.Error("stripped JPEG still has EXIF")
+	}
+
+	if _, err = jpeg.Decode(bytes.NewReader(stripped)); err != nil {
+		t.Errorf("stripped JPEG does not decode: %v", err)
+	}
+}
+
+func TestStripPNG(t *testing.T) {
+	data := pngFixture(t,
+		pngChunk("tEXt", []byte("Author\x00someone")),
+		pngChunk("eXIf", exifSegment[10:]),
+		pngChunk("tIME", []byte{0x07, 0xE7, 8, 1, 12, 0, 0}),
+	)
+
+	stripped, err := Strip(data, "image/png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, leaked := range []string{"tEXt", "eXIf", "tIME", "someone"} {
+		if bytes.Contains(stripped, []byte(leaked)) {
+			t.Errorf("stripped PNG still holds %q", leaked)
+		}
+	}
+
+	if _, err = png.Decode(bytes.NewReader(stripped)); err != nil {
+		t.Errorf("stripped PNG does not decode: %v", err)
+	}
+}
+
+func TestStripWebP(t *testing.T) {
+	// VP8X flags with EXIF, XMP and alpha set, then a 1x1 canvas
+	vp8x := webpChunk("VP8X", []byte{webpExifFlag | webpXMPFlag | 0x10, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+	bitstream := webpChunk("VP8L", []byte{0x2F, 0, 0, 0, 0})
+	data := webpFixture(vp8x, bitstream, webpChunk("EXIF", []byte("Exif\x00\x00MM")), webpChunk("XMP ", []byte("<x:xmpmeta/>")))
+
+	stripped, err := Strip(data, "image/webp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := webpFixture(webpChunk("VP8X", []byte{0x10, 0, 0, 0, 0, 0, 0, 0, 0, 0}), bitstream)
+	if !bytes.Equal(stripped, want) {
+		t.Errorf("Strip(webp) = %q, want %q", stripped, want)
+	}
+}
+
+func TestStripGIF(t *testing.T) {
+	comment := []byte{0x21, 0xFE, 5, 'h', 'e', 'l', 'l', 'o', 0}
+	xmp := append([]byte{0x21, 0xFF, 11}, "XMP DataXMP"...)
+	xmp = append(xmp, 4, '<', 'x', '/', '>', 0)
+	loop := append([]byte{0x21, 0xFF, 11}, "NETSCAPE2.0"...)
+	loop = append(loop, 3, 1, 0, 0, 0)
+
+	data := gifFixture(t, comment, xmp, loop)
+
+	stripped, err := Strip(data, "image/gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := gifFixture(t, loop); !bytes.Equal(stripped, want) {
+		t.Errorf("Strip(gif) = %q, want %q", stripped, want)
+	}
+
+	if _, err = gif.DecodeAll(bytes.NewReader(stripped)); err != nil {
+		t.Errorf("stripped GIF does not decode: %v", err)
+	}
+}
+
+func TestStripUnknownTypeIsUnchanged(t *testing.T) {
+	data := []byte("not an image")
+
+	stripped, err := Strip(data, "application/octet-stream")
+	if err != nil || !bytes.Equal(stripped, data) {
+		t.Errorf("Strip = %q, %v, want the data unchanged", stripped, err)
+	}
+}
+
+func TestStripMalformed(t *testing.T) {
+	jpegData := jpegFixture(t, exifSegment)
+	pngData := pngFixture(t)
+	gifData := gifFixture(t)
+
+	// a length field set to v at offset i of a copy of data
+	withUint16 := func(data []byte, i int, v uint16) []byte {
+		data = append([]byte(nil), data...)
+		binary.BigEndian.PutUint16(data[i:], v)
+		return data
+	}
+
+	hugePNGChunk := append(append([]byte(nil), pngSignature...), 0xFF, 0xFF, 0xFF, 0xF0, 'I', 'D', 'A', 'T')
+	hugeWebPChunk := webpFixture([]byte("VP8L\xF0\xFF\xFF\xFF"))
+
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+	}{
+		{"empty jpeg", "image/jpeg", nil},
+		{"jpeg without soi", "image/jpeg", []byte("\xFF\xD9\xFF\xD8")},
+		{"jpeg truncated in a segment", "image/jpeg", jpegData[:len(exifSegment)]},
+		{"jpeg truncated after a marker", "image/jpeg", []byte("\xFF\xD8\xFF\xE1\x00")},
+		{"jpeg of fill bytes only", "image/jpeg", []byte("\xFF\xD8\xFF\xFF\xFF")},
+		{"jpeg segment length below 2", "image/jpeg", withUint16(jpegData, 4, 1)},
+		{"jpeg segment length past the end", "image/jpeg", withUint16(jpegData, 4, 0xFFFF)},
+		{"jpeg garbage between segments", "image/jpeg", append(jpegData[:2:2], 0x00, 0xFF, 0xD9)},
+		{"png without signature", "image/png", []byte("PNG")},
+		{"png truncated in a chunk", "image/png", pngData[:len(pngSignature)+20]},
+		{"png truncated in a chunk header", "image/png", pngData[:len(pngSignature)+4]},
+		{"png chunk length past the end", "image/png", hugePNGChunk},
+		{"webp without header", "image/webp", []byte("RIFF\x00\x00\x00\x00WEB")},
+		{"webp truncated in a chunk header", "image/webp", webpFixture([]byte("VP8X\x0A"))},
+		{"webp chunk size past the end", "image/webp", hugeWebPChunk},
+		{"gif without header", "image/gif", []byte("GIF89a")},
+		{"gif colour table past the end", "image/gif", gifData[:14]},
+		{"gif truncated in a sub-block", "image/gif", gifData[:len(gifData)-4]},
+		{"gif sub-block past the end", "image/gif", append(gifData[:len(gifData)-1:len(gifData)-1], 0x21, 0xFE, 0xFF, 'x')},
+		{"gif unknown block", "image/gif", append(gifData[:len(gifData)-1:len(gifData)-1], 0x00)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Strip(test.data, test.contentType); !errors.Is(err, ErrMalformedImage) {
+				t.Errorf("Strip = %v, want ErrMalformedImage", err)
+			}
+		})
+	}
+}
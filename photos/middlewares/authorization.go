@@ -4,9 +4,9 @@ import (
 	"net/http"
 	"strconv"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 
+	"mygram-api/auth"
 	"mygram-api/models/domain"
 	"mygram-api/models/response"
 	"mygram-api/photos/service"
@@ -20,8 +20,7 @@ func Authorization(photoService service.PhotoService) gin.HandlerFunc {
         )
 
         photoID, _ := strconv.Atoi(ctx.Param("id"))
-        userData := ctx.MustGet("userData").(jwt.MapClaims)
-        userID := uint(userData["id"].(float64))
+        principal := auth.MustGetPrincipal(ctx)
 
         if photo, err = photoService.GetOne(uint(photoID)); err != nil {
             ctx.AbortWithStatusJSON(http.StatusNotFound, response.ErrorResponse{
@@ -35,7 +34,7 @@ func Authorization(photoService service.PhotoService) gin.HandlerFunc {
             return
         }
 
-        if photo.UserID != userID {
+        if !principal.CanModerate(photo.UserID) {
             ctx.AbortWithStatusJSON(http.StatusForbidden, response.ErrorResponse{
                 Code:   http.StatusForbidden,
                 Status: "Forbidden",
@@ -3,17 +3,31 @@ package repository
 import (
 	"gorm.io/gorm"
 
+	"mygram-api/database"
 	"mygram-api/models/domain"
+	"mygram-api/models/request"
 )
 
 type PhotoRepository interface {
 	Create(photo *domain.Photo) (err error)
-	GetAll() (photos []domain.Photo, err error)
+	GetAll(list request.ListRequest) (photos []domain.Photo, page database.Page, err error)
+	GetAllFollowedBy(userID uint, list request.ListRequest) (photos []domain.Photo, page database.Page, err error)
+	GetAllInTimeline(userID uint, list request.ListRequest) (photos []domain.Photo, page database.Page, err error)
+	GetAllByTag(name string, list request.ListRequest) (photos []domain.Photo, page database.Page, err error)
 	GetOne(id uint) (photo domain.Photo, err error)
+	GetByIDs(ids []uint) (photos []domain.Photo, err error)
 	Update(photo domain.Photo) (updatedPhoto domain.Photo, err error)
 	Delete(id uint) (err error)
+	GetAllByUser(userID uint, pagination request.PaginationRequest) (photos []domain.Photo, total int64, err error)
+	CountByUser(userID uint) (count int64, err error)
+	CountCommentsReceivedByUser(userID uint) (count int64, err error)
 }
 
+// selectWithCommentCount also selects the number of comments on each photo,
+// leaving out the placeholders of deleted comments. It is passed to Select
+// rather than through a scope so that Count can still replace it.
+const selectWithCommentCount = "photos.*, (SELECT COUNT(*) FROM comments WHERE comments.photo_id = photos.id AND comments.deleted_at IS NULL) AS comment_count"
+
 type PhotoRepositoryDB struct {
 	DB *gorm.DB
 }
@@ -31,18 +45,63 @@ func (photoRepository *PhotoRepositoryDB) Create(photo *domain.Photo) (err error
 	return
 }
 
-func (photoRepository *PhotoRepositoryDB) GetAll() (photos []domain.Photo, err error) {
+func (photoRepository *PhotoRepositoryDB) GetAll(list request.ListRequest) (photos []domain.Photo, page database.Page, err error) {
+
+	return photoRepository.paginate(photoRepository.DB, list)
+}
+
+// GetAllFollowedBy lists the photos of the users userID follows
+func (photoRepository *PhotoRepositoryDB) GetAllFollowedBy(userID uint, list request.ListRequest) (photos []domain.Photo, page database.Page, err error) {
+
+	followees := photoRepository.DB.Model(&domain.Follow{}).Select("followee_id").Where("follower_id = ?", userID)
+
+	return photoRepository.paginate(photoRepository.DB.Where("user_id IN (?)", followees), list)
+}
+
+// GetAllInTimeline lists the photos fanned out to the timeline of userID
+func (photoRepository *PhotoRepositoryDB) GetAllInTimeline(userID uint, list request.ListRequest) (photos []domain.Photo, page database.Page, err error) {
+
+	entries := photoRepository.DB.Model(&domain.TimelineEntry{}).Select("photo_id").Where("user_id = ?", userID)
+
+	return photoRepository.paginate(photoRepository.DB.Where("id IN (?)", entries), list)
+}
+
+// GetAllByTag lists the photos whose caption has the hashtag name
+func (photoRepository *PhotoRepositoryDB) GetAllByTag(name string, list request.ListRequest) (photos []domain.Photo, page database.Page, err error) {
+
+	tagged := photoRepository.DB.Model(&domain.PhotoTag{}).Select("photo_tags.photo_id").
+		Joins("JOIN tags ON tags.id = photo_tags.tag_id").
+		Where("tags.name = ?", name)
+
+	return photoRepository.paginate(photoRepository.DB.Where("id IN (?)", tagged), list)
+}
+
+func (photoRepository *PhotoRepositoryDB) paginate(query *gorm.DB, list request.ListRequest) (photos []domain.Photo, page database.Page, err error) {
+
+	query = query.Model(&domain.Photo{}).Select(selectWithCommentCount).Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "username")
+	}).Preload("Variants").Preload("Tags")
+
+	return database.Paginate(query, list, func(photo domain.Photo) request.Cursor {
+		return request.Cursor{CreatedAt: photo.CreatedAt, ID: photo.ID}
+	})
+}
+
+func (photoRepository *PhotoRepositoryDB) GetOne(id uint) (photo domain.Photo, err error) {
 
-	if err = photoRepository.DB.Preload("User").Find(&photos).Error; err != nil {
+	if err = photoRepository.DB.Select(selectWithCommentCount).Preload("User").Preload("Variants").Preload("Tags").First(&photo, id).Error; err != nil {
 		return
 	}
 
 	return
 }
 
-func (photoRepository *PhotoRepositoryDB) GetOne(id uint) (photo domain.Photo, err error) {
+// GetByIDs loads the photos with the given ids, in no particular order
+func (photoRepository *PhotoRepositoryDB) GetByIDs(ids []uint) (photos []domain.Photo, err error) {
 
-	if err = photoRepository.DB.Preload("User").First(&photo, id).Error; err != nil {
+	if err = photoRepository.DB.Select(selectWithCommentCount).Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "username")
+	}).Preload("Variants").Preload("Tags").Find(&photos, ids).Error; err != nil {
 		return
 	}
 
@@ -64,11 +123,55 @@ func (photoRepository *PhotoRepositoryDB) Update(photo domain.Photo) (updatedPho
 
 func (photoRepository *PhotoRepositoryDB) Delete(id uint) (err error) {
 
-	if err = photoRepository.DB.First(&domain.Photo{}, id).Error; err != nil {
+	return photoRepository.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&domain.Photo{}, id).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("photo_id = ?", id).Delete(&domain.Comment{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&domain.Photo{}, id).Error
+	})
+}
+
+func (photoRepository *PhotoRepositoryDB) GetAllByUser(userID uint, pagination request.PaginationRequest) (photos []domain.Photo, total int64, err error) {
+
+	query := photoRepository.DB.Model(&domain.Photo{}).Where("user_id = ?", userID).Session(&gorm.Session{})
+
+	if err = query.Count(&total).Error; err != nil {
 		return
 	}
 
-	if err = photoRepository.DB.Delete(&domain.Photo{}, id).Error; err != nil {
+	if err = query.Select(selectWithCommentCount).Preload("User").Preload("Variants").Preload("Tags").
+		Order("created_at DESC, id DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.Offset()).
+		Find(&photos).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+func (photoRepository *PhotoRepositoryDB) CountByUser(userID uint) (count int64, err error) {
+
+	if err = photoRepository.DB.Model(&domain.Photo{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// CountCommentsReceivedByUser counts the comments on the photos of the user,
+// leaving out the placeholders of deleted comments like selectWithCommentCount
+func (photoRepository *PhotoRepositoryDB) CountCommentsReceivedByUser(userID uint) (count int64, err error) {
+
+	if err = photoRepository.DB.Model(&domain.Comment{}).
+		Joins("JOIN photos ON photos.id = comments.photo_id").
+		Where("photos.user_id = ? AND comments.deleted_at IS NULL", userID).
+		Count(&count).Error; err != nil {
 		return
 	}
 
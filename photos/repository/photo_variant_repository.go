@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"mygram-api/models/domain"
+)
+
+type PhotoVariantRepository interface {
+	ReplaceAll(photoID uint, variants []domain.PhotoVariant) (err error)
+	GetByPhoto(photoID uint) (variants []domain.PhotoVariant, err error)
+	PendingPhotoIDs(limit int) (photoIDs []uint, err error)
+}
+
+type PhotoVariantRepositoryDB struct {
+	DB *gorm.DB
+}
+
+func NewPhotoVariantRepository(db *gorm.DB) PhotoVariantRepository {
+	return &PhotoVariantRepositoryDB{DB: db}
+}
+
+// ReplaceAll stores every variant of a photo at once, so a photo either has
+// a full set of variants or none and is picked up again by PendingPhotoIDs
+func (photoVariantRepository *PhotoVariantRepositoryDB) ReplaceAll(photoID uint, variants []domain.PhotoVariant) (err error) {
+
+	return photoVariantRepository.DB.Transaction(func(tx *gorm.DB) error {
+		// lock the photo so a concurrent delete cannot leave orphaned rows behind
+		if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).First(&domain.Photo{}, photoID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("photo_id = ?", photoID).Delete(&domain.PhotoVariant{}).Error; err != nil {
+			return err
+		}
+
+		if len(variants) == 0 {
+			return nil
+		}
+
+		return tx.Create(&variants).Error
+	})
+}
+
+func (photoVariantRepository *PhotoVariantRepositoryDB) GetByPhoto(photoID uint) (variants []domain.PhotoVariant, err error) {
+
+	if err = photoVariantRepository.DB.Where("photo_id = ?", photoID).Order("id").Find(&variants).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// PendingPhotoIDs returns uploaded photos that have no variants yet
+func (photoVariantRepository *PhotoVariantRepositoryDB) PendingPhotoIDs(limit int) (photoIDs []uint, err error) {
+
+	if err = photoVariantRepository.DB.Model(&domain.Photo{}).
+		Where("storage_key <> ''").
+		Where("NOT EXISTS (SELECT 1 FROM photo_variants WHERE photo_variants.photo_id = photos.id)").
+		Order("id").
+		Limit(limit).
+		Pluck("id", &photoIDs).Error; err != nil {
+		return
+	}
+
+	return
+}
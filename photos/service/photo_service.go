@@ -1,24 +1,93 @@
 package service
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"log"
+	"net/http"
+
+	_ "golang.org/x/image/webp"
+
+	"mygram-api/database"
+	"mygram-api/helpers"
 	"mygram-api/models/domain"
+	"mygram-api/models/request"
+	"mygram-api/photos/metadata"
 	"mygram-api/photos/repository"
+	"mygram-api/storage"
+)
+
+const (
+	// maxUploadPixels keeps a small but highly compressed upload from exhausting memory once decoded
+	maxUploadPixels = 50_000_000
+
+	// orientedJPEGQuality is used when an upload is re-encoded to apply its orientation
+	orientedJPEGQuality = 92
 )
 
+var (
+	ErrUnsupportedImage = errors.New("photo must be a JPEG, PNG, GIF or WebP image")
+	ErrImageTooLarge    = errors.New("photo must not have more than 50 megapixels")
+)
+
+// uploadExtensions maps the sniffed content type of an accepted upload to its file extension
+var uploadExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// FeedFanOut is told about every new photo, to add it to the timelines of the owner's followers
+type FeedFanOut interface {
+	FanOut(photo domain.Photo)
+}
+
+// TagSync keeps the tags of a photo in sync with the hashtags of its caption
+type TagSync interface {
+	SyncPhotoTags(photo *domain.Photo) (err error)
+}
+
+// MentionSync keeps the users mentioned by a photo in sync with its caption
+type MentionSync interface {
+	SyncPhotoMentions(photo domain.Photo) (err error)
+}
+
+// PhotoWebhooks is told about the photo events, to queue them for the webhooks of the owner
+type PhotoWebhooks interface {
+	PhotoEvent(event string, photo domain.Photo)
+}
+
 type PhotoService interface {
 	Create(photo *domain.Photo) (err error)
-	GetAll() (photos []domain.Photo, err error)
+	Upload(ctx context.Context, photo *domain.Photo, data []byte, shareLocation bool) (err error)
+	GetAll(list request.ListRequest) (photos []domain.Photo, page database.Page, err error)
 	GetOne(id uint) (photo domain.Photo, err error)
 	Update(photo domain.Photo) (updatedPhoto domain.Photo, err error)
 	Delete(id uint) (err error)
 }
 
 type PhotoServiceRepository struct {
-	PhotoRepository repository.PhotoRepository
+	PhotoRepository  repository.PhotoRepository
+	Storage          storage.Storage
+	VariantScheduler VariantScheduler
+	FeedFanOut       FeedFanOut
+	TagSync          TagSync
+	MentionSync      MentionSync
+	PhotoWebhooks    PhotoWebhooks
+
+	// StripMetadata removes EXIF, XMP and comments from uploads before they are stored
+	StripMetadata bool
 }
 
-func NewPhotoService(photoRepository repository.PhotoRepository) PhotoService {
-	return &PhotoServiceRepository{PhotoRepository: photoRepository}
+func NewPhotoService(photoRepository repository.PhotoRepository, storage storage.Storage, variantScheduler VariantScheduler, feedFanOut FeedFanOut, tagSync TagSync, mentionSync MentionSync, photoWebhooks PhotoWebhooks, stripMetadata bool) PhotoService {
+	return &PhotoServiceRepository{PhotoRepository: photoRepository, Storage: storage, VariantScheduler: variantScheduler, FeedFanOut: feedFanOut, TagSync: tagSync, MentionSync: mentionSync, PhotoWebhooks: photoWebhooks, StripMetadata: stripMetadata}
 }
 
 func (photoService *PhotoServiceRepository) Create(photo *domain.Photo) (err error) {
@@ -27,12 +96,132 @@ func (photoService *PhotoServiceRepository) Create(photo *domain.Photo) (err err
 		return
 	}
 
+	photoService.syncCaption(photo)
+
+	photoService.FeedFanOut.FanOut(*photo)
+	photoService.PhotoWebhooks.PhotoEvent(domain.WebhookEventPhotoCreated, *photo)
+
 	return
 }
 
-func (photoService *PhotoServiceRepository) GetAll() (photos []domain.Photo, err error) {
+// Upload checks that data is an image by its content rather than its file name,
+// keeps the safe fields of its EXIF on the photo, stores it without the rest
+// and creates the photo pointing at the stored blob
+func (photoService *PhotoServiceRepository) Upload(ctx context.Context, photo *domain.Photo, data []byte, shareLocation bool) (err error) {
+
+	contentType := http.DetectContentType(data)
+
+	extension, ok := uploadExtensions[contentType]
+	if !ok {
+		return ErrUnsupportedImage
+	}
+
+	imageConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return ErrUnsupportedImage
+	}
+
+	if imageConfig.Width*imageConfig.Height > maxUploadPixels {
+		return ErrImageTooLarge
+	}
+
+	meta := metadata.Extract(data, shareLocation)
+
+	if photoService.StripMetadata {
+		if data, err = photoService.stripMetadata(data, contentType, meta.Orientation); err != nil {
+			return
+		}
 
-	if photos, err = photoService.PhotoRepository.GetAll(); err != nil {
+		// applying the orientation may have swapped the dimensions
+		if imageConfig, _, err = image.DecodeConfig(bytes.NewReader(data)); err != nil {
+			return
+		}
+
+		// the stored pixels are upright, describing them with the original
+		// orientation would get them turned a second time
+		if meta.Orientation > 1 {
+			meta.Orientation = 1
+		}
+	}
+
+	name, err := helpers.GenerateRandomToken(16)
+	if err != nil {
+		return
+	}
+
+	key := fmt.Sprintf("photos/%d/%s%s", photo.UserID, name, extension)
+
+	if err = photoService.Storage.Put(ctx, key, data, contentType); err != nil {
+		return
+	}
+
+	photo.PhotoUrl = photoService.Storage.URL(key)
+	photo.StorageKey = key
+	photo.ContentType = contentType
+	photo.ByteSize = int64(len(data))
+	photo.Width = imageConfig.Width
+	photo.Height = imageConfig.Height
+	photo.TakenAt = meta.TakenAt
+	photo.CameraModel = meta.CameraModel
+	photo.Orientation = meta.Orientation
+	photo.Latitude = meta.Latitude
+	photo.Longitude = meta.Longitude
+
+	if err = photoService.PhotoRepository.Create(photo); err != nil {
+		return errors.Join(err, photoService.Storage.Delete(context.Background(), key))
+	}
+
+	photoService.VariantScheduler.Schedule(photo.ID)
+
+	photoService.syncCaption(photo)
+
+	photoService.FeedFanOut.FanOut(*photo)
+	photoService.PhotoWebhooks.PhotoEvent(domain.WebhookEventPhotoCreated, *photo)
+
+	return
+}
+
+// stripMetadata returns data without its metadata. A rotated or mirrored JPEG
+// is re-encoded upright instead, as the orientation tag is about to be lost.
+func (photoService *PhotoServiceRepository) stripMetadata(data []byte, contentType string, orientation int) ([]byte, error) {
+	if contentType != "image/jpeg" || orientation <= 1 {
+		stripped, err := metadata.Strip(data, contentType)
+		if errors.Is(err, metadata.ErrMalformedImage) {
+			return nil, ErrUnsupportedImage
+		}
+
+		return stripped, err
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+
+	var oriented bytes.Buffer
+	if err = jpeg.Encode(&oriented, metadata.Orient(img, orientation), &jpeg.Options{Quality: orientedJPEGQuality}); err != nil {
+		return nil, err
+	}
+
+	return oriented.Bytes(), nil
+}
+
+// syncCaption updates the tags and mentions of a stored photo from its
+// caption. A failure is logged rather than returned, failing the request
+// would only get a photo that is already stored created again on retry.
+func (photoService *PhotoServiceRepository) syncCaption(photo *domain.Photo) {
+	if err := photoService.TagSync.SyncPhotoTags(photo); err != nil {
+		log.Printf("syncing tags of photo %d: %v", photo.ID, err)
+	}
+
+	if err := photoService.MentionSync.SyncPhotoMentions(*photo); err != nil {
+		log.Printf("syncing mentions of photo %d: %v", photo.ID, err)
+	}
+}
+
+func (photoService *PhotoServiceRepository) GetAll(list request.ListRequest) (photos []domain.Photo, page database.Page, err error) {
+
+	if photos, page, err = photoService.PhotoRepository.GetAll(list); err != nil {
 		return
 	}
 
@@ -54,14 +243,41 @@ func (photoService *PhotoServiceRepository) Update(photo domain.Photo) (updatedP
 		return
 	}
 
+	photoService.syncCaption(&updatedPhoto)
+
+	photoService.PhotoWebhooks.PhotoEvent(domain.WebhookEventPhotoUpdated, updatedPhoto)
+
 	return
 }
 
 func (photoService *PhotoServiceRepository) Delete(id uint) (err error) {
 
+	photo, err := photoService.PhotoRepository.GetOne(id)
+	if err != nil {
+		return
+	}
+
 	if err = photoService.PhotoRepository.Delete(id); err != nil {
 		return
 	}
 
+	photoService.PhotoWebhooks.PhotoEvent(domain.WebhookEventPhotoDeleted, photo)
+
+	// the rows are gone either way, a leftover blob is only wasted space
+	keys := []string{photo.StorageKey}
+	for _, variant := range photo.Variants {
+		keys = append(keys, variant.StorageKey)
+	}
+
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+
+		if err := photoService.Storage.Delete(context.Background(), key); err != nil {
+			log.Printf("deleting blob %s of photo %d: %v", key, photo.ID, err)
+		}
+	}
+
 	return
 }
\ No newline at end of file
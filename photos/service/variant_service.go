@@ -0,0 +1,240 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+
+	"mygram-api/config"
+	"mygram-api/models/domain"
+	"mygram-api/photos/repository"
+	"mygram-api/storage"
+)
+
+// VariantSize is the bounding box a variant is scaled down to fit in
+type VariantSize struct {
+	Name    string
+	MaxEdge int
+}
+
+// VariantSizes are generated for every uploaded photo, each also as WebP when cwebp is available
+var VariantSizes = []VariantSize{
+	{Name: "small", MaxEdge: 320},
+	{Name: "medium", MaxEdge: 640},
+	{Name: "large", MaxEdge: 1280},
+}
+
+// VariantScheduler queues the generation of the variants of an uploaded photo
+type VariantScheduler interface {
+	Schedule(photoID uint)
+}
+
+type VariantService interface {
+	Generate(ctx context.Context, photoID uint) (err error)
+	PendingPhotoIDs(limit int) (photoIDs []uint, err error)
+}
+
+type VariantServiceRepository struct {
+	PhotoRepository        repository.PhotoRepository
+	PhotoVariantRepository repository.PhotoVariantRepository
+	Storage                storage.Storage
+	JPEGQuality            int
+
+	// cwebpPath is empty when WebP variants are disabled
+	cwebpPath string
+}
+
+func NewVariantService(photoRepository repository.PhotoRepository, photoVariantRepository repository.PhotoVariantRepository, storage storage.Storage, variantsConfig config.PhotoVariantsConfig) VariantService {
+	variantService := &VariantServiceRepository{
+		PhotoRepository:        photoRepository,
+		PhotoVariantRepository: photoVariantRepository,
+		Storage:                storage,
+		JPEGQuality:            variantsConfig.JPEGQuality,
+	}
+
+	if variantsConfig.CwebpPath != "" {
+		cwebpPath, err := exec.LookPath(variantsConfig.CwebpPath)
+		if err != nil {
+			log.Printf("WebP photo variants disabled: %v", err)
+		}
+
+		variantService.cwebpPath = cwebpPath
+	}
+
+	return variantService
+}
+
+// Generate scales the original of an uploaded photo down to every variant size and
+// records them all at once. Variant keys derive from the original key, so running it
+// again overwrites the previous blobs.
+func (variantService *VariantServiceRepository) Generate(ctx context.Context, photoID uint) (err error) {
+
+	photo, err := variantService.PhotoRepository.GetOne(photoID)
+	if err != nil {
+		return
+	}
+
+	if photo.StorageKey == "" {
+		return
+	}
+
+	body, err := variantService.Storage.Get(ctx, photo.StorageKey)
+	if err != nil {
+		return
+	}
+
+	original, _, err := image.Decode(body)
+	body.Close()
+
+	if err != nil {
+		return fmt.Errorf("decoding photo %d: %w", photo.ID, err)
+	}
+
+	base := strings.TrimSuffix(photo.StorageKey, path.Ext(photo.StorageKey))
+
+	var variants []domain.PhotoVariant
+
+	for _, size := range VariantSizes {
+		resized := resize(original, size.MaxEdge)
+
+		data, contentType, extension, err := variantService.encode(resized, photo.ContentType)
+		if err != nil {
+			return err
+		}
+
+		variant, err := variantService.store(ctx, photo.ID, size.Name, base+"_"+size.Name+extension, data, contentType, resized)
+		if err != nil {
+			return err
+		}
+
+		variants = append(variants, variant)
+
+		if variantService.cwebpPath == "" {
+			continue
+		}
+
+		if data, err = variantService.encodeWebP(ctx, resized); err != nil {
+			return err
+		}
+
+		if variant, err = variantService.store(ctx, photo.ID, size.Name+"_webp", base+"_"+size.Name+".webp", data, "image/webp", resized); err != nil {
+			return err
+		}
+
+		variants = append(variants, variant)
+	}
+
+	return variantService.PhotoVariantRepository.ReplaceAll(photo.ID, variants)
+}
+
+func (variantService *VariantServiceRepository) PendingPhotoIDs(limit int) (photoIDs []uint, err error) {
+
+	if photoIDs, err = variantService.PhotoVariantRepository.PendingPhotoIDs(limit); err != nil {
+		return
+	}
+
+	return
+}
+
+func (variantService *VariantServiceRepository) store(ctx context.Context, photoID uint, name, key string, data []byte, contentType string, img image.Image) (variant domain.PhotoVariant, err error) {
+
+	if err = variantService.Storage.Put(ctx, key, data, contentType); err != nil {
+		return
+	}
+
+	return domain.PhotoVariant{
+		PhotoID:     photoID,
+		Name:        name,
+		StorageKey:  key,
+		Url:         variantService.Storage.URL(key),
+		ContentType: contentType,
+		ByteSize:    int64(len(data)),
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+	}, nil
+}
+
+// encode keeps PNG for originals that may be transparent and uses JPEG otherwise
+func (variantService *VariantServiceRepository) encode(img image.Image, originalContentType string) (data []byte, contentType, extension string, err error) {
+
+	var buffer bytes.Buffer
+
+	if originalContentType == "image/png" || originalContentType == "image/gif" {
+		err = png.Encode(&buffer, img)
+		return buffer.Bytes(), "image/png", ".png", err
+	}
+
+	err = jpeg.Encode(&buffer, img, &jpeg.Options{Quality: variantService.JPEGQuality})
+
+	return buffer.Bytes(), "image/jpeg", ".jpg", err
+}
+
+// encodeWebP hands the image to cwebp, the standard library has no WebP encoder
+func (variantService *VariantServiceRepository) encodeWebP(ctx context.Context, img image.Image) (data []byte, err error) {
+
+	dir, err := os.MkdirTemp("", "mygram-webp-*")
+	if err != nil {
+		return
+	}
+
+	defer os.RemoveAll(dir)
+
+	input, output := filepath.Join(dir, "in.png"), filepath.Join(dir, "out.webp")
+
+	var buffer bytes.Buffer
+	if err = png.Encode(&buffer, img); err != nil {
+		return
+	}
+
+	if err = os.WriteFile(input, buffer.Bytes(), 0o600); err != nil {
+		return
+	}
+
+	cmd := exec.CommandContext(ctx, variantService.cwebpPath, "-quiet", "-q", strconv.Itoa(variantService.JPEGQuality), input, "-o", output)
+	if message, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("cwebp: %w: %s", err, bytes.TrimSpace(message))
+	}
+
+	return os.ReadFile(output)
+}
+
+// resize scales img down to fit in a maxEdge square, smaller images are left as is
+func resize(img image.Image, maxEdge int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width <= maxEdge && height <= maxEdge {
+		return img
+	}
+
+	if width >= height {
+		width, height = maxEdge, height*maxEdge/width
+	} else {
+		width, height = width*maxEdge/height, maxEdge
+	}
+
+	if width < 1 {
+		width = 1
+	}
+
+	if height < 1 {
+		height = 1
+	}
+
+	resized := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(resized, resized.Bounds(), img, bounds, draw.Src, nil)
+
+	return resized
+}
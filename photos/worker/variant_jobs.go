@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+
+	"mygram-api/jobs"
+	"mygram-api/photos/service"
+)
+
+// sweepLimit bounds how many photos left without variants are requeued by a sweep
+const sweepLimit = 1000
+
+// VariantsPayload names the photo whose variants are generated
+type VariantsPayload struct {
+	PhotoID uint `json:"photo_id"`
+}
+
+var (
+	// VariantsJob generates the variants of an uploaded photo
+	VariantsJob = jobs.Type[VariantsPayload]{Name: "photos.variants", Queue: jobs.QueueVariants, MaxAttempts: 5}
+
+	// VariantsSweepJob queues the photos that still have no variants, such as
+	// the ones uploaded before the job queue existed
+	VariantsSweepJob = jobs.Type[struct{}]{Name: "photos.variants_sweep", Queue: jobs.QueueVariants, MaxAttempts: 3}
+)
+
+// VariantScheduler queues a VariantsJob for every uploaded photo so uploads
+// return as soon as the original is stored
+type VariantScheduler struct {
+	Client *jobs.Client
+}
+
+func NewVariantScheduler(client *jobs.Client) *VariantScheduler {
+	return &VariantScheduler{Client: client}
+}
+
+// Schedule queues the photo unless it is queued already. A photo that cannot
+// be queued is logged and picked up by the next sweep.
+func (variantScheduler *VariantScheduler) Schedule(photoID uint) {
+	if _, err := jobs.Enqueue(variantScheduler.Client, VariantsJob, VariantsPayload{PhotoID: photoID}, variantsKey(photoID)); err != nil {
+		log.Printf("queueing variants of photo %d: %v", photoID, err)
+	}
+}
+
+// VariantsHandler runs VariantsJob. A photo deleted in the meantime is given up.
+func VariantsHandler(variantService service.VariantService) jobs.Handler {
+	return VariantsJob.Handle(func(ctx context.Context, payload VariantsPayload) error {
+		err := variantService.Generate(ctx, payload.PhotoID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return jobs.Permanent(err)
+		}
+
+		return err
+	})
+}
+
+// VariantsSweepHandler runs VariantsSweepJob
+func VariantsSweepHandler(variantService service.VariantService, client *jobs.Client) jobs.Handler {
+	return VariantsSweepJob.Handle(func(ctx context.Context, _ struct{}) error {
+		photoIDs, err := variantService.PendingPhotoIDs(sweepLimit)
+		if err != nil {
+			return err
+		}
+
+		for _, photoID := range photoIDs {
+			if _, err = jobs.Enqueue(client, VariantsJob, VariantsPayload{PhotoID: photoID}, variantsKey(photoID)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func variantsKey(photoID uint) jobs.Option {
+	return jobs.Unique(fmt.Sprintf("photos.variants:%d", photoID))
+}
@@ -0,0 +1,66 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+
+	"mygram-api/config"
+)
+
+// Broker carries the published messages to the hub of every API instance,
+// including the one that published them
+type Broker interface {
+	Publish(message Message) (err error)
+	// Listen delivers the messages published on any instance until ctx is done
+	Listen(ctx context.Context, deliver func(Message)) (err error)
+}
+
+// NewBroker returns the broker of the configured kind
+func NewBroker(realtimeConfig config.RealtimeConfig, db *gorm.DB, dsn string) (Broker, error) {
+	switch realtimeConfig.Broker {
+	case config.RealtimeBrokerLocal:
+		return NewLocalBroker(), nil
+	case config.RealtimeBrokerPostgres:
+		return NewPostgresBroker(db, dsn), nil
+	default:
+		return nil, fmt.Errorf("unknown realtime broker %q", realtimeConfig.Broker)
+	}
+}
+
+// LocalBroker delivers the messages within this process only, for a single instance
+type LocalBroker struct {
+	mutex   sync.RWMutex
+	deliver func(Message)
+}
+
+func NewLocalBroker() *LocalBroker {
+	return &LocalBroker{}
+}
+
+func (localBroker *LocalBroker) Publish(message Message) (err error) {
+	localBroker.mutex.RLock()
+	defer localBroker.mutex.RUnlock()
+
+	if localBroker.deliver != nil {
+		localBroker.deliver(message)
+	}
+
+	return
+}
+
+func (localBroker *LocalBroker) Listen(ctx context.Context, deliver func(Message)) (err error) {
+	localBroker.mutex.Lock()
+	localBroker.deliver = deliver
+	localBroker.mutex.Unlock()
+
+	<-ctx.Done()
+
+	localBroker.mutex.Lock()
+	localBroker.deliver = nil
+	localBroker.mutex.Unlock()
+
+	return
+}
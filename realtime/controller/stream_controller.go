@@ -0,0 +1,345 @@
+package controller
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/websocket"
+
+	"mygram-api/auth"
+	"mygram-api/config"
+	"mygram-api/helpers"
+	"mygram-api/models/request"
+	"mygram-api/models/response"
+	"mygram-api/realtime"
+)
+
+const (
+	// maxPhotoSubscriptions bounds the photos one connection follows the comments of
+	maxPhotoSubscriptions = 50
+
+	maxCommandSize = 1024
+	writeWait      = 10 * time.Second
+)
+
+// the access token is checked on every connection and browsers never send it
+// on their own, so a page on another origin cannot open a stream as the user
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+type StreamController interface {
+	Stream(c *gin.Context)
+	WebSocket(c *gin.Context)
+}
+
+type StreamControllerService struct {
+	Hub            *realtime.Hub
+	SessionChecker auth.SessionChecker
+	Config         config.RealtimeConfig
+}
+
+func NewStreamController(hub *realtime.Hub, sessionChecker auth.SessionChecker, realtimeConfig config.RealtimeConfig) StreamController {
+	return &StreamControllerService{Hub: hub, SessionChecker: sessionChecker, Config: realtimeConfig}
+}
+
+// Stream godoc
+// @Summary Stream notifications and new comments
+// @Description Push the notifications of the authentication user and the new comments of the photos in photo_id as server-sent events named after their type, notification or comment, until the access token expires or its session is revoked.
+// @Description The access token may be passed in access_token for clients that cannot set headers, such as EventSource.
+// @Tags realtime
+// @Security Bearer
+// @Produce text/event-stream
+// @Param photo_id query []int false "Photos to receive the new comments of" collectionFormat(multi)
+// @Param access_token query string false "Access token, when not sent in the Authorization header"
+// @Success 200 {object} realtime.Message
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 503 {object} response.ErrorResponse
+// @Router /stream [get]
+func (streamController *StreamControllerService) Stream(c *gin.Context) {
+
+	principal := auth.MustGetPrincipal(c)
+
+	var stream request.StreamRequest
+
+	if err := c.ShouldBindQuery(&stream); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	subscription, err := streamController.subscribe(principal.UserID, stream.PhotoIDs)
+	if err != nil {
+		abortUnavailable(c, err)
+		return
+	}
+	defer subscription.Close()
+
+	// the stream outlives the write timeout of the server, which is kept for
+	// the other requests; a writer that cannot lift it is closed by it instead
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(streamController.Config.KeepAlive)
+	defer keepAlive.Stop()
+
+	expired := time.NewTimer(time.Until(principal.ExpiresAt))
+	defer expired.Stop()
+
+	sessionCheck := time.NewTicker(streamController.Config.SessionCheck)
+	defer sessionCheck.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case message, ok := <-subscription.Messages():
+			if !ok {
+				return false
+			}
+
+			c.SSEvent(message.Type, message)
+			return true
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case <-expired.C:
+			return false
+		case <-sessionCheck.C:
+			return !streamController.SessionChecker.IsSessionRevoked(principal.SessionID)
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// WebSocket godoc
+// @Summary Stream notifications and new comments over a WebSocket
+// @Description Upgrade to a WebSocket pushing the notifications of the authentication user and the new comments of the followed photos as JSON messages with a topic, type and data, until the access token expires or its session is revoked.
+// @Description Send {"action": "subscribe", "photo_id": 1} or {"action": "unsubscribe", "photo_id": 1} to follow or stop following the comments of a photo, which is acknowledged with a subscribed or unsubscribed message, or an error one.
+// @Description The access token may be passed in access_token for clients that cannot set headers, such as browsers.
+// @Tags realtime
+// @Security Bearer
+// @Param photo_id query []int false "Photos to receive the new comments of from the start" collectionFormat(multi)
+// @Param access_token query string false "Access token, when not sent in the Authorization header"
+// @Success 101
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 503 {object} response.ErrorResponse
+// @Router /ws [get]
+func (streamController *StreamControllerService) WebSocket(c *gin.Context) {
+
+	principal := auth.MustGetPrincipal(c)
+
+	var stream request.StreamRequest
+
+	if err := c.ShouldBindQuery(&stream); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	subscription, err := streamController.subscribe(principal.UserID, stream.PhotoIDs)
+	if err != nil {
+		abortUnavailable(c, err)
+		return
+	}
+	defer subscription.Close()
+
+	// Upgrade answers the handshake errors itself
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	replies := make(chan realtime.Message)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	defer close(stop)
+
+	go func() {
+		defer close(done)
+		streamController.readCommands(conn, subscription, replies, stop)
+	}()
+
+	keepAlive := time.NewTicker(streamController.Config.KeepAlive)
+	defer keepAlive.Stop()
+
+	expired := time.NewTimer(time.Until(principal.ExpiresAt))
+	defer expired.Stop()
+
+	sessionCheck := time.NewTicker(streamController.Config.SessionCheck)
+	defer sessionCheck.Stop()
+
+	for {
+		var message realtime.Message
+
+		select {
+		case received, ok := <-subscription.Messages():
+			if !ok {
+				writeClose(conn, websocket.CloseGoingAway, "stream closed")
+				return
+			}
+
+			message = received
+		case message = <-replies:
+		case <-keepAlive.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+
+			continue
+		case <-expired.C:
+			writeClose(conn, websocket.ClosePolicyViolation, "access token expired")
+			return
+		case <-sessionCheck.C:
+			// signing out everywhere, a password reset and a ban revoke the session
+			if streamController.SessionChecker.IsSessionRevoked(principal.SessionID) {
+				writeClose(conn, websocket.ClosePolicyViolation, "session revoked")
+				return
+			}
+
+			continue
+		case <-done:
+			return
+		}
+
+		if err := conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+			return
+		}
+
+		if err := conn.WriteJSON(message); err != nil {
+			return
+		}
+	}
+}
+
+// readCommands applies the commands of the client until the connection fails
+// or the client misses two pings
+func (streamController *StreamControllerService) readCommands(conn *websocket.Conn, subscription *realtime.Subscription, replies chan<- realtime.Message, stop <-chan struct{}) {
+
+	readWait := 2 * streamController.Config.KeepAlive
+
+	conn.SetReadLimit(maxCommandSize)
+	_ = conn.SetReadDeadline(time.Now().Add(readWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(readWait))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		reply := applyCommand(data, subscription)
+
+		select {
+		case replies <- reply:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func applyCommand(data []byte, subscription *realtime.Subscription) realtime.Message {
+
+	var command request.StreamCommand
+
+	if err := json.Unmarshal(data, &command); err != nil {
+		return newErrorMessage(err.Error())
+	}
+
+	if err := binding.Validator.ValidateStruct(&command); err != nil {
+		return newErrorMessage(validationErrors(err))
+	}
+
+	topic := realtime.PhotoCommentsTopic(command.PhotoID)
+	messageType := realtime.MessageTypeUnsubscribed
+
+	if command.Action == "subscribe" {
+		// the topic of the user counts too
+		if subscription.Len() > maxPhotoSubscriptions {
+			return newErrorMessage("too many photos followed, unsubscribe from one first")
+		}
+
+		subscription.Add(topic)
+		messageType = realtime.MessageTypeSubscribed
+	} else {
+		subscription.Remove(topic)
+	}
+
+	message, _ := realtime.NewMessage(topic, messageType, response.StreamSubscriptionResponse{PhotoID: command.PhotoID})
+
+	return message
+}
+
+// subscribe opens a subscription to the notifications of userID and the new comments of photoIDs
+func (streamController *StreamControllerService) subscribe(userID uint, photoIDs []uint) (*realtime.Subscription, error) {
+
+	topics := []string{realtime.UserTopic(userID)}
+	for _, photoID := range photoIDs {
+		topics = append(topics, realtime.PhotoCommentsTopic(photoID))
+	}
+
+	return streamController.Hub.Subscribe(topics...)
+}
+
+func newErrorMessage(problems interface{}) realtime.Message {
+	message, _ := realtime.NewMessage("", realtime.MessageTypeError, response.ErrorResponse{
+		Code:   http.StatusBadRequest,
+		Status: "Bad Request",
+		Errors: problems,
+	})
+
+	return message
+}
+
+func writeClose(conn *websocket.Conn, code int, reason string) {
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(writeWait))
+}
+
+// abortUnavailable answers the streams opened while the server shuts down
+func abortUnavailable(c *gin.Context, err error) {
+
+	c.AbortWithStatusJSON(http.StatusServiceUnavailable, response.ErrorResponse{
+		Code:   http.StatusServiceUnavailable,
+		Status: "Service Unavailable",
+		Errors: err.Error(),
+	})
+}
+
+func validationErrors(err error) interface{} {
+
+	validationError, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err.Error()
+	}
+
+	fieldErrorResponse := make(map[string]interface{})
+
+	for _, v := range validationError {
+		fieldErrorResponse[strings.ToLower(v.Field())] = helpers.GetValidationErrorMsg(v)
+	}
+
+	return fieldErrorResponse
+}
+
+func abortWithBindError(c *gin.Context, err error) {
+
+	c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+		Code:   http.StatusBadRequest,
+		Status: "Bad Request",
+		Errors: validationErrors(err),
+	})
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"mygram-api/auth"
+	"mygram-api/config"
+	"mygram-api/realtime"
+)
+
+// revocableSessions revokes every session once revoked is set
+type revocableSessions struct {
+	revoked atomic.Bool
+}
+
+func (sessions *revocableSessions) IsSessionRevoked(sessionID string) bool {
+	return sessions.revoked.Load()
+}
+
+func newStreamServer(t *testing.T, sessions auth.SessionChecker) *httptest.Server {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	hub := realtime.NewHub(realtime.NewLocalBroker())
+	hub.Start()
+	t.Cleanup(func() { _ = hub.Close() })
+
+	streamController := NewStreamController(hub, sessions, config.RealtimeConfig{
+		KeepAlive:    time.Minute,
+		SessionCheck: 10 * time.Millisecond,
+	})
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		auth.SetPrincipal(c, auth.Principal{UserID: 1, SessionID: "session", ExpiresAt: time.Now().Add(time.Hour)})
+	})
+	router.GET("/stream", streamController.Stream)
+	router.GET("/ws", streamController.WebSocket)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestStreamEndsWhenTheSessionIsRevoked(t *testing.T) {
+	sessions := &revocableSessions{}
+	server := newStreamServer(t, sessions)
+
+	res, err := http.Get(server.URL + "/stream")
+	if err != nil {
+		t.Fatalf("opening the stream: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", res.StatusCode)
+	}
+
+	sessions.revoked.Store(true)
+
+	ended := make(chan error, 1)
+	go func() {
+		_, err := res.Body.Read(make([]byte, 1))
+		ended <- err
+	}()
+
+	select {
+	case err := <-ended:
+		if err == nil {
+			t.Fatal("stream sent data instead of ending")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream still open after the session was revoked")
+	}
+}
+
+func TestWebSocketClosesWhenTheSessionIsRevoked(t *testing.T) {
+	sessions := &revocableSessions{}
+	server := newStreamServer(t, sessions)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("opening the websocket: %v", err)
+	}
+	defer conn.Close()
+
+	sessions.revoked.Store(true)
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+		t.Fatalf("read error = %v, want a policy violation close", err)
+	}
+}
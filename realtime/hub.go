@@ -0,0 +1,248 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+const (
+	MessageTypeNotification = "notification"
+	MessageTypeComment      = "comment"
+
+	// acknowledgements and errors answering the commands of a WebSocket client
+	MessageTypeSubscribed   = "subscribed"
+	MessageTypeUnsubscribed = "unsubscribed"
+	MessageTypeError        = "error"
+
+	// subscriptionBuffer is how many messages may wait for a slow client
+	// before it is disconnected rather than holding up the others
+	subscriptionBuffer = 64
+)
+
+var ErrHubClosed = errors.New("realtime hub is closed")
+
+// Message is an event pushed to the clients subscribed to its topic
+type Message struct {
+	Topic string          `json:"topic"`
+	Type  string          `json:"type"`
+	Data  json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+// NewMessage encodes data as the payload of a message
+func NewMessage(topic, messageType string, data any) (message Message, err error) {
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+
+	return Message{Topic: topic, Type: messageType, Data: encoded}, nil
+}
+
+// UserTopic carries the notifications of a user
+func UserTopic(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// PhotoCommentsTopic carries the new comments of a photo
+func PhotoCommentsTopic(photoID uint) string {
+	return fmt.Sprintf("photo:%d:comments", photoID)
+}
+
+// Hub delivers the messages received from the broker to the subscriptions of
+// their topic in this process
+type Hub struct {
+	Broker Broker
+
+	mutex  sync.RWMutex
+	topics map[string]map[*Subscription]struct{}
+	closed bool
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewHub(broker Broker) *Hub {
+	return &Hub{
+		Broker: broker,
+		topics: make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// Start listens to the broker in the background until Close
+func (hub *Hub) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	hub.cancel = cancel
+
+	hub.wg.Add(1)
+
+	go func() {
+		defer hub.wg.Done()
+
+		if err := hub.Broker.Listen(ctx, hub.dispatch); err != nil {
+			log.Printf("realtime broker stopped: %v", err)
+		}
+	}()
+}
+
+// Publish sends data to the subscribers of topic on every instance
+func (hub *Hub) Publish(topic, messageType string, data any) (err error) {
+
+	message, err := NewMessage(topic, messageType, data)
+	if err != nil {
+		return
+	}
+
+	return hub.Broker.Publish(message)
+}
+
+// Subscribe opens a subscription to the given topics
+func (hub *Hub) Subscribe(topics ...string) (*Subscription, error) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	if hub.closed {
+		return nil, ErrHubClosed
+	}
+
+	subscription := &Subscription{
+		hub:      hub,
+		messages: make(chan Message, subscriptionBuffer),
+		topics:   make(map[string]struct{}, len(topics)),
+	}
+
+	for _, topic := range topics {
+		hub.add(subscription, topic)
+	}
+
+	return subscription, nil
+}
+
+// Close ends every subscription, which disconnects the clients, and stops
+// listening to the broker
+func (hub *Hub) Close() error {
+	hub.mutex.Lock()
+	hub.closed = true
+
+	for _, subscriptions := range hub.topics {
+		for subscription := range subscriptions {
+			hub.remove(subscription)
+		}
+	}
+	hub.mutex.Unlock()
+
+	if hub.cancel != nil {
+		hub.cancel()
+	}
+
+	hub.wg.Wait()
+
+	return nil
+}
+
+func (hub *Hub) dispatch(message Message) {
+	var slow []*Subscription
+
+	hub.mutex.RLock()
+	for subscription := range hub.topics[message.Topic] {
+		select {
+		case subscription.messages <- message:
+		default:
+			slow = append(slow, subscription)
+		}
+	}
+	hub.mutex.RUnlock()
+
+	for _, subscription := range slow {
+		subscription.Close()
+	}
+}
+
+// add and remove expect the hub mutex to be held
+func (hub *Hub) add(subscription *Subscription, topic string) {
+	if hub.topics[topic] == nil {
+		hub.topics[topic] = make(map[*Subscription]struct{})
+	}
+
+	hub.topics[topic][subscription] = struct{}{}
+	subscription.topics[topic] = struct{}{}
+}
+
+func (hub *Hub) remove(subscription *Subscription) {
+	if subscription.closed {
+		return
+	}
+	subscription.closed = true
+
+	for topic := range subscription.topics {
+		delete(hub.topics[topic], subscription)
+
+		if len(hub.topics[topic]) == 0 {
+			delete(hub.topics, topic)
+		}
+	}
+
+	close(subscription.messages)
+}
+
+// Subscription receives the messages of its topics until it is closed, by
+// the client, by the hub shutting down or for falling behind
+type Subscription struct {
+	hub      *Hub
+	messages chan Message
+
+	// topics and closed are guarded by the mutex of the hub
+	topics map[string]struct{}
+	closed bool
+}
+
+// Messages is closed along with the subscription
+func (subscription *Subscription) Messages() <-chan Message {
+	return subscription.messages
+}
+
+// Add subscribes to one more topic
+func (subscription *Subscription) Add(topic string) {
+	subscription.hub.mutex.Lock()
+	defer subscription.hub.mutex.Unlock()
+
+	if !subscription.closed {
+		subscription.hub.add(subscription, topic)
+	}
+}
+
+// Remove unsubscribes from a topic
+func (subscription *Subscription) Remove(topic string) {
+	subscription.hub.mutex.Lock()
+	defer subscription.hub.mutex.Unlock()
+
+	if subscription.closed {
+		return
+	}
+
+	delete(subscription.topics, topic)
+	delete(subscription.hub.topics[topic], subscription)
+
+	if len(subscription.hub.topics[topic]) == 0 {
+		delete(subscription.hub.topics, topic)
+	}
+}
+
+// Len returns the number of topics subscribed to
+func (subscription *Subscription) Len() int {
+	subscription.hub.mutex.RLock()
+	defer subscription.hub.mutex.RUnlock()
+
+	return len(subscription.topics)
+}
+
+func (subscription *Subscription) Close() {
+	subscription.hub.mutex.Lock()
+	defer subscription.hub.mutex.Unlock()
+
+	subscription.hub.remove(subscription)
+}
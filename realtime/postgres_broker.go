@@ -0,0 +1,113 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	postgresChannel = "mygram_realtime"
+
+	// maxNotifyPayload stays under the 8000 bytes postgres accepts in a NOTIFY
+	maxNotifyPayload = 7900
+
+	maxReconnectDelay = 30 * time.Second
+)
+
+var ErrMessageTooLarge = errors.New("realtime message is too large to publish")
+
+// PostgresBroker fans the messages out to every instance sharing the database
+// through NOTIFY, each listening on a dedicated connection. Messages published
+// while an instance reconnects are not replayed to it.
+type PostgresBroker struct {
+	DB  *gorm.DB
+	DSN string
+}
+
+func NewPostgresBroker(db *gorm.DB, dsn string) *PostgresBroker {
+	return &PostgresBroker{DB: db, DSN: dsn}
+}
+
+func (postgresBroker *PostgresBroker) Publish(message Message) (err error) {
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+
+	if len(payload) > maxNotifyPayload {
+		return ErrMessageTooLarge
+	}
+
+	if err = postgresBroker.DB.Exec("SELECT pg_notify(?, ?)", postgresChannel, string(payload)).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// Listen keeps a LISTEN connection open, reconnecting with a growing delay
+// whenever it is lost, until ctx is done
+func (postgresBroker *PostgresBroker) Listen(ctx context.Context, deliver func(Message)) (err error) {
+
+	delay := time.Second
+
+	for {
+		connected, listenErr := postgresBroker.listen(ctx, deliver)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if connected {
+			delay = time.Second
+		}
+
+		log.Printf("realtime: listening on postgres: %v, reconnecting in %s", listenErr, delay)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+func (postgresBroker *PostgresBroker) listen(ctx context.Context, deliver func(Message)) (connected bool, err error) {
+
+	conn, err := pgx.Connect(ctx, postgresBroker.DSN)
+	if err != nil {
+		return
+	}
+	defer conn.Close(context.Background())
+
+	if _, err = conn.Exec(ctx, "LISTEN "+postgresChannel); err != nil {
+		return
+	}
+
+	connected = true
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return connected, err
+		}
+
+		var message Message
+		if err := json.Unmarshal([]byte(notification.Payload), &message); err != nil {
+			log.Printf("realtime: decoding notification: %v", err)
+			continue
+		}
+
+		deliver(message)
+	}
+}
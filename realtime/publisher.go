@@ -0,0 +1,37 @@
+package realtime
+
+import (
+	"log"
+
+	"mygram-api/models/domain"
+	"mygram-api/models/response"
+)
+
+// Publisher pushes the notifications and comments created by the services to
+// the clients subscribed to them, best-effort: a message that cannot be
+// published is logged and does not fail the action that caused it
+type Publisher struct {
+	Hub *Hub
+}
+
+func NewPublisher(hub *Hub) *Publisher {
+	return &Publisher{Hub: hub}
+}
+
+// NotificationsCreated pushes notifications loaded with their actor to their users
+func (publisher *Publisher) NotificationsCreated(notifications []domain.Notification) {
+
+	for _, notification := range notifications {
+		if err := publisher.Hub.Publish(UserTopic(notification.UserID), MessageTypeNotification, response.NewNotificationResponse(notification)); err != nil {
+			log.Printf("publishing notification %d: %v", notification.ID, err)
+		}
+	}
+}
+
+// CommentCreated pushes a comment loaded with its author to the subscribers of its photo
+func (publisher *Publisher) CommentCreated(comment domain.Comment) {
+
+	if err := publisher.Hub.Publish(PhotoCommentsTopic(comment.PhotoID), MessageTypeComment, response.NewCommentThreadResponse(comment, nil)); err != nil {
+		log.Printf("publishing comment %d: %v", comment.ID, err)
+	}
+}
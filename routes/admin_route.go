@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"mygram-api/admins/controller"
+	"mygram-api/app"
+	"mygram-api/auth"
+	"mygram-api/models/domain"
+)
+
+func AdminRoute(router *gin.Engine, container *app.Container) {
+
+	controllerAdmin := controller.NewAdminController(container.UserService, container.PhotoService, container.CommentService, container.SocialMediaService)
+
+	onlyAdmins := auth.RequireRole(domain.RoleAdmin)
+
+	adminRouter := router.Group("/admin", auth.Authentication(container.Config.JWT.SecretKey, container.UserService), auth.RequireRole(domain.RoleModerator, domain.RoleAdmin))
+	{
+		adminRouter.GET("/users", controllerAdmin.GetUsers)
+		adminRouter.POST("/users/:id/ban", onlyAdmins, controllerAdmin.BanUser)
+		adminRouter.POST("/users/:id/unban", onlyAdmins, controllerAdmin.UnbanUser)
+		adminRouter.PUT("/users/:id/role", onlyAdmins, controllerAdmin.SetUserRole)
+		adminRouter.DELETE("/photos/:id", controllerAdmin.DeletePhoto)
+		adminRouter.DELETE("/comments/:id", controllerAdmin.DeleteComment)
+		adminRouter.DELETE("/social-media/:id", controllerAdmin.DeleteSocialMedia)
+	}
+
+}
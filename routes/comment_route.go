@@ -3,35 +3,29 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 
-	"mygram-api/database"
+	"mygram-api/app"
+	"mygram-api/auth"
 	"mygram-api/comments/controller"
 	"mygram-api/comments/middlewares"
-	"mygram-api/comments/repository"
-	photoRepository "mygram-api/photos/repository"
-	photoservice "mygram-api/photos/service"
-	"mygram-api/comments/service"
 )
 
+func CommentRoute(router *gin.Engine, container *app.Container) {
 
-func CommentRoute(router *gin.Engine) {
+	controllerComment := controller.NewCommentController(container.CommentService, container.PhotoService)
 
-	db := database.StartDB()
-
-	repositoryPhoto := photoRepository.NewPhotoRepository(db)
-	servicePhoto := photoservice.PhotoService(repositoryPhoto)
-
-	repositoryComment := repository.NewCommentRepository(db)
-	serviceComment := service.NewCommentService(repositoryComment)
-
-	controllerComment := controller.NewCommentController(serviceComment, servicePhoto)
-
-	commentRouter := router.Group("/comments", middlewares.Authentication())
+	commentRouter := router.Group("/comments", auth.Authentication(container.Config.JWT.SecretKey, container.UserService))
 	{
 		commentRouter.POST("/", controllerComment.Create)
 		commentRouter.GET("/", controllerComment.GetAll)
-		commentRouter.GET("/:commentId", middlewares.Authorization(serviceComment), controllerComment.GetOne)
-		commentRouter.PUT("/:commentId", middlewares.Authorization(serviceComment), controllerComment.Update)
-		commentRouter.DELETE("/:commentId", middlewares.Authorization(serviceComment), controllerComment.Delete)
+		commentRouter.GET("/:commentId", middlewares.Authorization(container.CommentService), controllerComment.GetOne)
+		commentRouter.PUT("/:commentId", middlewares.Authorization(container.CommentService), controllerComment.Update)
+		commentRouter.DELETE("/:commentId", middlewares.Authorization(container.CommentService), controllerComment.Delete)
+	}
+
+	photoCommentRouter := router.Group("/photos/:id/comments", auth.Authentication(container.Config.JWT.SecretKey, container.UserService))
+	{
+		photoCommentRouter.POST("", controllerComment.CreateOnPhoto)
+		photoCommentRouter.GET("", controllerComment.GetThread)
 	}
 
-}
\ No newline at end of file
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"mygram-api/app"
+	"mygram-api/auth"
+	"mygram-api/feeds/controller"
+)
+
+func FeedRoute(router *gin.Engine, container *app.Container) {
+
+	controllerFeed := controller.NewFeedController(container.FeedService, container.LikeService)
+
+	feedRouter := router.Group("/feed", auth.Authentication(container.Config.JWT.SecretKey, container.UserService))
+	{
+		feedRouter.GET("", controllerFeed.GetFeed)
+	}
+
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"mygram-api/app"
+	"mygram-api/auth"
+	"mygram-api/follows/controller"
+)
+
+func FollowRoute(router *gin.Engine, container *app.Container) {
+
+	controllerFollow := controller.NewFollowController(container.FollowService)
+
+	followRouter := router.Group("/users/:username", auth.Authentication(container.Config.JWT.SecretKey, container.UserService))
+	{
+		followRouter.POST("/follow", controllerFollow.Follow)
+		followRouter.DELETE("/follow", controllerFollow.Unfollow)
+		followRouter.GET("/followers", controllerFollow.GetFollowers)
+		followRouter.GET("/following", controllerFollow.GetFollowing)
+	}
+
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"mygram-api/app"
+	"mygram-api/auth"
+	"mygram-api/likes/controller"
+)
+
+func LikeRoute(router *gin.Engine, container *app.Container) {
+
+	controllerLike := controller.NewLikeController(container.LikeService, container.PhotoService)
+
+	likeRouter := router.Group("/photos/:id", auth.Authentication(container.Config.JWT.SecretKey, container.UserService))
+	{
+		likeRouter.POST("/like", controllerLike.Like)
+		likeRouter.DELETE("/like", controllerLike.Unlike)
+		likeRouter.GET("/likes", controllerLike.GetAll)
+	}
+
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"mygram-api/app"
+	"mygram-api/auth"
+	"mygram-api/notifications/controller"
+)
+
+func NotificationRoute(router *gin.Engine, container *app.Container) {
+
+	controllerNotification := controller.NewNotificationController(container.NotificationService)
+
+	notificationRouter := router.Group("/notifications", auth.Authentication(container.Config.JWT.SecretKey, container.UserService))
+	{
+		notificationRouter.GET("", controllerNotification.GetAll)
+		notificationRouter.GET("/unread-count", controllerNotification.GetUnreadCount)
+		notificationRouter.POST("/read", controllerNotification.MarkRead)
+	}
+
+}
@@ -1,30 +1,34 @@
 package routes
 
 import (
+	"net/url"
+
 	"github.com/gin-gonic/gin"
 
-	"mygram-api/database"
+	"mygram-api/app"
+	"mygram-api/auth"
+	"mygram-api/config"
 	"mygram-api/photos/controller"
 	"mygram-api/photos/middlewares"
-	"mygram-api/photos/repository"
-	"mygram-api/photos/service"
 )
 
-func PhotoRoute(router *gin.Engine) {
+func PhotoRoute(router *gin.Engine, container *app.Container) {
 
-	db := database.StartDB()
+	controllerPhoto := controller.NewPhotoController(container.PhotoService, container.LikeService, container.Config.Storage.MaxUploadSize)
 
-	repositoryPhoto := repository.NewPhotoRepository(db)
-	servicePhoto := service.NewPhotoService(repositoryPhoto)
-	controllerPhoto := controller.NewPhotoController(servicePhoto)
+	// uploads kept on the local filesystem are served by the api itself
+	if storageConfig := container.Config.Storage; storageConfig.Driver == config.StorageDriverLocal {
+		publicURL, _ := url.Parse(storageConfig.PublicBaseURL())
+		router.Static(publicURL.Path, storageConfig.Local.Dir)
+	}
 
-	photoRouter := router.Group("/photos", middlewares.Authentication())
+	photoRouter := router.Group("/photos", auth.Authentication(container.Config.JWT.SecretKey, container.UserService))
 	{
 		photoRouter.POST("/", controllerPhoto.Create)
 		photoRouter.GET("/", controllerPhoto.GetAll)
 		photoRouter.GET("/:id", controllerPhoto.GetOne)
-		photoRouter.PUT("/:id", middlewares.Authorization(servicePhoto), controllerPhoto.Update)
-		photoRouter.DELETE("/:id", middlewares.Authorization(servicePhoto), controllerPhoto.Delete)
+		photoRouter.PUT("/:id", middlewares.Authorization(container.PhotoService), controllerPhoto.Update)
+		photoRouter.DELETE("/:id", middlewares.Authorization(container.PhotoService), controllerPhoto.Delete)
 	}
 
-}
\ No newline at end of file
+}
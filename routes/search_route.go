@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"mygram-api/app"
+	"mygram-api/auth"
+	"mygram-api/search/controller"
+)
+
+func SearchRoute(router *gin.Engine, container *app.Container) {
+
+	controllerSearch := controller.NewSearchController(container.SearchService, container.LikeService)
+
+	searchRouter := router.Group("/search", auth.Authentication(container.Config.JWT.SecretKey, container.UserService))
+	{
+		searchRouter.GET("", controllerSearch.GetAll)
+	}
+
+}
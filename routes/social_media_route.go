@@ -3,28 +3,23 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 
-	"mygram-api/database"
+	"mygram-api/app"
+	"mygram-api/auth"
 	"mygram-api/social_medias/controller"
 	"mygram-api/social_medias/middlewares"
-	"mygram-api/social_medias/repository"
-	"mygram-api/social_medias/service"
 )
 
-func SocialMediaRoute(router *gin.Engine) {
+func SocialMediaRoute(router *gin.Engine, container *app.Container) {
 
-	db := database.StartDB()
+	controllerSocialMedia := controller.NewSocialMediaController(container.SocialMediaService)
 
-	repositorySocialMedia := repository.NewSocialMediaRepository(db)
-	serviceSoacialMedia := service.NewSocialMediaService(repositorySocialMedia)
-	controllerSocialMedia := controller.NewSocialMediaController(serviceSoacialMedia)
-
-	socialMedia := router.Group("/social-media", middlewares.Authentication())
+	socialMedia := router.Group("/social-media", auth.Authentication(container.Config.JWT.SecretKey, container.UserService))
 	{
 		socialMedia.GET("/", controllerSocialMedia.GetAll)
 		socialMedia.GET("/:id", controllerSocialMedia.GetOne)
 		socialMedia.POST("/", controllerSocialMedia.Create)
-		socialMedia.PUT("/:id", middlewares.Authorization(serviceSoacialMedia), controllerSocialMedia.Update)
-		socialMedia.DELETE("/:id", middlewares.Authorization(serviceSoacialMedia), controllerSocialMedia.Delete)
+		socialMedia.PUT("/:id", middlewares.Authorization(container.SocialMediaService), controllerSocialMedia.Update)
+		socialMedia.DELETE("/:id", middlewares.Authorization(container.SocialMediaService), controllerSocialMedia.Delete)
 	}
 
-}
\ No newline at end of file
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"mygram-api/app"
+	"mygram-api/auth"
+	"mygram-api/realtime/controller"
+)
+
+func StreamRoute(router *gin.Engine, container *app.Container) {
+
+	controllerStream := controller.NewStreamController(container.Hub, container.UserService, container.Config.Realtime)
+
+	streamRouter := router.Group("", auth.QueryToken(), auth.Authentication(container.Config.JWT.SecretKey, container.UserService))
+	{
+		streamRouter.GET("/stream", controllerStream.Stream)
+		streamRouter.GET("/ws", controllerStream.WebSocket)
+	}
+
+}
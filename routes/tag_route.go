@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"mygram-api/app"
+	"mygram-api/auth"
+	"mygram-api/tags/controller"
+)
+
+func TagRoute(router *gin.Engine, container *app.Container) {
+
+	controllerTag := controller.NewTagController(container.TagService, container.LikeService)
+
+	tagRouter := router.Group("/tags", auth.Authentication(container.Config.JWT.SecretKey, container.UserService))
+	{
+		tagRouter.GET("", controllerTag.GetAll)
+		tagRouter.GET("/:name/photos", controllerTag.GetPhotos)
+	}
+
+}
@@ -3,24 +3,41 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 
-	"mygram-api/database"
+	"mygram-api/app"
+	"mygram-api/auth"
 	"mygram-api/users/controller"
-	"mygram-api/users/repository"
-	"mygram-api/users/service"
 )
 
-func UserRoute(router *gin.Engine) {
-	
-	db := database.StartDB()
+func UserRoute(router *gin.Engine, container *app.Container) {
 
-	repositoryUser := repository.NewUserRepository(db)
-	serviceUser := service.NewUserService(repositoryUser)
-	controllerUser := controller.NewUserController(serviceUser)
+	controllerUser := controller.NewUserController(container.UserService, container.ProfileService, container.LikeService, container.AccountService)
 
 	userRouter := router.Group("/users")
 	{
 		userRouter.POST("/register", controllerUser.Register)
 		userRouter.POST("/login", controllerUser.Login)
+		userRouter.POST("/refresh", controllerUser.Refresh)
+		userRouter.POST("/logout", controllerUser.Logout)
+		userRouter.POST("/verify", controllerUser.VerifyEmail)
+		userRouter.POST("/verify/resend", controllerUser.ResendVerification)
+		userRouter.POST("/forgot-password", controllerUser.ForgotPassword)
+		userRouter.POST("/reset-password", controllerUser.ResetPassword)
 	}
 
-}
\ No newline at end of file
+	authentication := auth.Authentication(container.Config.JWT.SecretKey, container.UserService)
+
+	meRouter := userRouter.Group("/me", authentication)
+	{
+		meRouter.GET("", controllerUser.GetProfile)
+		meRouter.PUT("", controllerUser.UpdateProfile)
+		meRouter.PUT("/password", controllerUser.ChangePassword)
+		meRouter.DELETE("", controllerUser.DeleteAccount)
+	}
+
+	profileRouter := userRouter.Group("/:username", authentication)
+	{
+		profileRouter.GET("", controllerUser.GetPublicProfile)
+		profileRouter.GET("/photos", controllerUser.GetPhotos)
+	}
+
+}
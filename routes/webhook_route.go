@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"mygram-api/app"
+	"mygram-api/auth"
+	"mygram-api/webhooks/controller"
+	"mygram-api/webhooks/middlewares"
+)
+
+func WebhookRoute(router *gin.Engine, container *app.Container) {
+
+	controllerWebhook := controller.NewWebhookController(container.WebhookService)
+
+	webhookRouter := router.Group("/webhooks", auth.Authentication(container.Config.JWT.SecretKey, container.UserService))
+	{
+		webhookRouter.POST("", controllerWebhook.Create)
+		webhookRouter.GET("", controllerWebhook.GetAll)
+		webhookRouter.GET("/:id", middlewares.Authorization(container.WebhookService), controllerWebhook.GetOne)
+		webhookRouter.PUT("/:id", middlewares.Authorization(container.WebhookService), controllerWebhook.Update)
+		webhookRouter.DELETE("/:id", middlewares.Authorization(container.WebhookService), controllerWebhook.Delete)
+		webhookRouter.GET("/:id/deliveries", middlewares.Authorization(container.WebhookService), controllerWebhook.GetDeliveries)
+		webhookRouter.POST("/:id/deliveries/:deliveryId/redeliver", middlewares.Authorization(container.WebhookService), controllerWebhook.Redeliver)
+	}
+
+}
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"mygram-api/auth"
+	"mygram-api/database"
+	"mygram-api/helpers"
+	likeService "mygram-api/likes/service"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+	"mygram-api/models/response"
+	"mygram-api/search/service"
+)
+
+type SearchController interface {
+	GetAll(c *gin.Context)
+}
+
+type SearchControllerService struct {
+	SearchService service.SearchService
+	LikeService   likeService.LikeService
+}
+
+func NewSearchController(searchService service.SearchService, likeService likeService.LikeService) SearchController {
+	return &SearchControllerService{SearchService: searchService, LikeService: likeService}
+}
+
+// GetAll search godoc
+// @Summary Search photos, comments or users
+// @Description Full-text search over photo titles and captions, comment messages or usernames.
+// @Description The query supports "quoted phrases", or and -excluded words.
+// @Description Results sorted by rank are paged by page number, results sorted by creation time also by cursor.
+// @Description The highlight is HTML escaped with the matched words wrapped in <mark>.
+// @Tags search
+// @Security Bearer
+// @Produce json
+// @Param q query string true "Search query"
+// @Param type query string false "What to search" Enums(photos, comments, users) default(photos)
+// @Param page query int false "Page number, 1 by default when sorted by rank, otherwise without it the first keyset page is returned. Ignored when cursor is set"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "Cursor of the next page from a previous response, not used when sorted by rank"
+// @Param sort query string false "Sort order" Enums(rank, -created_at, created_at) default(rank)
+// @Param user_id query int false "Only photos or comments of this user"
+// @Param created_after query string false "Only items created after this RFC 3339 time"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /search [get]
+func (searchController *SearchControllerService) GetAll(c *gin.Context) {
+
+	var search request.SearchRequest
+
+	if err := c.ShouldBindQuery(&search); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	search.Normalize()
+
+	var (
+		data interface{}
+		page database.Page
+	)
+
+	switch search.Type {
+	case request.SearchTypeComments:
+		hits, comments, commentPage, err := searchController.SearchService.SearchComments(search)
+		if err != nil {
+			abortWithSearchError(c, err)
+			return
+		}
+
+		commentsResponse := []response.SearchCommentResponse{}
+		for _, hit := range hits {
+			comment, ok := comments[hit.ID]
+			if !ok {
+				continue
+			}
+
+			commentsResponse = append(commentsResponse, response.SearchCommentResponse{
+				CommentGetAllResponse: response.NewCommentGetAllResponse(comment),
+				Rank:                  hit.Rank,
+				Highlight:             response.NewSearchHighlight(hit.Highlight),
+			})
+		}
+
+		data, page = commentsResponse, commentPage
+	case request.SearchTypeUsers:
+		hits, users, userPage, err := searchController.SearchService.SearchUsers(search)
+		if err != nil {
+			abortWithSearchError(c, err)
+			return
+		}
+
+		usersResponse := []response.SearchUserResponse{}
+		for _, hit := range hits {
+			user, ok := users[hit.ID]
+			if !ok {
+				continue
+			}
+
+			usersResponse = append(usersResponse, response.SearchUserResponse{
+				ID:        user.ID,
+				Username:  user.Username,
+				CreatedAt: user.CreatedAt,
+				Rank:      hit.Rank,
+				Highlight: response.NewSearchHighlight(hit.Highlight),
+			})
+		}
+
+		data, page = usersResponse, userPage
+	default:
+		hits, photos, photoPage, err := searchController.SearchService.SearchPhotos(search)
+		if err != nil {
+			abortWithSearchError(c, err)
+			return
+		}
+
+		found := make([]domain.Photo, 0, len(photos))
+		for _, photo := range photos {
+			found = append(found, photo)
+		}
+
+		likeSummaries, err := searchController.LikeService.GetSummaries(found, auth.MustGetPrincipal(c).UserID)
+		if err != nil {
+			abortWithSearchError(c, err)
+			return
+		}
+
+		photosResponse := []response.SearchPhotoResponse{}
+		for _, hit := range hits {
+			photo, ok := photos[hit.ID]
+			if !ok {
+				continue
+			}
+
+			photosResponse = append(photosResponse, response.SearchPhotoResponse{
+				PhotoGetAllResponse: response.NewPhotoGetAllResponse(photo, likeSummaries[photo.ID]),
+				Rank:                hit.Rank,
+				Highlight:           response.NewSearchHighlight(hit.Highlight),
+			})
+		}
+
+		data, page = photosResponse, photoPage
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data:       data,
+		Pagination: response.NewListPaginationResponse(search.ListRequest(), page.Total, page.NextCursor),
+	})
+}
+
+func abortWithSearchError(c *gin.Context, err error) {
+	c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+		Code:   http.StatusBadRequest,
+		Status: "Bad Request",
+		Errors: err.Error(),
+	})
+}
+
+func abortWithBindError(c *gin.Context, err error) {
+
+	validationError, ok := err.(validator.ValidationErrors)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	fieldErrorResponse := make(map[string]interface{})
+
+	for _, v := range validationError {
+		fieldErrorResponse[strings.ToLower(v.Field())] = helpers.GetValidationErrorMsg(v)
+	}
+
+	c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+		Code:   http.StatusBadRequest,
+		Status: "Bad Request",
+		Errors: fieldErrorResponse,
+	})
+}
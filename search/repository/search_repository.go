@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"mygram-api/database"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+)
+
+// headlineOptions keeps highlights short enough for a result list
+var headlineOptions = fmt.Sprintf("StartSel=\"%s\", StopSel=\"%s\", MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=\" ... \"", domain.HighlightStart, domain.HighlightStop)
+
+type SearchRepository interface {
+	SearchPhotos(q string, list request.ListRequest) (hits []domain.SearchHit, page database.Page, err error)
+	SearchComments(q string, list request.ListRequest) (hits []domain.SearchHit, page database.Page, err error)
+	SearchUsers(q string, list request.ListRequest) (hits []domain.SearchHit, page database.Page, err error)
+}
+
+type SearchRepositoryDB struct {
+	DB *gorm.DB
+}
+
+func NewSearchRepository(db *gorm.DB) SearchRepository {
+	return &SearchRepositoryDB{DB: db}
+}
+
+// SearchPhotos matches titles and captions, a match in the title ranks higher
+func (searchRepository *SearchRepositoryDB) SearchPhotos(q string, list request.ListRequest) (hits []domain.SearchHit, page database.Page, err error) {
+
+	query := searchRepository.query("photos", "concat_ws(' ', title, caption)", q).
+		Where("NOT EXISTS (SELECT 1 FROM users WHERE users.id = photos.user_id AND users.banned_at IS NOT NULL)")
+
+	return search(query, list)
+}
+
+func (searchRepository *SearchRepositoryDB) SearchComments(q string, list request.ListRequest) (hits []domain.SearchHit, page database.Page, err error) {
+
+	query := searchRepository.query("comments", "message", q).
+		Where("NOT EXISTS (SELECT 1 FROM users WHERE users.id = comments.user_id AND users.banned_at IS NOT NULL)")
+
+	return search(query, list)
+}
+
+func (searchRepository *SearchRepositoryDB) SearchUsers(q string, list request.ListRequest) (hits []domain.SearchHit, page database.Page, err error) {
+
+	// users have no owner to filter on
+	list.UserID = 0
+
+	query := searchRepository.query("users", "username", q).Where("banned_at IS NULL")
+
+	return search(query, list)
+}
+
+// query selects the rows of table matching q, ranked, with a highlight of text.
+// q is parsed like a web search: words, "quoted phrases", or and -excluded words.
+func (searchRepository *SearchRepositoryDB) query(table, text, q string) *gorm.DB {
+	return searchRepository.DB.
+		Table(fmt.Sprintf("%s, websearch_to_tsquery('simple', ?) AS query", table), q).
+		Select(fmt.Sprintf("id, created_at, ts_rank_cd(search_vector, query) AS rank, ts_headline('simple', %s, query, ?) AS highlight", text), headlineOptions).
+		Where("search_vector @@ query")
+}
+
+func search(query *gorm.DB, list request.ListRequest) (hits []domain.SearchHit, page database.Page, err error) {
+
+	if list.Sort == "" {
+		return database.PaginateByRank[domain.SearchHit](query, list)
+	}
+
+	return database.Paginate(query, list, func(hit domain.SearchHit) request.Cursor {
+		return request.Cursor{CreatedAt: hit.CreatedAt, ID: hit.ID}
+	})
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	commentRepository "mygram-api/comments/repository"
+	"mygram-api/database"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+	photoRepository "mygram-api/photos/repository"
+	"mygram-api/search/repository"
+	userRepository "mygram-api/users/repository"
+)
+
+// SearchService returns the hits of a search in order, along with the matched
+// rows by id. A row deleted between both queries has a hit but no row.
+type SearchService interface {
+	SearchPhotos(search request.SearchRequest) (hits []domain.SearchHit, photos map[uint]domain.Photo, page database.Page, err error)
+	SearchComments(search request.SearchRequest) (hits []domain.SearchHit, comments map[uint]domain.Comment, page database.Page, err error)
+	SearchUsers(search request.SearchRequest) (hits []domain.SearchHit, users map[uint]domain.User, page database.Page, err error)
+}
+
+type SearchServiceRepository struct {
+	SearchRepository  repository.SearchRepository
+	PhotoRepository   photoRepository.PhotoRepository
+	CommentRepository commentRepository.CommentRepository
+	UserRepository    userRepository.UserRepository
+}
+
+func NewSearchService(searchRepository repository.SearchRepository, photoRepository photoRepository.PhotoRepository, commentRepository commentRepository.CommentRepository, userRepository userRepository.UserRepository) SearchService {
+	return &SearchServiceRepository{
+		SearchRepository:  searchRepository,
+		PhotoRepository:   photoRepository,
+		CommentRepository: commentRepository,
+		UserRepository:    userRepository,
+	}
+}
+
+func (searchService *SearchServiceRepository) SearchPhotos(search request.SearchRequest) (hits []domain.SearchHit, photos map[uint]domain.Photo, page database.Page, err error) {
+
+	if hits, page, err = searchService.SearchRepository.SearchPhotos(search.Q, search.ListRequest()); err != nil {
+		return
+	}
+
+	found, err := searchService.PhotoRepository.GetByIDs(hitIDs(hits))
+	if err != nil {
+		return
+	}
+
+	photos = make(map[uint]domain.Photo, len(found))
+	for _, photo := range found {
+		photos[photo.ID] = photo
+	}
+
+	return
+}
+
+func (searchService *SearchServiceRepository) SearchComments(search request.SearchRequest) (hits []domain.SearchHit, comments map[uint]domain.Comment, page database.Page, err error) {
+
+	if hits, page, err = searchService.SearchRepository.SearchComments(search.Q, search.ListRequest()); err != nil {
+		return
+	}
+
+	found, err := searchService.CommentRepository.GetByIDs(hitIDs(hits))
+	if err != nil {
+		return
+	}
+
+	comments = make(map[uint]domain.Comment, len(found))
+	for _, comment := range found {
+		comments[comment.ID] = comment
+	}
+
+	return
+}
+
+func (searchService *SearchServiceRepository) SearchUsers(search request.SearchRequest) (hits []domain.SearchHit, users map[uint]domain.User, page database.Page, err error) {
+
+	if hits, page, err = searchService.SearchRepository.SearchUsers(search.Q, search.ListRequest()); err != nil {
+		return
+	}
+
+	found, err := searchService.UserRepository.GetByIDs(hitIDs(hits))
+	if err != nil {
+		return
+	}
+
+	users = make(map[uint]domain.User, len(found))
+	for _, user := range found {
+		users[user.ID] = user
+	}
+
+	return
+}
+
+func hitIDs(hits []domain.SearchHit) []uint {
+	ids := make([]uint, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+
+	return ids
+}
@@ -5,10 +5,11 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 
+	"mygram-api/auth"
+	"mygram-api/database"
 	"mygram-api/helpers"
 	"mygram-api/models/domain"
 	"mygram-api/models/request"
@@ -48,8 +49,7 @@ func (socialMediaController *SocialMediaControllerService) Create(c *gin.Context
 
 	var req request.SocialMediaCreateRequest
 	
-	userData := c.MustGet("userData").(jwt.MapClaims)
-	userID := uint(userData["id"].(float64))
+	userID := auth.MustGetPrincipal(c).UserID
 
 	contentType := helpers.GetContentType(c)
 
@@ -112,10 +112,16 @@ func (socialMediaController *SocialMediaControllerService) Create(c *gin.Context
 
 // GetAll social media godoc
 // @Summary Get all social media
-// @Description Get all social media with authentication user
+// @Description Get a page of social media with authentication user
 // @Tags Social media
 // @Accept json
 // @Produce json
+// @Param page query int false "Page number for offset pagination with a total, without it the first keyset page is returned. Ignored when cursor is set"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "Cursor of the next page from a previous response"
+// @Param sort query string false "Sort order" Enums(-created_at, created_at) default(-created_at)
+// @Param user_id query int false "Only items of this user"
+// @Param created_after query string false "Only items created after this RFC 3339 time"
 // @Success 200 {object} response.SuccessResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
@@ -124,12 +130,43 @@ func (socialMediaController *SocialMediaControllerService) Create(c *gin.Context
 func (socialMediaController *SocialMediaControllerService) GetAll(c *gin.Context) {
 
 	var (
+		list                 request.ListRequest
 		socialMedias         []domain.SocialMedia
+		page                 database.Page
 		err                  error
 		socialMediasResponse = []response.SocialMediaGetAllResponse{}
 	)
 
-	if socialMedias, err = socialMediaController.SocialMediaService.GetAll(); err != nil {
+	if err := c.ShouldBindQuery(&list); err != nil {
+		validationError, ok := err.(validator.ValidationErrors)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+				Code:   http.StatusBadRequest,
+				Status: "Bad Request",
+				Errors: err.Error(),
+			})
+
+			return
+		}
+
+		fieldErrorResponse := make(map[string]interface{})
+
+		for _, v := range validationError {
+			fieldErrorResponse[strings.ToLower(v.Field())] = helpers.GetValidationErrorMsg(v)
+		}
+
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: fieldErrorResponse,
+		})
+
+		return
+	}
+
+	list.Normalize()
+
+	if socialMedias, page, err = socialMediaController.SocialMediaService.GetAll(list); err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
 			Code:   http.StatusBadRequest,
 			Status: "Bad Request",
@@ -157,6 +194,7 @@ func (socialMediaController *SocialMediaControllerService) GetAll(c *gin.Context
 		Data: gin.H{
 			"social_medias": socialMediasResponse,
 		},
+		Pagination: response.NewListPaginationResponse(list, page.Total, page.NextCursor),
 	})
 }
 
@@ -233,8 +271,6 @@ func (socialMediaController *SocialMediaControllerService) Update(c *gin.Context
 	)
 	
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
-	userData := c.MustGet("userData").(jwt.MapClaims)
-	userID := uint(userData["id"].(float64))
 	
 	contentType := helpers.GetContentType(c)
 	
@@ -271,9 +307,9 @@ func (socialMediaController *SocialMediaControllerService) Update(c *gin.Context
 
 	}
 	
+	// the owner stays the same whoever edits, a moderator included
 	socialMedia := domain.SocialMedia{
-		ID:     uint(id),
-		UserID: userID,
+		ID: uint(id),
 	}
 	
 	if req.Name != "" {
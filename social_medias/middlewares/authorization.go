@@ -4,9 +4,9 @@ import (
 	"net/http"
 	"strconv"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 
+	"mygram-api/auth"
 	"mygram-api/models/domain"
 	"mygram-api/models/response"
 	"mygram-api/social_medias/service"
@@ -20,8 +20,7 @@ func Authorization(socialMediaService service.SocialMediaService) gin.HandlerFun
 		)
 
 		socialMediaId, _ := strconv.Atoi(ctx.Param("id"))
-		userData := ctx.MustGet("userData").(jwt.MapClaims)
-		userID := uint(userData["id"].(float64))
+		principal := auth.MustGetPrincipal(ctx)
 
 		if socialMedia, err = socialMediaService.GetOne(uint(socialMediaId)); err != nil {
 			ctx.AbortWithStatusJSON(http.StatusNotFound, response.ErrorResponse{
@@ -35,7 +34,7 @@ func Authorization(socialMediaService service.SocialMediaService) gin.HandlerFun
 			return
 		}
 
-		if socialMedia.UserID != userID {
+		if !principal.CanModerate(socialMedia.UserID) {
 			ctx.AbortWithStatusJSON(http.StatusForbidden, response.ErrorResponse{
 				Code:   http.StatusForbidden,
 				Status: "Forbidden",
@@ -3,15 +3,18 @@ package repository
 import (
 	"gorm.io/gorm"
 
+	"mygram-api/database"
 	"mygram-api/models/domain"
+	"mygram-api/models/request"
 )
 
 type SocialMediaRepository interface {
 	Create(socialMedia *domain.SocialMedia) (err error)
-	GetAll() (socialMedias []domain.SocialMedia, err error)
+	GetAll(list request.ListRequest) (socialMedias []domain.SocialMedia, page database.Page, err error)
 	GetOne(id uint) (socialMedia domain.SocialMedia, err error)
 	Update(socialMedia domain.SocialMedia) (updatedSocialMedia domain.SocialMedia, err error)
 	Delete(id uint) (err error)
+	GetAllByUser(userID uint) (socialMedias []domain.SocialMedia, err error)
 }
 
 type SocialMediaRepositoryDB struct {
@@ -31,15 +34,15 @@ func (socialMediaRepository *SocialMediaRepositoryDB) Create(socialMedia *domain
 	return
 }
 
-func (socialMediaRepository *SocialMediaRepositoryDB) GetAll() (socialMedias []domain.SocialMedia, err error) {
+func (socialMediaRepository *SocialMediaRepositoryDB) GetAll(list request.ListRequest) (socialMedias []domain.SocialMedia, page database.Page, err error) {
 
-	if err = socialMediaRepository.DB.Preload("User", func(db *gorm.DB) *gorm.DB {
+	query := socialMediaRepository.DB.Model(&domain.SocialMedia{}).Preload("User", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id", "username")
-	}).Find(&socialMedias).Error; err != nil {
-		return
-	}
+	})
 
-	return
+	return database.Paginate(query, list, func(socialMedia domain.SocialMedia) request.Cursor {
+		return request.Cursor{CreatedAt: socialMedia.CreatedAt, ID: socialMedia.ID}
+	})
 }
 
 func (socialMediaRepository *SocialMediaRepositoryDB) GetOne(id uint) (socialMedia domain.SocialMedia, err error) {
@@ -59,7 +62,8 @@ func (socialMediaRepository *SocialMediaRepositoryDB) Update(socialMedia domain.
 		return
 	}
 
-	if err = socialMediaRepository.DB.Model(&updatedSocialMedia).Updates(socialMedia).Error; err != nil {
+	// the owner never moves, even when a moderator edits
+	if err = socialMediaRepository.DB.Model(&updatedSocialMedia).Omit("user_id").Updates(socialMedia).Error; err != nil {
 		return
 	}
 
@@ -72,5 +76,14 @@ func (socialMediaRepository *SocialMediaRepositoryDB) Delete(id uint) (err error
 		return
 	}
 
+	return
+}
+
+func (socialMediaRepository *SocialMediaRepositoryDB) GetAllByUser(userID uint) (socialMedias []domain.SocialMedia, err error) {
+
+	if err = socialMediaRepository.DB.Where("user_id = ?", userID).Order("id").Find(&socialMedias).Error; err != nil {
+		return
+	}
+
 	return
 }
\ No newline at end of file
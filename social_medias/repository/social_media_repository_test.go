@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"testing"
+
+	"mygram-api/database/dbtest"
+	"mygram-api/models/domain"
+)
+
+func TestUpdateKeepsTheOwner(t *testing.T) {
+	db := dbtest.Open(t)
+
+	owner := domain.User{Username: "owner", Email: "owner@example.com", Password: "secret", Age: 20}
+	moderator := domain.User{Username: "moderator", Email: "moderator@example.com", Password: "secret", Age: 20, Role: domain.RoleModerator}
+
+	for _, user := range []*domain.User{&owner, &moderator} {
+		if err := db.Create(user).Error; err != nil {
+			t.Fatalf("creating user: %v", err)
+		}
+	}
+
+	repository := NewSocialMediaRepository(db)
+
+	socialMedia := domain.SocialMedia{Name: "github", SocialMediaUrl: "https://github.com/owner", UserID: owner.ID}
+	if err := repository.Create(&socialMedia); err != nil {
+		t.Fatalf("creating social media: %v", err)
+	}
+
+	// a moderator's edit carrying their own id must not take the link over
+	if _, err := repository.Update(domain.SocialMedia{ID: socialMedia.ID, UserID: moderator.ID, Name: "gitlab"}); err != nil {
+		t.Fatalf("updating social media: %v", err)
+	}
+
+	stored, err := repository.GetOne(socialMedia.ID)
+	if err != nil {
+		t.Fatalf("getting social media: %v", err)
+	}
+
+	if stored.UserID != owner.ID {
+		t.Errorf("UserID = %d, want the owner %d", stored.UserID, owner.ID)
+	}
+
+	if stored.Name != "gitlab" || stored.SocialMediaUrl != "https://github.com/owner" {
+		t.Errorf("got %q %q, want the name changed and the url kept", stored.Name, stored.SocialMediaUrl)
+	}
+}
@@ -1,13 +1,21 @@
 package service
 
 import (
+	"mygram-api/database"
 	"mygram-api/models/domain"
+	"mygram-api/models/request"
 	"mygram-api/social_medias/repository"
 )
 
+// SocialMediaWebhooks is told about the social media events, to queue them
+// for the webhooks of the owner
+type SocialMediaWebhooks interface {
+	SocialMediaEvent(event string, socialMedia domain.SocialMedia)
+}
+
 type SocialMediaService interface {
 	Create(socialMedia *domain.SocialMedia) (err error)
-	GetAll() (socialMedias []domain.SocialMedia, err error)
+	GetAll(list request.ListRequest) (socialMedias []domain.SocialMedia, page database.Page, err error)
 	GetOne(id uint) (socialMedia domain.SocialMedia, err error)
 	Update(socialMedia domain.SocialMedia) (updatedSocialMedia domain.SocialMedia, err error)
 	Delete(id uint) (err error)
@@ -15,10 +23,11 @@ type SocialMediaService interface {
 
 type SocialMediaServiceRepository struct {
 	SocialMediaRepository repository.SocialMediaRepository
+	SocialMediaWebhooks   SocialMediaWebhooks
 }
 
-func NewSocialMediaService(socialMediaRepository repository.SocialMediaRepository) SocialMediaService {
-	return &SocialMediaServiceRepository{SocialMediaRepository: socialMediaRepository}
+func NewSocialMediaService(socialMediaRepository repository.SocialMediaRepository, socialMediaWebhooks SocialMediaWebhooks) SocialMediaService {
+	return &SocialMediaServiceRepository{SocialMediaRepository: socialMediaRepository, SocialMediaWebhooks: socialMediaWebhooks}
 }
 
 func (socialMediaService *SocialMediaServiceRepository) Create(socialMedia *domain.SocialMedia) (err error) {
@@ -27,12 +36,14 @@ func (socialMediaService *SocialMediaServiceRepository) Create(socialMedia *doma
 		return
 	}
 
+	socialMediaService.SocialMediaWebhooks.SocialMediaEvent(domain.WebhookEventSocialMediaCreated, *socialMedia)
+
 	return
 }
 
-func (socialMediaService *SocialMediaServiceRepository) GetAll() (socialMedias []domain.SocialMedia, err error) {
+func (socialMediaService *SocialMediaServiceRepository) GetAll(list request.ListRequest) (socialMedias []domain.SocialMedia, page database.Page, err error) {
 
-	if socialMedias, err = socialMediaService.SocialMediaRepository.GetAll(); err != nil {
+	if socialMedias, page, err = socialMediaService.SocialMediaRepository.GetAll(list); err != nil {
 		return
 	}
 
@@ -54,14 +65,23 @@ func (socialMediaService *SocialMediaServiceRepository) Update(socialMedia domai
 		return
 	}
 
+	socialMediaService.SocialMediaWebhooks.SocialMediaEvent(domain.WebhookEventSocialMediaUpdated, updatedSocialMedia)
+
 	return
 }
 
 func (socialMediaService *SocialMediaServiceRepository) Delete(id uint) (err error) {
 
+	socialMedia, err := socialMediaService.SocialMediaRepository.GetOne(id)
+	if err != nil {
+		return
+	}
+
 	if err = socialMediaService.SocialMediaRepository.Delete(id); err != nil {
 		return
 	}
 
+	socialMediaService.SocialMediaWebhooks.SocialMediaEvent(domain.WebhookEventSocialMediaDeleted, socialMedia)
+
 	return
 }
\ No newline at end of file
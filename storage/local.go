@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage keeps blobs on the local filesystem, the http server serves
+// them from Dir under the public URL
+type LocalStorage struct {
+	Dir       string
+	PublicURL string
+}
+
+func NewLocalStorage(dir, publicURL string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating storage directory: %w", err)
+	}
+
+	return &LocalStorage{Dir: dir, PublicURL: strings.TrimSuffix(publicURL, "/")}, nil
+}
+
+func (local *LocalStorage) Put(ctx context.Context, key string, data []byte, contentType string) (err error) {
+	path, err := local.path(key)
+	if err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+
+	// write to a temporary file first so readers never see a partial blob
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return
+	}
+
+	defer os.Remove(file.Name())
+
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		return
+	}
+
+	if err = file.Close(); err != nil {
+		return
+	}
+
+	if err = os.Chmod(file.Name(), 0o644); err != nil {
+		return
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+func (local *LocalStorage) Get(ctx context.Context, key string) (body io.ReadCloser, err error) {
+	path, err := local.path(key)
+	if err != nil {
+		return
+	}
+
+	if body, err = os.Open(path); errors.Is(err, fs.ErrNotExist) {
+		err = ErrNotFound
+	}
+
+	return
+}
+
+func (local *LocalStorage) Delete(ctx context.Context, key string) (err error) {
+	path, err := local.path(key)
+	if err != nil {
+		return
+	}
+
+	if err = os.Remove(path); errors.Is(err, fs.ErrNotExist) {
+		err = nil
+	}
+
+	return
+}
+
+func (local *LocalStorage) URL(key string) string {
+	return local.PublicURL + "/" + key
+}
+
+func (local *LocalStorage) path(key string) (string, error) {
+	if !fs.ValidPath(key) || key == "." {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+
+	return filepath.Join(local.Dir, filepath.FromSlash(key)), nil
+}
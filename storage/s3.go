@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"mygram-api/config"
+)
+
+const (
+	amzDateFormat  = "20060102T150405Z"
+	signAlgorithm  = "AWS4-HMAC-SHA256"
+	emptyPayload   = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	maxErrorLength = 1 << 10
+)
+
+// S3Storage keeps blobs in an S3-compatible bucket such as AWS S3 or MinIO,
+// signing requests with AWS Signature Version 4
+type S3Storage struct {
+	Config    config.S3Config
+	PublicURL string
+	Client    *http.Client
+}
+
+func NewS3Storage(s3Config config.S3Config, publicURL string) *S3Storage {
+	return &S3Storage{
+		Config:    s3Config,
+		PublicURL: strings.TrimSuffix(publicURL, "/"),
+		Client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (s3 *S3Storage) Put(ctx context.Context, key string, data []byte, contentType string) (err error) {
+	req, err := s3.newRequest(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return
+	}
+
+	req.Header.Set("Content-Type", contentType)
+
+	res, err := s3.do(req, data)
+	if err != nil {
+		return
+	}
+
+	return res.Body.Close()
+}
+
+func (s3 *S3Storage) Get(ctx context.Context, key string) (body io.ReadCloser, err error) {
+	req, err := s3.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return
+	}
+
+	res, err := s3.do(req, nil)
+	if err != nil {
+		return
+	}
+
+	return res.Body, nil
+}
+
+func (s3 *S3Storage) Delete(ctx context.Context, key string) (err error) {
+	req, err := s3.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return
+	}
+
+	res, err := s3.do(req, nil)
+	if err == ErrNotFound {
+		return nil
+	}
+
+	if err != nil {
+		return
+	}
+
+	return res.Body.Close()
+}
+
+func (s3 *S3Storage) URL(key string) string {
+	if s3.PublicURL != "" {
+		return s3.PublicURL + "/" + key
+	}
+
+	return s3.objectURL(key).String()
+}
+
+// objectURL addresses the object path-style (endpoint/bucket/key), which MinIO
+// expects, or virtual-hosted-style (bucket.endpoint/key) as AWS prefers
+func (s3 *S3Storage) objectURL(key string) *url.URL {
+	endpoint, _ := url.Parse(s3.Config.Endpoint)
+	objectURL := *endpoint
+
+	if s3.Config.PathStyle {
+		objectURL.Path = "/" + s3.Config.Bucket + "/" + key
+	} else {
+		objectURL.Host = s3.Config.Bucket + "." + endpoint.Host
+		objectURL.Path = "/" + key
+	}
+
+	return &objectURL
+}
+
+func (s3 *S3Storage) newRequest(ctx context.Context, method, key string, data []byte) (*http.Request, error) {
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+
+	return http.NewRequestWithContext(ctx, method, s3.objectURL(key).String(), body)
+}
+
+func (s3 *S3Storage) do(req *http.Request, data []byte) (*http.Response, error) {
+	signRequest(req, data, s3.Config, time.Now())
+
+	res, err := s3.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return res, nil
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorLength))
+
+	return nil, fmt.Errorf("s3 %s %s: %s: %s", req.Method, req.URL.Path, res.Status, bytes.TrimSpace(message))
+}
+
+// signRequest adds the x-amz-date, x-amz-content-sha256 and Authorization
+// headers, signing the host and every header already set on req
+func signRequest(req *http.Request, payload []byte, s3Config config.S3Config, now time.Time) {
+	payloadHash := emptyPayload
+	if len(payload) > 0 {
+		sum := sha256.Sum256(payload)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+
+	amzDate := now.UTC().Format(amzDateFormat)
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, s3Config.Region, "s3", "aws4_request"}, "/")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{signAlgorithm, amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s3Config.SecretAccessKey), date)
+	for _, part := range []string{s3Config.Region, "s3", "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signAlgorithm, s3Config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
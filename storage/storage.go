@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"mygram-api/config"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// Storage keeps uploaded blobs under opaque keys such as photos/1/abc.jpg
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) (err error)
+	Get(ctx context.Context, key string) (body io.ReadCloser, err error)
+	Delete(ctx context.Context, key string) (err error)
+	// URL returns the address clients download the blob from
+	URL(key string) string
+}
+
+// New builds the backend selected by STORAGE_DRIVER
+func New(storageConfig config.StorageConfig) (Storage, error) {
+	switch storageConfig.Driver {
+	case config.StorageDriverLocal:
+		return NewLocalStorage(storageConfig.Local.Dir, storageConfig.PublicBaseURL())
+	case config.StorageDriverS3:
+		return NewS3Storage(storageConfig.S3, storageConfig.PublicBaseURL()), nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", storageConfig.Driver)
+	}
+}
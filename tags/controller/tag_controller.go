@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"mygram-api/auth"
+	"mygram-api/helpers"
+	likeService "mygram-api/likes/service"
+	"mygram-api/models/request"
+	"mygram-api/models/response"
+	"mygram-api/tags/service"
+)
+
+type TagController interface {
+	GetAll(c *gin.Context)
+	GetPhotos(c *gin.Context)
+}
+
+type TagControllerService struct {
+	TagService  service.TagService
+	LikeService likeService.LikeService
+}
+
+func NewTagController(tagService service.TagService, likeService likeService.LikeService) TagController {
+	return &TagControllerService{TagService: tagService, LikeService: likeService}
+}
+
+// GetAll tags godoc
+// @Summary Autocomplete tags
+// @Description Get the tags starting with a prefix, with or without its #, the most used first
+// @Tags tags
+// @Security Bearer
+// @Produce json
+// @Param prefix query string false "Start of the tag name"
+// @Param limit query int false "Number of tags" default(10)
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /tags [get]
+func (tagController *TagControllerService) GetAll(c *gin.Context) {
+
+	var search request.TagSearchRequest
+
+	if err := c.ShouldBindQuery(&search); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	search.Normalize()
+
+	tags, err := tagController.TagService.Search(search.Prefix, search.Limit)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	tagsResponse := []response.TagGetAllResponse{}
+	for _, tag := range tags {
+		tagsResponse = append(tagsResponse, response.TagGetAllResponse{
+			Name:       tag.Name,
+			PhotoCount: tag.PhotoCount,
+		})
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: tagsResponse,
+	})
+}
+
+// GetPhotos tag godoc
+// @Summary Get the photos of a tag
+// @Description Get a page of the photos whose caption has a hashtag
+// @Tags tags
+// @Security Bearer
+// @Produce json
+// @Param name path string true "Tag name"
+// @Param page query int false "Page number for offset pagination with a total, without it the first keyset page is returned. Ignored when cursor is set"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "Cursor of the next page from a previous response"
+// @Param sort query string false "Sort order" Enums(-created_at, created_at) default(-created_at)
+// @Param user_id query int false "Only items of this user"
+// @Param created_after query string false "Only items created after this RFC 3339 time"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /tags/{name}/photos [get]
+func (tagController *TagControllerService) GetPhotos(c *gin.Context) {
+
+	var list request.ListRequest
+
+	if err := c.ShouldBindQuery(&list); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	list.Normalize()
+
+	photos, page, err := tagController.TagService.GetPhotos(c.Param("name"), list)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	likeSummaries, err := tagController.LikeService.GetSummaries(photos, auth.MustGetPrincipal(c).UserID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	photosResponse := []response.PhotoGetAllResponse{}
+	for _, photo := range photos {
+		photosResponse = append(photosResponse, response.NewPhotoGetAllResponse(photo, likeSummaries[photo.ID]))
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data:       photosResponse,
+		Pagination: response.NewListPaginationResponse(list, page.Total, page.NextCursor),
+	})
+}
+
+func abortWithBindError(c *gin.Context, err error) {
+
+	validationError, ok := err.(validator.ValidationErrors)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	fieldErrorResponse := make(map[string]interface{})
+
+	for _, v := range validationError {
+		fieldErrorResponse[strings.ToLower(v.Field())] = helpers.GetValidationErrorMsg(v)
+	}
+
+	c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+		Code:   http.StatusBadRequest,
+		Status: "Bad Request",
+		Errors: fieldErrorResponse,
+	})
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"mygram-api/models/domain"
+)
+
+type TagRepository interface {
+	SetPhotoTags(photoID uint, names []string) (tags []domain.Tag, err error)
+	Search(prefix string, limit int) (tags []domain.TagCount, err error)
+}
+
+type TagRepositoryDB struct {
+	DB *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &TagRepositoryDB{DB: db}
+}
+
+// SetPhotoTags replaces the tags of a photo with names, creating the tags that do not exist yet
+func (tagRepository *TagRepositoryDB) SetPhotoTags(photoID uint, names []string) (tags []domain.Tag, err error) {
+
+	err = tagRepository.DB.Transaction(func(tx *gorm.DB) error {
+		unlink := tx.Where("photo_id = ?", photoID)
+
+		if len(names) > 0 {
+			for _, name := range names {
+				tags = append(tags, domain.Tag{Name: name})
+			}
+
+			// updating the name to itself makes postgres return the id of existing tags too
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "name"}},
+				DoUpdates: clause.AssignmentColumns([]string{"name"}),
+			}).Create(&tags).Error; err != nil {
+				return err
+			}
+
+			tagIDs := make([]uint, 0, len(tags))
+			for _, tag := range tags {
+				tagIDs = append(tagIDs, tag.ID)
+			}
+
+			unlink = unlink.Where("tag_id NOT IN ?", tagIDs)
+		}
+
+		if err := unlink.Delete(&domain.PhotoTag{}).Error; err != nil {
+			return err
+		}
+
+		if len(tags) == 0 {
+			return nil
+		}
+
+		photoTags := make([]domain.PhotoTag, 0, len(tags))
+		for _, tag := range tags {
+			photoTags = append(photoTags, domain.PhotoTag{PhotoID: photoID, TagID: tag.ID})
+		}
+
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&photoTags).Error
+	})
+
+	return
+}
+
+// Search returns the tags starting with prefix that are used by at least one
+// photo, the most used first
+func (tagRepository *TagRepositoryDB) Search(prefix string, limit int) (tags []domain.TagCount, err error) {
+
+	pattern := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix) + "%"
+
+	if err = tagRepository.DB.Model(&domain.Tag{}).
+		Select("tags.name, COUNT(*) AS photo_count").
+		Joins("JOIN photo_tags ON photo_tags.tag_id = tags.id").
+		Where(`tags.name LIKE ? ESCAPE '\'`, pattern).
+		Group("tags.id, tags.name").
+		Order("photo_count DESC, tags.name").
+		Limit(limit).
+		Scan(&tags).Error; err != nil {
+		return
+	}
+
+	return
+}
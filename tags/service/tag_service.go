@@ -0,0 +1,56 @@
+package service
+
+import (
+	"strings"
+
+	"mygram-api/database"
+	"mygram-api/helpers"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+	photoRepository "mygram-api/photos/repository"
+	"mygram-api/tags/repository"
+)
+
+type TagService interface {
+	SyncPhotoTags(photo *domain.Photo) (err error)
+	Search(prefix string, limit int) (tags []domain.TagCount, err error)
+	GetPhotos(name string, list request.ListRequest) (photos []domain.Photo, page database.Page, err error)
+}
+
+type TagServiceRepository struct {
+	TagRepository   repository.TagRepository
+	PhotoRepository photoRepository.PhotoRepository
+}
+
+func NewTagService(tagRepository repository.TagRepository, photoRepository photoRepository.PhotoRepository) TagService {
+	return &TagServiceRepository{TagRepository: tagRepository, PhotoRepository: photoRepository}
+}
+
+// SyncPhotoTags sets the tags of a photo to the hashtags of its caption
+func (tagService *TagServiceRepository) SyncPhotoTags(photo *domain.Photo) (err error) {
+
+	if photo.Tags, err = tagService.TagRepository.SetPhotoTags(photo.ID, helpers.ParseHashtags(photo.Caption)); err != nil {
+		return
+	}
+
+	return
+}
+
+// Search returns the used tags starting with prefix, with or without its #
+func (tagService *TagServiceRepository) Search(prefix string, limit int) (tags []domain.TagCount, err error) {
+
+	if tags, err = tagService.TagRepository.Search(strings.ToLower(strings.TrimPrefix(prefix, "#")), limit); err != nil {
+		return
+	}
+
+	return
+}
+
+func (tagService *TagServiceRepository) GetPhotos(name string, list request.ListRequest) (photos []domain.Photo, page database.Page, err error) {
+
+	if photos, page, err = tagService.PhotoRepository.GetAllByTag(strings.ToLower(strings.TrimPrefix(name, "#")), list); err != nil {
+		return
+	}
+
+	return
+}
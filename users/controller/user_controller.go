@@ -1,13 +1,16 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 
+	"mygram-api/auth"
 	"mygram-api/helpers"
+	likeService "mygram-api/likes/service"
 	"mygram-api/models/domain"
 	"mygram-api/models/request"
 	"mygram-api/models/response"
@@ -17,14 +20,29 @@ import (
 type UserController interface {
 	Register(c *gin.Context)
 	Login(c *gin.Context)
+	Refresh(c *gin.Context)
+	Logout(c *gin.Context)
+	GetProfile(c *gin.Context)
+	UpdateProfile(c *gin.Context)
+	ChangePassword(c *gin.Context)
+	DeleteAccount(c *gin.Context)
+	GetPublicProfile(c *gin.Context)
+	GetPhotos(c *gin.Context)
+	VerifyEmail(c *gin.Context)
+	ResendVerification(c *gin.Context)
+	ForgotPassword(c *gin.Context)
+	ResetPassword(c *gin.Context)
 }
 
 type UserControllerService struct {
-	UserService service.UserService
+	UserService    service.UserService
+	ProfileService service.ProfileService
+	LikeService    likeService.LikeService
+	AccountService service.AccountService
 }
 
-func NewUserController(userService service.UserService) UserController {
-	return &UserControllerService{UserService: userService}
+func NewUserController(userService service.UserService, profileService service.ProfileService, likeService likeService.LikeService, accountService service.AccountService) UserController {
+	return &UserControllerService{UserService: userService, ProfileService: profileService, LikeService: likeService, AccountService: accountService}
 }
 
 // Register godoc
@@ -94,20 +112,10 @@ func (userController *UserControllerService) Register(c *gin.Context) {
 	}
 
 	if err := userController.UserService.Register(&user); err != nil {
-		fieldErrorResponse := make(map[string]interface{})
-
-		if strings.Contains(err.Error(), "idx_users_email") {
-			fieldErrorResponse["email"] = "Email is already used"
-		}
-
-		if strings.Contains(err.Error(), "idx_users_username") {
-			fieldErrorResponse["username"] = "Username is already used"
-		}
-
 		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
 			Code:   http.StatusBadRequest,
 			Status: "Bad Request",
-			Errors: fieldErrorResponse,
+			Errors: uniqueFieldErrors(err),
 		})
 
 		return
@@ -132,6 +140,8 @@ func (userController *UserControllerService) Register(c *gin.Context) {
 // @Param json body request.UserLoginRequest true "User Login Request"
 // @Success 200 {object} response.SuccessResponse
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Router /users/login [post]
 func (userController *UserControllerService) Login(c *gin.Context) {
 
@@ -188,6 +198,17 @@ func (userController *UserControllerService) Login(c *gin.Context) {
 	}
 
 	if err := userController.UserService.Login(&user); err != nil {
+		// the credentials were right, the account may just not sign in
+		if errors.Is(err, service.ErrUserBanned) || errors.Is(err, service.ErrEmailNotVerified) {
+			c.AbortWithStatusJSON(http.StatusForbidden, response.ErrorResponse{
+				Code:   http.StatusForbidden,
+				Status: "Forbidden",
+				Errors: err.Error(),
+			})
+
+			return
+		}
+
 		c.AbortWithStatusJSON(http.StatusUnauthorized, response.ErrorResponse{
 			Code:   http.StatusUnauthorized,
 			Status: "Unauthorized",
@@ -197,11 +218,596 @@ func (userController *UserControllerService) Login(c *gin.Context) {
 		return
 	}
 
-	token := helpers.GenerateToken(user.ID, user.Email)
+	tokens, err := userController.UserService.CreateSession(user)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.ErrorResponse{
+			Code:   http.StatusInternalServerError,
+			Status: "Internal Server Error",
+			Errors: err.Error(),
+		})
+
+		return
+	}
 
 	c.JSON(http.StatusOK, response.SuccessResponse{
 		Data: response.UserLoginResponse{
-			Token: token,
+			Token:                 tokens.AccessToken,
+			ExpiresAt:             tokens.AccessTokenExpiresAt,
+			RefreshToken:          tokens.RefreshToken,
+			RefreshTokenExpiresAt: tokens.RefreshTokenExpiresAt,
+		},
+	})
+}
+
+// Refresh godoc
+// @Summary Refresh a token
+// @Description Exchange a refresh token for a new access and refresh token. Reusing an already exchanged refresh token revokes the whole session.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param json body request.UserRefreshTokenRequest true "User Refresh Token Request"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /users/refresh [post]
+func (userController *UserControllerService) Refresh(c *gin.Context) {
+
+	var req request.UserRefreshTokenRequest
+
+	if !bindRequest(c, &req) {
+		return
+	}
+
+	tokens, err := userController.UserService.RefreshSession(req.RefreshToken)
+	if errors.Is(err, service.ErrUserBanned) {
+		c.AbortWithStatusJSON(http.StatusForbidden, response.ErrorResponse{
+			Code:   http.StatusForbidden,
+			Status: "Forbidden",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, response.ErrorResponse{
+			Code:   http.StatusUnauthorized,
+			Status: "Unauthorized",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.UserLoginResponse{
+			Token:                 tokens.AccessToken,
+			ExpiresAt:             tokens.AccessTokenExpiresAt,
+			RefreshToken:          tokens.RefreshToken,
+			RefreshTokenExpiresAt: tokens.RefreshTokenExpiresAt,
+		},
+	})
+}
+
+// Logout godoc
+// @Summary Logout a user
+// @Description Revoke the refresh token and every token rotated from the same login
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param json body request.UserRefreshTokenRequest true "User Refresh Token Request"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /users/logout [post]
+func (userController *UserControllerService) Logout(c *gin.Context) {
+
+	var req request.UserRefreshTokenRequest
+
+	if !bindRequest(c, &req) {
+		return
+	}
+
+	if err := userController.UserService.RevokeSession(req.RefreshToken); err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, response.ErrorResponse{
+			Code:   http.StatusUnauthorized,
+			Status: "Unauthorized",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.UserMessageResponse{
+			Message: "Logged out successfully",
+		},
+	})
+}
+
+// GetProfile godoc
+// @Summary Get my profile
+// @Description Get the profile of the authenticated user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security Bearer
+// @Router /users/me [get]
+func (userController *UserControllerService) GetProfile(c *gin.Context) {
+
+	user, err := userController.UserService.GetProfile(auth.MustGetPrincipal(c).UserID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.ErrorResponse{
+			Code:   http.StatusNotFound,
+			Status: "Not Found",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.UserProfileResponse{
+			ID:            user.ID,
+			Username:      user.Username,
+			Email:         user.Email,
+			EmailVerified: user.IsEmailVerified(),
+			Age:           user.Age,
+			CreatedAt:     user.CreatedAt,
+			UpdatedAt:     user.UpdatedAt,
+		},
+	})
+}
+
+// UpdateProfile godoc
+// @Summary Update my profile
+// @Description Update the username, age and email of the authenticated user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param json body request.UserUpdateRequest true "User Update Request"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Security Bearer
+// @Router /users/me [put]
+func (userController *UserControllerService) UpdateProfile(c *gin.Context) {
+
+	var req request.UserUpdateRequest
+
+	if !bindRequest(c, &req) {
+		return
+	}
+
+	user := domain.User{
+		ID:       auth.MustGetPrincipal(c).UserID,
+		Username: req.Username,
+		Age:      req.Age,
+		Email:    req.Email,
+	}
+
+	updatedUser, err := userController.UserService.UpdateProfile(user)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: uniqueFieldErrors(err),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.UserProfileResponse{
+			ID:            updatedUser.ID,
+			Username:      updatedUser.Username,
+			Email:         updatedUser.Email,
+			EmailVerified: updatedUser.IsEmailVerified(),
+			Age:           updatedUser.Age,
+			CreatedAt:     updatedUser.CreatedAt,
+			UpdatedAt:     updatedUser.UpdatedAt,
+		},
+	})
+}
+
+// ChangePassword godoc
+// @Summary Change my password
+// @Description Change the password of the authenticated user and sign out every other session
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param json body request.UserChangePasswordRequest true "User Change Password Request"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Security Bearer
+// @Router /users/me/password [put]
+func (userController *UserControllerService) ChangePassword(c *gin.Context) {
+
+	var req request.UserChangePasswordRequest
+
+	if !bindRequest(c, &req) {
+		return
+	}
+
+	principal := auth.MustGetPrincipal(c)
+
+	if err := userController.UserService.ChangePassword(principal.UserID, req.OldPassword, req.NewPassword, principal.SessionID); err != nil {
+		var errs interface{} = err.Error()
+		if errors.Is(err, service.ErrWrongPassword) {
+			errs = gin.H{"old_password": err.Error()}
+		}
+
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: errs,
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.UserMessageResponse{
+			Message: "Password changed successfully",
+		},
+	})
+}
+
+// DeleteAccount godoc
+// @Summary Delete my account
+// @Description Delete the authenticated user together with their photos, comments and social media
+// @Tags users
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Security Bearer
+// @Router /users/me [delete]
+func (userController *UserControllerService) DeleteAccount(c *gin.Context) {
+
+	if err := userController.UserService.DeleteAccount(auth.MustGetPrincipal(c).UserID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.UserMessageResponse{
+			Message: "Account deleted successfully",
+		},
+	})
+}
+
+// GetPublicProfile godoc
+// @Summary Get a user profile
+// @Description Get the public profile of a user with photo, comment and follow counts and social media links
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security Bearer
+// @Router /users/{username} [get]
+func (userController *UserControllerService) GetPublicProfile(c *gin.Context) {
+
+	profile, err := userController.ProfileService.GetPublicProfile(c.Param("username"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.ErrorResponse{
+			Code:   http.StatusNotFound,
+			Status: "Not Found",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	socialMediasResponse := []response.UserSocialMediaResponse{}
+	for _, socialMedia := range profile.SocialMedias {
+		socialMediasResponse = append(socialMediasResponse, response.UserSocialMediaResponse{
+			ID:             socialMedia.ID,
+			Name:           socialMedia.Name,
+			SocialMediaUrl: socialMedia.SocialMediaUrl,
+		})
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.UserPublicProfileResponse{
+			ID:                    profile.User.ID,
+			Username:              profile.User.Username,
+			CreatedAt:             profile.User.CreatedAt,
+			PhotoCount:            profile.PhotoCount,
+			CommentsReceivedCount: profile.CommentsReceivedCount,
+			FollowerCount:         profile.FollowerCount,
+			FollowingCount:        profile.FollowingCount,
+			SocialMedias:          socialMediasResponse,
+		},
+	})
+}
+
+// GetPhotos godoc
+// @Summary Get the photos of a user
+// @Description Get the photos of a user, newest first
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param username path string true "Username"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Security Bearer
+// @Router /users/{username}/photos [get]
+func (userController *UserControllerService) GetPhotos(c *gin.Context) {
+
+	var pagination request.PaginationRequest
+
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	pagination.Normalize()
+
+	photos, total, err := userController.ProfileService.GetPhotos(c.Param("username"), pagination)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.ErrorResponse{
+			Code:   http.StatusNotFound,
+			Status: "Not Found",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	likeSummaries, err := userController.LikeService.GetSummaries(photos, auth.MustGetPrincipal(c).UserID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	photosResponse := []response.PhotoGetAllResponse{}
+	for _, photo := range photos {
+		photosResponse = append(photosResponse, response.NewPhotoGetAllResponse(photo, likeSummaries[photo.ID]))
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data:       photosResponse,
+		Pagination: response.NewPaginationResponse(pagination.Page, pagination.Limit, total),
+	})
+}
+
+// VerifyEmail godoc
+// @Summary Verify an email
+// @Description Verify the email of a user with the token of the link mailed on registration or email change
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param json body request.UserVerifyEmailRequest true "User Verify Email Request"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Router /users/verify [post]
+func (userController *UserControllerService) VerifyEmail(c *gin.Context) {
+
+	var req request.UserVerifyEmailRequest
+
+	if !bindRequest(c, &req) {
+		return
+	}
+
+	if err := userController.AccountService.VerifyEmail(req.Token); err != nil {
+		var errs interface{} = err.Error()
+		if errors.Is(err, service.ErrInvalidAccountToken) {
+			errs = gin.H{"token": err.Error()}
+		}
+
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: errs,
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.UserMessageResponse{
+			Message: "Email verified successfully",
+		},
+	})
+}
+
+// ResendVerification godoc
+// @Summary Resend the verification email
+// @Description Mail a new verification link to the email of an unverified user. The answer is the same whether or not the email is registered.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param json body request.UserEmailRequest true "User Email Request"
+// @Success 202 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Router /users/verify/resend [post]
+func (userController *UserControllerService) ResendVerification(c *gin.Context) {
+
+	var req request.UserEmailRequest
+
+	if !bindRequest(c, &req) {
+		return
+	}
+
+	if err := userController.AccountService.ResendVerification(req.Email); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.ErrorResponse{
+			Code:   http.StatusInternalServerError,
+			Status: "Internal Server Error",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.SuccessResponse{
+		Data: response.UserMessageResponse{
+			Message: "If the email belongs to an unverified account, a verification link is on its way",
 		},
 	})
+}
+
+// ForgotPassword godoc
+// @Summary Forgot password
+// @Description Mail a password reset link to the email of a user. The answer is the same whether or not the email is registered.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param json body request.UserEmailRequest true "User Email Request"
+// @Success 202 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Router /users/forgot-password [post]
+func (userController *UserControllerService) ForgotPassword(c *gin.Context) {
+
+	var req request.UserEmailRequest
+
+	if !bindRequest(c, &req) {
+		return
+	}
+
+	if err := userController.AccountService.ForgotPassword(req.Email); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.ErrorResponse{
+			Code:   http.StatusInternalServerError,
+			Status: "Internal Server Error",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.SuccessResponse{
+		Data: response.UserMessageResponse{
+			Message: "If the email is registered, a password reset link is on its way",
+		},
+	})
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Choose a new password with the token of the mailed reset link, which signs out every session
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param json body request.UserResetPasswordRequest true "User Reset Password Request"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Router /users/reset-password [post]
+func (userController *UserControllerService) ResetPassword(c *gin.Context) {
+
+	var req request.UserResetPasswordRequest
+
+	if !bindRequest(c, &req) {
+		return
+	}
+
+	if err := userController.AccountService.ResetPassword(req.Token, req.NewPassword); err != nil {
+		var errs interface{} = err.Error()
+		if errors.Is(err, service.ErrInvalidAccountToken) {
+			errs = gin.H{"token": err.Error()}
+		}
+
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: errs,
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.UserMessageResponse{
+			Message: "Password reset successfully",
+		},
+	})
+}
+
+// uniqueFieldErrors maps unique index violations to field errors
+func uniqueFieldErrors(err error) interface{} {
+	fieldErrorResponse := make(map[string]interface{})
+
+	if strings.Contains(err.Error(), "idx_users_email") {
+		fieldErrorResponse["email"] = "Email is already used"
+	}
+
+	if strings.Contains(err.Error(), "idx_users_username") {
+		fieldErrorResponse["username"] = "Username is already used"
+	}
+
+	if len(fieldErrorResponse) == 0 {
+		return err.Error()
+	}
+
+	return fieldErrorResponse
+}
+
+func bindRequest(c *gin.Context, req interface{}) bool {
+
+	contentType := helpers.GetContentType(c)
+
+	if contentType != "application/json" && contentType != "application/x-www-form-urlencoded" {
+		c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, response.ErrorResponse{
+			Code:   http.StatusUnsupportedMediaType,
+			Status: "Unsupported Media Type",
+			Errors: "Request content type must be either 'application/json' or 'application/x-www-form-urlencoded'",
+		})
+
+		return false
+	}
+
+	if err := c.ShouldBind(req); err != nil {
+		abortWithBindError(c, err)
+		return false
+	}
+
+	return true
+}
+
+func abortWithBindError(c *gin.Context, err error) {
+
+	validationError, ok := err.(validator.ValidationErrors)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	fieldErrorResponse := make(map[string]interface{})
+
+	for _, v := range validationError {
+		fieldErrorResponse[strings.ToLower(v.Field())] = helpers.GetValidationErrorMsg(v)
+	}
+
+	c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+		Code:   http.StatusBadRequest,
+		Status: "Bad Request",
+		Errors: fieldErrorResponse,
+	})
 }
\ No newline at end of file
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"mygram-api/models/domain"
+	"mygram-api/users/service"
+)
+
+// loginResult answers every login with err, the methods the tests do not use
+// are left to the nil interface
+type loginResult struct {
+	service.UserService
+
+	err error
+}
+
+func (result loginResult) Login(*domain.User) error {
+	return result.err
+}
+
+func TestLoginStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{errors.New("invalid email or password"), http.StatusUnauthorized},
+		{service.ErrUserBanned, http.StatusForbidden},
+		{service.ErrEmailNotVerified, http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		router := gin.New()
+		router.POST("/users/login", NewUserController(loginResult{err: test.err}, nil, nil, nil).Login)
+
+		req := httptest.NewRequest(http.MethodPost, "/users/login", strings.NewReader(`{"email":"someone@example.com","password":"secret"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		if recorder.Code != test.want || !strings.Contains(recorder.Body.String(), test.err.Error()) {
+			t.Errorf("login failing with %q = %d %s, want %d with the error", test.err, recorder.Code, recorder.Body, test.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"mygram-api/models/domain"
+)
+
+// ErrRefreshTokenSpent is returned when a token was already rotated or revoked
+var ErrRefreshTokenSpent = errors.New("refresh token already used")
+
+type RefreshTokenRepository interface {
+	Create(refreshToken *domain.RefreshToken) (err error)
+	GetByHash(tokenHash string) (refreshToken domain.RefreshToken, err error)
+	Rotate(current domain.RefreshToken, next *domain.RefreshToken) (err error)
+	RevokeFamily(familyID string) (err error)
+	RevokeAllForUser(userID uint, exceptFamilyID string) (err error)
+	IsFamilyRevoked(familyID string) (revoked bool, err error)
+}
+
+type RefreshTokenRepositoryDB struct {
+	DB *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &RefreshTokenRepositoryDB{DB: db}
+}
+
+func (refreshTokenRepository *RefreshTokenRepositoryDB) Create(refreshToken *domain.RefreshToken) (err error) {
+
+	if err = refreshTokenRepository.DB.Create(refreshToken).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+func (refreshTokenRepository *RefreshTokenRepositoryDB) GetByHash(tokenHash string) (refreshToken domain.RefreshToken, err error) {
+
+	if err = refreshTokenRepository.DB.Preload("User").Where("token_hash = ?", tokenHash).Take(&refreshToken).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// Rotate marks current as used and stores next in one transaction. The
+// conditional update makes two concurrent refreshes with the same token
+// race for a single winner, the loser gets ErrRefreshTokenSpent.
+func (refreshTokenRepository *RefreshTokenRepositoryDB) Rotate(current domain.RefreshToken, next *domain.RefreshToken) (err error) {
+
+	return refreshTokenRepository.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", current.ID).
+			Update("used_at", time.Now())
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenSpent
+		}
+
+		return tx.Create(next).Error
+	})
+}
+
+func (refreshTokenRepository *RefreshTokenRepositoryDB) RevokeFamily(familyID string) (err error) {
+
+	if err = refreshTokenRepository.DB.Model(&domain.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// RevokeAllForUser revokes every session of the user but the one in exceptFamilyID
+func (refreshTokenRepository *RefreshTokenRepositoryDB) RevokeAllForUser(userID uint, exceptFamilyID string) (err error) {
+
+	if err = refreshTokenRepository.DB.Model(&domain.RefreshToken{}).
+		Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, exceptFamilyID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// IsFamilyRevoked reports a session as live only while one of its tokens is
+// not revoked, so a family that is unknown or was deleted with its user
+// counts as revoked
+func (refreshTokenRepository *RefreshTokenRepositoryDB) IsFamilyRevoked(familyID string) (revoked bool, err error) {
+
+	var live bool
+
+	if err = refreshTokenRepository.DB.
+		Raw("SELECT EXISTS (SELECT 1 FROM refresh_tokens WHERE family_id = ? AND revoked_at IS NULL)", familyID).
+		Scan(&live).Error; err != nil {
+		return
+	}
+
+	return !live, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"mygram-api/database/dbtest"
+	"mygram-api/models/domain"
+)
+
+func TestIsFamilyRevoked(t *testing.T) {
+	db := dbtest.Open(t)
+	refreshTokens := NewRefreshTokenRepository(db)
+
+	user := domain.User{Username: "someone", Email: "someone@example.com", Password: "secret", Age: 20}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+
+	for i, family := range []string{"revoked", "live", "deleted"} {
+		token := domain.RefreshToken{UserID: user.ID, FamilyID: family, TokenHash: family, ExpiresAt: time.Now().Add(time.Hour)}
+		if err := refreshTokens.Create(&token); err != nil {
+			t.Fatalf("creating token %d: %v", i, err)
+		}
+	}
+
+	// a rotated token stays in its family, the session is live through the next one
+	current, err := refreshTokens.GetByHash("live")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next := domain.RefreshToken{UserID: user.ID, FamilyID: "live", TokenHash: "live-next", ExpiresAt: time.Now().Add(time.Hour)}
+	if err = refreshTokens.Rotate(current, &next); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = refreshTokens.RevokeFamily("revoked"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = db.Where("family_id = ?", "deleted").Delete(&domain.RefreshToken{}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		family  string
+		revoked bool
+	}{
+		{"live", false},
+		{"revoked", true},
+		{"deleted", true},
+		{"unknown", true},
+	}
+
+	for _, test := range tests {
+		revoked, err := refreshTokens.IsFamilyRevoked(test.family)
+		if err != nil {
+			t.Fatalf("IsFamilyRevoked(%s): %v", test.family, err)
+		}
+
+		if revoked != test.revoked {
+			t.Errorf("IsFamilyRevoked(%s) = %t, want %t", test.family, revoked, test.revoked)
+		}
+	}
+}
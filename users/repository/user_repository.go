@@ -2,16 +2,33 @@ package repository
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
 	"mygram-api/helpers"
 	"mygram-api/models/domain"
+	"mygram-api/models/request"
 )
 
 type UserRepository interface {
 	Register(user *domain.User) (err error)
 	Login(user *domain.User) (err error)
+	GetByID(id uint) (user domain.User, err error)
+	GetByUsername(username string) (user domain.User, err error)
+	GetByEmail(email string) (user domain.User, err error)
+	GetByIDs(ids []uint) (users []domain.User, err error)
+	GetByUsernames(usernames []string) (users []domain.User, err error)
+	Update(user domain.User) (updatedUser domain.User, err error)
+	UpdatePassword(id uint, hashedPassword string) (err error)
+	ResetPassword(id uint, currentHashedPassword, hashedPassword string) (reset bool, err error)
+	MarkEmailVerified(id uint, email string) (verified bool, err error)
+	Delete(id uint) (err error)
+	GetStorageKeys(id uint) (keys []string, err error)
+	GetAll(pagination request.PaginationRequest) (users []domain.User, total int64, err error)
+	SetBannedAt(id uint, bannedAt *time.Time) (user domain.User, err error)
+	SetRole(id uint, role string) (user domain.User, err error)
 }
 
 type UserRepositoryDB struct {
@@ -42,5 +59,230 @@ func (userRepository *UserRepositoryDB) Login(user *domain.User) (err error) {
 		return errors.New("invalid email or password")
 	}
 
+	return
+}
+
+func (userRepository *UserRepositoryDB) GetByID(id uint) (user domain.User, err error) {
+
+	if err = userRepository.DB.First(&user, id).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+func (userRepository *UserRepositoryDB) GetByUsername(username string) (user domain.User, err error) {
+
+	if err = userRepository.DB.Where("username = ?", username).Take(&user).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+func (userRepository *UserRepositoryDB) GetByEmail(email string) (user domain.User, err error) {
+
+	if err = userRepository.DB.Where("email = ?", email).Take(&user).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// GetByIDs loads the users with the given ids, in no particular order
+func (userRepository *UserRepositoryDB) GetByIDs(ids []uint) (users []domain.User, err error) {
+
+	if err = userRepository.DB.Find(&users, ids).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// GetByUsernames loads the users with the given usernames whatever their
+// case, ordered by id. Unknown ones are left out.
+func (userRepository *UserRepositoryDB) GetByUsernames(usernames []string) (users []domain.User, err error) {
+
+	if len(usernames) == 0 {
+		return
+	}
+
+	lowered := make([]string, 0, len(usernames))
+	for _, username := range usernames {
+		lowered = append(lowered, strings.ToLower(username))
+	}
+
+	if err = userRepository.DB.Where("lower(username) IN ?", lowered).Order("id").Find(&users).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+func (userRepository *UserRepositoryDB) Update(user domain.User) (updatedUser domain.User, err error) {
+
+	if err = userRepository.DB.First(&updatedUser, user.ID).Error; err != nil {
+		return
+	}
+
+	columns := []string{"username", "age", "email"}
+
+	// the new address has to be verified again
+	if user.Email != updatedUser.Email {
+		user.EmailVerifiedAt = nil
+		columns = append(columns, "email_verified_at")
+	}
+
+	if err = userRepository.DB.Model(&updatedUser).Select(columns).Updates(user).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+func (userRepository *UserRepositoryDB) UpdatePassword(id uint, hashedPassword string) (err error) {
+
+	if err = userRepository.DB.Model(&domain.User{ID: id}).Update("password", hashedPassword).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// ResetPassword replaces the password of the user, unless it changed from
+// currentHashedPassword in the meantime, and marks the email verified since
+// the reset link was opened from it
+func (userRepository *UserRepositoryDB) ResetPassword(id uint, currentHashedPassword, hashedPassword string) (reset bool, err error) {
+
+	result := userRepository.DB.Model(&domain.User{}).
+		Where("id = ? AND password = ?", id, currentHashedPassword).
+		Updates(map[string]interface{}{
+			"password":          hashedPassword,
+			"email_verified_at": gorm.Expr("COALESCE(email_verified_at, ?)", time.Now()),
+		})
+	if err = result.Error; err != nil {
+		return
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// MarkEmailVerified verifies the email of the user, unless it changed from
+// email or is verified already
+func (userRepository *UserRepositoryDB) MarkEmailVerified(id uint, email string) (verified bool, err error) {
+
+	result := userRepository.DB.Model(&domain.User{}).
+		Where("id = ? AND email = ? AND email_verified_at IS NULL", id, email).
+		Update("email_verified_at", time.Now())
+	if err = result.Error; err != nil {
+		return
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// Delete removes the user together with everything that references it:
+// comments written by the user or left on the user's photos, the photos,
+// social media links and refresh tokens. The replies of other users to the
+// comments of the user stay, as top level comments.
+func (userRepository *UserRepositoryDB) Delete(id uint) (err error) {
+
+	return userRepository.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&domain.User{}, id).Error; err != nil {
+			return err
+		}
+
+		userPhotos := tx.Model(&domain.Photo{}).Select("id").Where("user_id = ?", id)
+		userComments := tx.Model(&domain.Comment{}).Select("id").Where("user_id = ?", id)
+
+		var orphanIDs []uint
+
+		if err := tx.Model(&domain.Comment{}).
+			Where("parent_id IN (?) AND user_id <> ? AND photo_id NOT IN (?)", userComments, id, userPhotos).
+			Pluck("id", &orphanIDs).Error; err != nil {
+			return err
+		}
+
+		// their parent_id is set to null by the foreign key
+		if err := tx.Where("user_id = ? OR photo_id IN (?)", id, userPhotos).Delete(&domain.Comment{}).Error; err != nil {
+			return err
+		}
+
+		// the orphaned replies and their own replies move up to the top level
+		if len(orphanIDs) > 0 {
+			if err := tx.Exec(`WITH RECURSIVE tree AS (
+					SELECT id, 0 AS depth FROM comments WHERE id IN ?
+					UNION ALL
+					SELECT comments.id, tree.depth + 1 FROM comments JOIN tree ON comments.parent_id = tree.id
+				)
+				UPDATE comments SET depth = tree.depth FROM tree WHERE comments.id = tree.id`, orphanIDs).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("user_id = ?", id).Delete(&domain.Photo{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", id).Delete(&domain.SocialMedia{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", id).Delete(&domain.RefreshToken{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&domain.User{}, id).Error
+	})
+}
+
+// GetStorageKeys returns the blob keys of the photos uploaded by the user and
+// of their variants, which go with the rows when the account is deleted
+func (userRepository *UserRepositoryDB) GetStorageKeys(id uint) (keys []string, err error) {
+
+	err = userRepository.DB.Raw(`SELECT storage_key FROM photos WHERE user_id = ? AND storage_key <> ''
+		UNION ALL
+		SELECT photo_variants.storage_key FROM photo_variants JOIN photos ON photos.id = photo_variants.photo_id WHERE photos.user_id = ?`, id, id).
+		Scan(&keys).Error
+
+	return
+}
+
+func (userRepository *UserRepositoryDB) GetAll(pagination request.PaginationRequest) (users []domain.User, total int64, err error) {
+
+	if err = userRepository.DB.Model(&domain.User{}).Count(&total).Error; err != nil {
+		return
+	}
+
+	if err = userRepository.DB.Order("id").Limit(pagination.Limit).Offset(pagination.Offset()).Find(&users).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+func (userRepository *UserRepositoryDB) SetBannedAt(id uint, bannedAt *time.Time) (user domain.User, err error) {
+
+	if err = userRepository.DB.First(&user, id).Error; err != nil {
+		return
+	}
+
+	if err = userRepository.DB.Model(&user).Update("banned_at", bannedAt).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+func (userRepository *UserRepositoryDB) SetRole(id uint, role string) (user domain.User, err error) {
+
+	if err = userRepository.DB.First(&user, id).Error; err != nil {
+		return
+	}
+
+	if err = userRepository.DB.Model(&user).Update("role", role).Error; err != nil {
+		return
+	}
+
 	return
 }
\ No newline at end of file
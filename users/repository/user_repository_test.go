@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"mygram-api/database/dbtest"
+	"mygram-api/models/domain"
+)
+
+func TestDeleteKeepsTheRepliesOfOtherUsers(t *testing.T) {
+	db := dbtest.Open(t)
+
+	leaving := domain.User{Username: "leaving", Email: "leaving@example.com", Password: "secret", Age: 20}
+	staying := domain.User{Username: "staying", Email: "staying@example.com", Password: "secret", Age: 20}
+
+	for _, user := range []*domain.User{&leaving, &staying} {
+		if err := db.Create(user).Error; err != nil {
+			t.Fatalf("creating user: %v", err)
+		}
+	}
+
+	photo := domain.Photo{Title: "photo", PhotoUrl: "https://example.com/photo.jpg", UserID: staying.ID}
+	if err := db.Create(&photo).Error; err != nil {
+		t.Fatalf("creating photo: %v", err)
+	}
+
+	create := func(comment domain.Comment) domain.Comment {
+		t.Helper()
+
+		comment.PhotoID = photo.ID
+		if err := db.Create(&comment).Error; err != nil {
+			t.Fatalf("creating comment: %v", err)
+		}
+
+		return comment
+	}
+
+	// staying > leaving > staying > staying, and a placeholder of leaving's
+	root := create(domain.Comment{UserID: staying.ID, Message: "root"})
+	byLeaving := create(domain.Comment{UserID: leaving.ID, Message: "reply", ParentID: &root.ID, Depth: 1})
+	answer := create(domain.Comment{UserID: staying.ID, Message: "answer", ParentID: &byLeaving.ID, Depth: 2})
+	nested := create(domain.Comment{UserID: staying.ID, Message: "nested", ParentID: &answer.ID, Depth: 3})
+
+	deletedAt := time.Now()
+	placeholder := create(domain.Comment{UserID: leaving.ID, DeletedAt: &deletedAt})
+	kept := create(domain.Comment{UserID: staying.ID, Message: "kept", ParentID: &placeholder.ID, Depth: 1})
+
+	if err := NewUserRepository(db).Delete(leaving.ID); err != nil {
+		t.Fatalf("deleting the user: %v", err)
+	}
+
+	var comments []domain.Comment
+	if err := db.Order("id").Find(&comments).Error; err != nil {
+		t.Fatalf("listing comments: %v", err)
+	}
+
+	want := map[uint]struct {
+		parentID *uint
+		depth    int
+	}{
+		root.ID:   {nil, 0},
+		answer.ID: {nil, 0},
+		nested.ID: {&answer.ID, 1},
+		kept.ID:   {nil, 0},
+	}
+
+	if len(comments) != len(want) {
+		t.Fatalf("%d comments left, want %d: %+v", len(comments), len(want), comments)
+	}
+
+	for _, comment := range comments {
+		expected, ok := want[comment.ID]
+		if !ok {
+			t.Errorf("comment %d by user %d was kept", comment.ID, comment.UserID)
+			continue
+		}
+
+		if (comment.ParentID == nil) != (expected.parentID == nil) || (comment.ParentID != nil && *comment.ParentID != *expected.parentID) {
+			t.Errorf("comment %d has parent %v, want %v", comment.ID, comment.ParentID, expected.parentID)
+		}
+
+		if comment.Depth != expected.depth {
+			t.Errorf("comment %d has depth %d, want %d", comment.ID, comment.Depth, expected.depth)
+		}
+	}
+}
+
+func TestGetStorageKeys(t *testing.T) {
+	db := dbtest.Open(t)
+
+	owner := domain.User{Username: "owner", Email: "owner@example.com", Password: "secret", Age: 20}
+	other := domain.User{Username: "other", Email: "other@example.com", Password: "secret", Age: 20}
+
+	for _, user := range []*domain.User{&owner, &other} {
+		if err := db.Create(user).Error; err != nil {
+			t.Fatalf("creating user: %v", err)
+		}
+	}
+
+	uploaded := domain.Photo{Title: "uploaded", PhotoUrl: "http://localhost:8080/uploads/photos/1/a.jpg", StorageKey: "photos/1/a.jpg", UserID: owner.ID}
+	linked := domain.Photo{Title: "linked", PhotoUrl: "https://example.com/photo.jpg", UserID: owner.ID}
+	others := domain.Photo{Title: "others", PhotoUrl: "http://localhost:8080/uploads/photos/2/b.jpg", StorageKey: "photos/2/b.jpg", UserID: other.ID}
+
+	for _, photo := range []*domain.Photo{&uploaded, &linked, &others} {
+		if err := db.Create(photo).Error; err != nil {
+			t.Fatalf("creating photo: %v", err)
+		}
+	}
+
+	for _, variant := range []domain.PhotoVariant{
+		{PhotoID: uploaded.ID, Name: "thumb", StorageKey: "photos/1/a_thumb.jpg", Url: "thumb", ContentType: "image/jpeg"},
+		{PhotoID: others.ID, Name: "thumb", StorageKey: "photos/2/b_thumb.jpg", Url: "thumb", ContentType: "image/jpeg"},
+	} {
+		if err := db.Create(&variant).Error; err != nil {
+			t.Fatalf("creating variant: %v", err)
+		}
+	}
+
+	keys, err := NewUserRepository(db).GetStorageKeys(owner.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(keys)
+
+	if want := []string{"photos/1/a.jpg", "photos/1/a_thumb.jpg"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("GetStorageKeys = %q, want %q", keys, want)
+	}
+}
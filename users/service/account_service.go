@@ -0,0 +1,204 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"mygram-api/config"
+	"mygram-api/helpers"
+	"mygram-api/mailer"
+	"mygram-api/models/domain"
+	"mygram-api/users/repository"
+)
+
+// Purposes of the tokens mailed to users, a token signed for one is refused for the other
+const (
+	tokenPurposeVerifyEmail   = "verify-email"
+	tokenPurposeResetPassword = "reset-password"
+)
+
+var ErrInvalidAccountToken = errors.New("invalid, expired or already used token")
+
+// MailScheduler queues emails to be sent in the background
+type MailScheduler interface {
+	ScheduleMail(message mailer.Message) (err error)
+}
+
+// AccountService mails the links that verify the email of a user and reset
+// a forgotten password. The tokens of the links are signed rather than
+// stored, and each only works once: a verification token is spent once the
+// email is verified or changed, a reset token once the password changes.
+//
+// ResendVerification and ForgotPassword succeed whether or not the address
+// belongs to a user, so they cannot be used to find out who is registered.
+type AccountService interface {
+	SendVerification(user domain.User) (err error)
+	ResendVerification(email string) (err error)
+	VerifyEmail(token string) (err error)
+	ForgotPassword(email string) (err error)
+	ResetPassword(token, newPassword string) (err error)
+}
+
+type AccountServiceRepository struct {
+	UserRepository         repository.UserRepository
+	RefreshTokenRepository repository.RefreshTokenRepository
+	MailScheduler          MailScheduler
+	SecretKey              string
+	Config                 config.AccountsConfig
+}
+
+func NewAccountService(userRepository repository.UserRepository, refreshTokenRepository repository.RefreshTokenRepository, mailScheduler MailScheduler, jwtConfig config.JWTConfig, accountsConfig config.AccountsConfig) AccountService {
+	return &AccountServiceRepository{UserRepository: userRepository, RefreshTokenRepository: refreshTokenRepository, MailScheduler: mailScheduler, SecretKey: jwtConfig.SecretKey, Config: accountsConfig}
+}
+
+// SendVerification mails the user a link verifying their current email
+func (accountService *AccountServiceRepository) SendVerification(user domain.User) (err error) {
+
+	token := helpers.SignUserToken(accountService.SecretKey, tokenPurposeVerifyEmail, user.ID, user.Email, time.Now().Add(accountService.Config.VerificationTTL))
+
+	return accountService.MailScheduler.ScheduleMail(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your MyGram email",
+		Text: fmt.Sprintf("Hi %s,\n\n"+
+			"Confirm that this is your email address by opening the link below within %s:\n\n"+
+			"%s\n\n"+
+			"If you did not sign up for MyGram, you can ignore this email.\n",
+			user.Username, formatTTL(accountService.Config.VerificationTTL), accountService.link("verify-email", token)),
+	})
+}
+
+func (accountService *AccountServiceRepository) ResendVerification(email string) (err error) {
+
+	user, err := accountService.UserRepository.GetByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return
+	}
+
+	if user.IsEmailVerified() || user.IsBanned() {
+		return
+	}
+
+	return accountService.SendVerification(user)
+}
+
+func (accountService *AccountServiceRepository) VerifyEmail(token string) (err error) {
+
+	user, err := accountService.userOf(token)
+	if err != nil {
+		return
+	}
+
+	if err = helpers.VerifyUserToken(accountService.SecretKey, tokenPurposeVerifyEmail, token, user.Email); err != nil {
+		return ErrInvalidAccountToken
+	}
+
+	verified, err := accountService.UserRepository.MarkEmailVerified(user.ID, user.Email)
+	if err != nil {
+		return
+	}
+
+	if !verified {
+		return ErrInvalidAccountToken
+	}
+
+	return
+}
+
+// ForgotPassword mails the user a link to choose a new password
+func (accountService *AccountServiceRepository) ForgotPassword(email string) (err error) {
+
+	user, err := accountService.UserRepository.GetByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return
+	}
+
+	if user.IsBanned() {
+		return
+	}
+
+	token := helpers.SignUserToken(accountService.SecretKey, tokenPurposeResetPassword, user.ID, user.Password, time.Now().Add(accountService.Config.PasswordResetTTL))
+
+	return accountService.MailScheduler.ScheduleMail(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your MyGram password",
+		Text: fmt.Sprintf("Hi %s,\n\n"+
+			"Choose a new password by opening the link below within %s:\n\n"+
+			"%s\n\n"+
+			"If you did not ask to reset your password, you can ignore this email and keep using your current one.\n",
+			user.Username, formatTTL(accountService.Config.PasswordResetTTL), accountService.link("reset-password", token)),
+	})
+}
+
+// ResetPassword stores the new password and signs out every session, in
+// case the old password leaked
+func (accountService *AccountServiceRepository) ResetPassword(token, newPassword string) (err error) {
+
+	user, err := accountService.userOf(token)
+	if err != nil {
+		return
+	}
+
+	if err = helpers.VerifyUserToken(accountService.SecretKey, tokenPurposeResetPassword, token, user.Password); err != nil {
+		return ErrInvalidAccountToken
+	}
+
+	reset, err := accountService.UserRepository.ResetPassword(user.ID, user.Password, helpers.Hash(newPassword))
+	if err != nil {
+		return
+	}
+
+	// another reset with the same token won the race
+	if !reset {
+		return ErrInvalidAccountToken
+	}
+
+	return accountService.RefreshTokenRepository.RevokeAllForUser(user.ID, "")
+}
+
+// userOf loads the user a token was issued to, whose state it is verified against
+func (accountService *AccountServiceRepository) userOf(token string) (user domain.User, err error) {
+
+	userID, err := helpers.ParseUserToken(token)
+	if err != nil {
+		return user, ErrInvalidAccountToken
+	}
+
+	user, err = accountService.UserRepository.GetByID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, ErrInvalidAccountToken
+	}
+
+	return
+}
+
+func (accountService *AccountServiceRepository) link(page, token string) string {
+	return strings.TrimRight(accountService.Config.LinkBaseURL, "/") + "/" + page + "?token=" + url.QueryEscape(token)
+}
+
+// formatTTL spells out a token lifetime for the emails, such as 48 hours or 30 minutes
+func formatTTL(ttl time.Duration) string {
+	count, unit := int(ttl/time.Minute), "minute"
+
+	if ttl%time.Hour == 0 {
+		count, unit = int(ttl/time.Hour), "hour"
+	}
+
+	if count == 1 {
+		return "1 " + unit
+	}
+
+	return fmt.Sprintf("%d %ss", count, unit)
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	followRepository "mygram-api/follows/repository"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+	photoRepository "mygram-api/photos/repository"
+	socialMediaRepository "mygram-api/social_medias/repository"
+	"mygram-api/users/repository"
+)
+
+// PublicProfile represents what other users can see about a user
+type PublicProfile struct {
+	User                  domain.User
+	PhotoCount            int64
+	CommentsReceivedCount int64
+	FollowerCount         int64
+	FollowingCount        int64
+	SocialMedias          []domain.SocialMedia
+}
+
+type ProfileService interface {
+	GetPublicProfile(username string) (profile PublicProfile, err error)
+	GetPhotos(username string, pagination request.PaginationRequest) (photos []domain.Photo, total int64, err error)
+}
+
+type ProfileServiceRepository struct {
+	UserRepository        repository.UserRepository
+	PhotoRepository       photoRepository.PhotoRepository
+	SocialMediaRepository socialMediaRepository.SocialMediaRepository
+	FollowRepository      followRepository.FollowRepository
+}
+
+func NewProfileService(userRepository repository.UserRepository, photoRepository photoRepository.PhotoRepository, socialMediaRepository socialMediaRepository.SocialMediaRepository, followRepository followRepository.FollowRepository) ProfileService {
+	return &ProfileServiceRepository{UserRepository: userRepository, PhotoRepository: photoRepository, SocialMediaRepository: socialMediaRepository, FollowRepository: followRepository}
+}
+
+func (profileService *ProfileServiceRepository) GetPublicProfile(username string) (profile PublicProfile, err error) {
+	if profile.User, err = profileService.UserRepository.GetByUsername(username); err != nil {
+		return
+	}
+
+	if profile.PhotoCount, err = profileService.PhotoRepository.CountByUser(profile.User.ID); err != nil {
+		return
+	}
+
+	if profile.CommentsReceivedCount, err = profileService.PhotoRepository.CountCommentsReceivedByUser(profile.User.ID); err != nil {
+		return
+	}
+
+	if profile.FollowerCount, err = profileService.FollowRepository.CountFollowers(profile.User.ID); err != nil {
+		return
+	}
+
+	if profile.FollowingCount, err = profileService.FollowRepository.CountFollowing(profile.User.ID); err != nil {
+		return
+	}
+
+	if profile.SocialMedias, err = profileService.SocialMediaRepository.GetAllByUser(profile.User.ID); err != nil {
+		return
+	}
+
+	return
+}
+
+func (profileService *ProfileServiceRepository) GetPhotos(username string, pagination request.PaginationRequest) (photos []domain.Photo, total int64, err error) {
+	user, err := profileService.UserRepository.GetByUsername(username)
+	if err != nil {
+		return
+	}
+
+	if photos, total, err = profileService.PhotoRepository.GetAllByUser(user.ID, pagination); err != nil {
+		return
+	}
+
+	return
+}
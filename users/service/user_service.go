@@ -1,28 +1,77 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"mygram-api/config"
+	"mygram-api/helpers"
 	"mygram-api/models/domain"
+	"mygram-api/models/request"
+	"mygram-api/storage"
 	"mygram-api/users/repository"
 )
 
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, sign in again")
+	ErrWrongPassword       = errors.New("old password is incorrect")
+	ErrUserBanned          = errors.New("account banned")
+	ErrInvalidRole         = errors.New("role must be one of user, moderator or admin")
+	ErrEmailNotVerified    = errors.New("verify your email before logging in")
+)
+
+// TokenPair holds the credentials handed out on login and refresh
+type TokenPair struct {
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
+
 type UserService interface {
 	Register(user *domain.User) (err error)
 	Login(user *domain.User) (err error)
+	CreateSession(user domain.User) (tokens TokenPair, err error)
+	RefreshSession(refreshToken string) (tokens TokenPair, err error)
+	RevokeSession(refreshToken string) (err error)
+	IsSessionRevoked(sessionID string) bool
+	GetProfile(id uint) (user domain.User, err error)
+	UpdateProfile(user domain.User) (updatedUser domain.User, err error)
+	ChangePassword(id uint, oldPassword, newPassword, currentSessionID string) (err error)
+	DeleteAccount(id uint) (err error)
+	GetAll(pagination request.PaginationRequest) (users []domain.User, total int64, err error)
+	Ban(id uint) (user domain.User, err error)
+	Unban(id uint) (user domain.User, err error)
+	SetRole(id uint, role string) (user domain.User, err error)
 }
 
 type UserServiceRepository struct {
-	UserRepository repository.UserRepository
+	UserRepository         repository.UserRepository
+	RefreshTokenRepository repository.RefreshTokenRepository
+	AccountService         AccountService
+	Storage                storage.Storage
+	JWTConfig              config.JWTConfig
+	AccountsConfig         config.AccountsConfig
 }
 
-func NewUserService(userRepository repository.UserRepository) UserService {
-	return &UserServiceRepository{UserRepository: userRepository}
+func NewUserService(userRepository repository.UserRepository, refreshTokenRepository repository.RefreshTokenRepository, accountService AccountService, storage storage.Storage, jwtConfig config.JWTConfig, accountsConfig config.AccountsConfig) UserService {
+	return &UserServiceRepository{UserRepository: userRepository, RefreshTokenRepository: refreshTokenRepository, AccountService: accountService, Storage: storage, JWTConfig: jwtConfig, AccountsConfig: accountsConfig}
 }
 
+// Register creates the user and mails them a verification link. A link that
+// cannot be queued is logged, the user can ask for another one.
 func (userService *UserServiceRepository) Register(user *domain.User) (err error) {
 	if err = userService.UserRepository.Register(user); err != nil {
 		return err
 	}
 
+	if err := userService.AccountService.SendVerification(*user); err != nil {
+		log.Printf("queueing the verification of user %d: %v", user.ID, err)
+	}
+
 	return
 }
 
@@ -31,5 +80,242 @@ func (userService *UserServiceRepository) Login(user *domain.User) (err error) {
 		return err
 	}
 
+	if user.IsBanned() {
+		return ErrUserBanned
+	}
+
+	if userService.AccountsConfig.RequireVerifiedEmail && !user.IsEmailVerified() {
+		return ErrEmailNotVerified
+	}
+
+	return
+}
+
+// CreateSession starts a new refresh token family for a freshly logged in user
+func (userService *UserServiceRepository) CreateSession(user domain.User) (tokens TokenPair, err error) {
+	familyID, err := helpers.GenerateRandomToken(16)
+	if err != nil {
+		return
+	}
+
+	refreshToken, next, err := userService.newRefreshToken(user.ID, familyID)
+	if err != nil {
+		return
+	}
+
+	if err = userService.RefreshTokenRepository.Create(&next); err != nil {
+		return
+	}
+
+	return userService.tokenPair(user, refreshToken, next)
+}
+
+// RefreshSession exchanges a refresh token for a new pair. Presenting a token
+// that was already exchanged revokes the whole family, since either the
+// client or an attacker is replaying a stolen token.
+func (userService *UserServiceRepository) RefreshSession(refreshToken string) (tokens TokenPair, err error) {
+	current, err := userService.RefreshTokenRepository.GetByHash(helpers.HashToken(refreshToken))
+	if err != nil {
+		return tokens, ErrInvalidRefreshToken
+	}
+
+	if current.RevokedAt != nil || time.Now().After(current.ExpiresAt) {
+		return tokens, ErrInvalidRefreshToken
+	}
+
+	if current.UsedAt != nil {
+		return tokens, userService.revokeReusedFamily(current.FamilyID)
+	}
+
+	if current.User.IsBanned() {
+		return tokens, ErrUserBanned
+	}
+
+	rotated, next, err := userService.newRefreshToken(current.UserID, current.FamilyID)
+	if err != nil {
+		return
+	}
+
+	if err = userService.RefreshTokenRepository.Rotate(current, &next); err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenSpent) {
+			return tokens, userService.revokeReusedFamily(current.FamilyID)
+		}
+
+		return
+	}
+
+	return userService.tokenPair(current.User, rotated, next)
+}
+
+// RevokeSession logs out every token issued from the same login
+func (userService *UserServiceRepository) RevokeSession(refreshToken string) (err error) {
+	current, err := userService.RefreshTokenRepository.GetByHash(helpers.HashToken(refreshToken))
+	if err != nil {
+		return ErrInvalidRefreshToken
+	}
+
+	if err = userService.RefreshTokenRepository.RevokeFamily(current.FamilyID); err != nil {
+		return err
+	}
+
+	return
+}
+
+// IsSessionRevoked fails closed, a lookup error counts as revoked
+func (userService *UserServiceRepository) IsSessionRevoked(sessionID string) bool {
+	revoked, err := userService.RefreshTokenRepository.IsFamilyRevoked(sessionID)
+
+	return err != nil || revoked
+}
+
+func (userService *UserServiceRepository) GetProfile(id uint) (user domain.User, err error) {
+	if user, err = userService.UserRepository.GetByID(id); err != nil {
+		return
+	}
+
+	return
+}
+
+// UpdateProfile stores the profile, and mails a verification link to a new email
+func (userService *UserServiceRepository) UpdateProfile(user domain.User) (updatedUser domain.User, err error) {
+	current, err := userService.UserRepository.GetByID(user.ID)
+	if err != nil {
+		return
+	}
+
+	if updatedUser, err = userService.UserRepository.Update(user); err != nil {
+		return
+	}
+
+	if updatedUser.Email != current.Email {
+		if err := userService.AccountService.SendVerification(updatedUser); err != nil {
+			log.Printf("queueing the verification of user %d: %v", updatedUser.ID, err)
+		}
+	}
+
+	return
+}
+
+// ChangePassword checks the old password, stores the new one and signs out
+// every other session so a leaked password stops working everywhere
+func (userService *UserServiceRepository) ChangePassword(id uint, oldPassword, newPassword, currentSessionID string) (err error) {
+	user, err := userService.UserRepository.GetByID(id)
+	if err != nil {
+		return
+	}
+
+	if !helpers.Compare([]byte(user.Password), []byte(oldPassword)) {
+		return ErrWrongPassword
+	}
+
+	if err = userService.UserRepository.UpdatePassword(id, helpers.Hash(newPassword)); err != nil {
+		return
+	}
+
+	return userService.RefreshTokenRepository.RevokeAllForUser(id, currentSessionID)
+}
+
+// DeleteAccount deletes the user with everything they posted, then the blobs
+// of their photos. The keys are read first as the rows go with the account,
+// and a blob left behind is logged, the account is gone either way.
+func (userService *UserServiceRepository) DeleteAccount(id uint) (err error) {
+	keys, err := userService.UserRepository.GetStorageKeys(id)
+	if err != nil {
+		return
+	}
+
+	if err = userService.UserRepository.Delete(id); err != nil {
+		return
+	}
+
+	for _, key := range keys {
+		if err := userService.Storage.Delete(context.Background(), key); err != nil {
+			log.Printf("deleting blob %s of user %d: %v", key, id, err)
+		}
+	}
+
+	return
+}
+
+func (userService *UserServiceRepository) GetAll(pagination request.PaginationRequest) (users []domain.User, total int64, err error) {
+	if users, total, err = userService.UserRepository.GetAll(pagination); err != nil {
+		return
+	}
+
+	return
+}
+
+// Ban blocks the user from signing in and revokes every session, which also
+// invalidates the access tokens already handed out
+func (userService *UserServiceRepository) Ban(id uint) (user domain.User, err error) {
+	bannedAt := time.Now()
+
+	if user, err = userService.UserRepository.SetBannedAt(id, &bannedAt); err != nil {
+		return
+	}
+
+	err = userService.RefreshTokenRepository.RevokeAllForUser(id, "")
+
+	return
+}
+
+func (userService *UserServiceRepository) Unban(id uint) (user domain.User, err error) {
+	if user, err = userService.UserRepository.SetBannedAt(id, nil); err != nil {
+		return
+	}
+
+	return
+}
+
+// SetRole changes the role of a user and revokes their sessions, so the
+// roles carried by tokens already handed out stop being honoured
+func (userService *UserServiceRepository) SetRole(id uint, role string) (user domain.User, err error) {
+	if !domain.IsValidRole(role) {
+		return user, ErrInvalidRole
+	}
+
+	if user, err = userService.UserRepository.SetRole(id, role); err != nil {
+		return
+	}
+
+	err = userService.RefreshTokenRepository.RevokeAllForUser(id, "")
+
+	return
+}
+
+func (userService *UserServiceRepository) revokeReusedFamily(familyID string) error {
+	if err := userService.RefreshTokenRepository.RevokeFamily(familyID); err != nil {
+		return err
+	}
+
+	return ErrRefreshTokenReused
+}
+
+func (userService *UserServiceRepository) newRefreshToken(userID uint, familyID string) (token string, refreshToken domain.RefreshToken, err error) {
+	if token, err = helpers.GenerateRandomToken(32); err != nil {
+		return
+	}
+
+	refreshToken = domain.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: helpers.HashToken(token),
+		ExpiresAt: time.Now().Add(userService.JWTConfig.RefreshTokenTTL),
+	}
+
 	return
-}
\ No newline at end of file
+}
+
+func (userService *UserServiceRepository) tokenPair(user domain.User, refreshToken string, stored domain.RefreshToken) (tokens TokenPair, err error) {
+	accessToken, expiresAt, err := helpers.GenerateToken(user.ID, user.Email, []string{user.Role}, stored.FamilyID, userService.JWTConfig)
+	if err != nil {
+		return
+	}
+
+	return TokenPair{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  expiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: stored.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mygram-api/config"
+	"mygram-api/storage"
+	"mygram-api/users/repository"
+)
+
+// leavingUsers holds the blob keys of a single user, the methods the tests do
+// not use are left to the nil interface
+type leavingUsers struct {
+	repository.UserRepository
+
+	keys    []string
+	deleted bool
+}
+
+func (users *leavingUsers) GetStorageKeys(uint) ([]string, error) {
+	return users.keys, nil
+}
+
+func (users *leavingUsers) Delete(uint) error {
+	users.deleted = true
+	return nil
+}
+
+func TestDeleteAccountDeletesTheBlobs(t *testing.T) {
+	blobStorage, err := storage.NewLocalStorage(t.TempDir(), "http://localhost:8080/uploads")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	keys := []string{"photos/1/a.jpg", "photos/1/a_thumb.jpg", "photos/1/a_medium.webp"}
+
+	for _, key := range append(keys, "photos/2/b.jpg") {
+		if err = blobStorage.Put(ctx, key, []byte("image"), "image/jpeg"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a blob already missing does not stop the others from being deleted
+	users := &leavingUsers{keys: append([]string{"photos/1/gone.jpg"}, keys...)}
+	userService := NewUserService(users, nil, nil, blobStorage, config.JWTConfig{}, config.AccountsConfig{})
+
+	if err = userService.DeleteAccount(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if !users.deleted {
+		t.Fatal("the account was not deleted")
+	}
+
+	for _, key := range keys {
+		if _, err := blobStorage.Get(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("Get(%s) = %v, want ErrNotFound", key, err)
+		}
+	}
+
+	body, err := blobStorage.Get(ctx, "photos/2/b.jpg")
+	if err != nil {
+		t.Fatalf("the blob of another user was deleted: %v", err)
+	}
+	body.Close()
+}
@@ -0,0 +1,357 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+
+	"mygram-api/auth"
+	"mygram-api/helpers"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+	"mygram-api/models/response"
+	"mygram-api/webhooks/service"
+)
+
+type WebhookController interface {
+	Create(c *gin.Context)
+	GetAll(c *gin.Context)
+	GetOne(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+	GetDeliveries(c *gin.Context)
+	Redeliver(c *gin.Context)
+}
+
+type WebhookControllerService struct {
+	WebhookService service.WebhookService
+}
+
+func NewWebhookController(webhookService service.WebhookService) WebhookController {
+	return &WebhookControllerService{WebhookService: webhookService}
+}
+
+// Create webhook godoc
+// @Summary Register a webhook
+// @Description Register an endpoint of the authentication user that is posted the subscribed events, signed with its secret.
+// @Description Events are photo.created, photo.updated, photo.deleted, comment.created, comment.updated, comment.deleted, social_media.created, social_media.updated and social_media.deleted.
+// @Description The secret is generated when left empty and only returned here.
+// @Tags webhooks
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param json body request.WebhookCreateRequest true "Register Webhook"
+// @Success 201 {object} response.SuccessResponse{data=response.WebhookCreateResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /webhooks [post]
+func (webhookController *WebhookControllerService) Create(c *gin.Context) {
+
+	var req request.WebhookCreateRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	webhook := domain.Webhook{
+		UserID: auth.MustGetPrincipal(c).UserID,
+		URL:    req.URL,
+		Secret: req.Secret,
+		Events: domain.JoinWebhookEvents(req.Events),
+		Active: req.Active == nil || *req.Active,
+	}
+
+	if err := webhookController.WebhookService.Create(&webhook); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.SuccessResponse{
+		Data: response.WebhookCreateResponse{
+			WebhookResponse: response.NewWebhookResponse(webhook),
+			Secret:          webhook.Secret,
+		},
+	})
+}
+
+// GetAll webhook godoc
+// @Summary Get the webhooks
+// @Description Get the webhooks of the authentication user, oldest first
+// @Tags webhooks
+// @Security Bearer
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=[]response.WebhookResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Router /webhooks [get]
+func (webhookController *WebhookControllerService) GetAll(c *gin.Context) {
+
+	webhooks, err := webhookController.WebhookService.GetAll(auth.MustGetPrincipal(c).UserID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	webhooksResponse := make([]response.WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		webhooksResponse = append(webhooksResponse, response.NewWebhookResponse(webhook))
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: webhooksResponse,
+	})
+}
+
+// GetOne webhook godoc
+// @Summary Get a webhook
+// @Description Get a webhook of the authentication user by id
+// @Tags webhooks
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} response.SuccessResponse{data=response.WebhookResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /webhooks/{id} [get]
+func (webhookController *WebhookControllerService) GetOne(c *gin.Context) {
+
+	webhookID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	webhook, err := webhookController.WebhookService.GetOne(uint(webhookID))
+	if err != nil {
+		abortWebhookNotFound(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.NewWebhookResponse(webhook),
+	})
+}
+
+// Update webhook godoc
+// @Summary Update a webhook
+// @Description Replace the url, events and active flag of a webhook of the authentication user, and its secret when set
+// @Tags webhooks
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param json body request.WebhookUpdateRequest true "Update Webhook"
+// @Success 200 {object} response.SuccessResponse{data=response.WebhookResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /webhooks/{id} [put]
+func (webhookController *WebhookControllerService) Update(c *gin.Context) {
+
+	var req request.WebhookUpdateRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	webhookID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	webhook, err := webhookController.WebhookService.Update(domain.Webhook{
+		ID:     uint(webhookID),
+		URL:    req.URL,
+		Secret: req.Secret,
+		Events: domain.JoinWebhookEvents(req.Events),
+		Active: *req.Active,
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: response.NewWebhookResponse(webhook),
+	})
+}
+
+// Delete webhook godoc
+// @Summary Delete a webhook
+// @Description Delete a webhook of the authentication user with its deliveries
+// @Tags webhooks
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /webhooks/{id} [delete]
+func (webhookController *WebhookControllerService) Delete(c *gin.Context) {
+
+	webhookID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	if err := webhookController.WebhookService.Delete(uint(webhookID)); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data: gin.H{
+			"message": "Your webhook has been successfully deleted",
+		},
+	})
+}
+
+// GetDeliveries webhook godoc
+// @Summary Get the deliveries of a webhook
+// @Description Get a page of the deliveries of a webhook of the authentication user, newest first, with their payload and the outcome of their last attempt
+// @Tags webhooks
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "Cursor of the next page from a previous response"
+// @Success 200 {object} response.SuccessResponse{data=[]response.WebhookDeliveryResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /webhooks/{id}/deliveries [get]
+func (webhookController *WebhookControllerService) GetDeliveries(c *gin.Context) {
+
+	var list request.CursorRequest
+
+	if err := c.ShouldBindQuery(&list); err != nil {
+		abortWithBindError(c, err)
+		return
+	}
+
+	webhookID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	deliveries, page, err := webhookController.WebhookService.GetDeliveries(uint(webhookID), list.ListRequest())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	deliveriesResponse := make([]response.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		deliveriesResponse = append(deliveriesResponse, response.NewWebhookDeliveryResponse(delivery))
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse{
+		Data:       deliveriesResponse,
+		Pagination: response.NewListPaginationResponse(list.ListRequest(), page.Total, page.NextCursor),
+	})
+}
+
+// Redeliver webhook godoc
+// @Summary Redeliver an event to a webhook
+// @Description Queue a new delivery of the payload of a previous delivery of a webhook of the authentication user
+// @Tags webhooks
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param deliveryId path int true "Delivery ID"
+// @Success 202 {object} response.SuccessResponse{data=response.WebhookDeliveryResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func (webhookController *WebhookControllerService) Redeliver(c *gin.Context) {
+
+	webhookID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	deliveryID, _ := strconv.ParseUint(c.Param("deliveryId"), 10, 32)
+
+	delivery, err := webhookController.WebhookService.Redeliver(uint(webhookID), uint(deliveryID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.ErrorResponse{
+			Code:   http.StatusNotFound,
+			Status: "Not Found",
+			Errors: gin.H{
+				"message": "Delivery not found",
+			},
+		})
+
+		return
+	}
+
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.SuccessResponse{
+		Data: response.NewWebhookDeliveryResponse(delivery),
+	})
+}
+
+func abortWebhookNotFound(c *gin.Context) {
+
+	c.AbortWithStatusJSON(http.StatusNotFound, response.ErrorResponse{
+		Code:   http.StatusNotFound,
+		Status: "Not Found",
+		Errors: gin.H{
+			"message": "Webhook not found",
+		},
+	})
+}
+
+func abortWithBindError(c *gin.Context, err error) {
+
+	validationError, ok := err.(validator.ValidationErrors)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Errors: err.Error(),
+		})
+
+		return
+	}
+
+	fieldErrorResponse := make(map[string]interface{})
+
+	for _, v := range validationError {
+		fieldErrorResponse[strings.ToLower(v.Field())] = helpers.GetValidationErrorMsg(v)
+	}
+
+	c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+		Code:   http.StatusBadRequest,
+		Status: "Bad Request",
+		Errors: fieldErrorResponse,
+	})
+}
@@ -0,0 +1,51 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"mygram-api/auth"
+	"mygram-api/models/domain"
+	"mygram-api/models/response"
+	"mygram-api/webhooks/service"
+)
+
+// Authorization lets the owner of a webhook through, and no one else as the
+// webhook and its deliveries reveal where and what the owner syncs
+func Authorization(webhookService service.WebhookService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var (
+			webhook domain.Webhook
+			err     error
+		)
+
+		webhookID, _ := strconv.ParseUint(ctx.Param("id"), 10, 32)
+		principal := auth.MustGetPrincipal(ctx)
+
+		if webhook, err = webhookService.GetOne(uint(webhookID)); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, response.ErrorResponse{
+				Code:   http.StatusNotFound,
+				Status: "Not Found",
+				Errors: gin.H{
+					"message": "Webhook not found",
+				},
+			})
+
+			return
+		}
+
+		if webhook.UserID != principal.UserID {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, response.ErrorResponse{
+				Code:   http.StatusForbidden,
+				Status: "Forbidden",
+				Errors: gin.H{
+					"message": "You don't have permission",
+				},
+			})
+
+			return
+		}
+	}
+}
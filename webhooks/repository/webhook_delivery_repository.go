@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"mygram-api/database"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+)
+
+type WebhookDeliveryRepository interface {
+	Create(deliveries []domain.WebhookDelivery) (err error)
+	GetAllByWebhook(webhookID uint, list request.ListRequest) (deliveries []domain.WebhookDelivery, page database.Page, err error)
+	GetOne(webhookID, id uint) (delivery domain.WebhookDelivery, err error)
+	GetWithWebhook(id uint) (delivery domain.WebhookDelivery, err error)
+	Finish(delivery domain.WebhookDelivery) (err error)
+	GetOverdue(before time.Time, limit int) (deliveries []domain.WebhookDelivery, err error)
+}
+
+type WebhookDeliveryRepositoryDB struct {
+	DB *gorm.DB
+}
+
+func NewWebhookDeliveryRepository(db *gorm.DB) WebhookDeliveryRepository {
+	return &WebhookDeliveryRepositoryDB{DB: db}
+}
+
+func (webhookDeliveryRepository *WebhookDeliveryRepositoryDB) Create(deliveries []domain.WebhookDelivery) (err error) {
+
+	if len(deliveries) == 0 {
+		return
+	}
+
+	if err = webhookDeliveryRepository.DB.Create(&deliveries).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// GetAllByWebhook lists the deliveries of a webhook, newest first
+func (webhookDeliveryRepository *WebhookDeliveryRepositoryDB) GetAllByWebhook(webhookID uint, list request.ListRequest) (deliveries []domain.WebhookDelivery, page database.Page, err error) {
+
+	query := webhookDeliveryRepository.DB.Model(&domain.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+
+	// deliveries have no user_id to filter on
+	list.UserID = 0
+
+	return database.Paginate(query, list, func(delivery domain.WebhookDelivery) request.Cursor {
+		return request.Cursor{CreatedAt: delivery.CreatedAt, ID: delivery.ID}
+	})
+}
+
+// GetOne loads a delivery of the given webhook
+func (webhookDeliveryRepository *WebhookDeliveryRepositoryDB) GetOne(webhookID, id uint) (delivery domain.WebhookDelivery, err error) {
+
+	if err = webhookDeliveryRepository.DB.Where("webhook_id = ?", webhookID).First(&delivery, id).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// GetWithWebhook loads a delivery with its webhook, to send it
+func (webhookDeliveryRepository *WebhookDeliveryRepositoryDB) GetWithWebhook(id uint) (delivery domain.WebhookDelivery, err error) {
+
+	if err = webhookDeliveryRepository.DB.Preload("Webhook").First(&delivery, id).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// Finish stores the outcome of an attempt
+func (webhookDeliveryRepository *WebhookDeliveryRepositoryDB) Finish(delivery domain.WebhookDelivery) (err error) {
+
+	if err = webhookDeliveryRepository.DB.Model(&delivery).
+		Select("status", "attempts", "next_attempt_at", "last_attempt_at", "response_status", "response_body", "last_error").
+		Updates(delivery).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// GetOverdue lists up to limit pending deliveries due before the given time,
+// longest overdue first
+func (webhookDeliveryRepository *WebhookDeliveryRepositoryDB) GetOverdue(before time.Time, limit int) (deliveries []domain.WebhookDelivery, err error) {
+
+	if err = webhookDeliveryRepository.DB.Select("id", "attempts", "next_attempt_at").
+		Where("status = ? AND next_attempt_at < ?", domain.WebhookDeliveryPending, before).
+		Order("next_attempt_at").Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return
+	}
+
+	return
+}
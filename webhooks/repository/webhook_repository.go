@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"mygram-api/models/domain"
+)
+
+type WebhookRepository interface {
+	Create(webhook *domain.Webhook) (err error)
+	GetAllByUser(userID uint) (webhooks []domain.Webhook, err error)
+	GetSubscribed(userIDs []uint, event string) (webhooks []domain.Webhook, err error)
+	GetOne(id uint) (webhook domain.Webhook, err error)
+	Update(webhook domain.Webhook) (updatedWebhook domain.Webhook, err error)
+	Delete(id uint) (err error)
+	CountByUser(userID uint) (count int64, err error)
+}
+
+type WebhookRepositoryDB struct {
+	DB *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &WebhookRepositoryDB{DB: db}
+}
+
+func (webhookRepository *WebhookRepositoryDB) Create(webhook *domain.Webhook) (err error) {
+
+	if err = webhookRepository.DB.Create(webhook).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// GetAllByUser lists the webhooks of userID, oldest first
+func (webhookRepository *WebhookRepositoryDB) GetAllByUser(userID uint) (webhooks []domain.Webhook, err error) {
+
+	if err = webhookRepository.DB.Where("user_id = ?", userID).Order("id").Find(&webhooks).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// GetSubscribed lists the active webhooks of userIDs subscribed to event
+func (webhookRepository *WebhookRepositoryDB) GetSubscribed(userIDs []uint, event string) (webhooks []domain.Webhook, err error) {
+
+	if err = webhookRepository.DB.
+		Where("user_id IN ? AND active AND ? = ANY (string_to_array(events, ','))", userIDs, event).
+		Find(&webhooks).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+func (webhookRepository *WebhookRepositoryDB) GetOne(id uint) (webhook domain.Webhook, err error) {
+
+	if err = webhookRepository.DB.First(&webhook, id).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+// Update replaces the url, events and active flag of a webhook, and its secret when set
+func (webhookRepository *WebhookRepositoryDB) Update(webhook domain.Webhook) (updatedWebhook domain.Webhook, err error) {
+
+	if err = webhookRepository.DB.First(&updatedWebhook, webhook.ID).Error; err != nil {
+		return
+	}
+
+	columns := []string{"url", "events", "active"}
+	if webhook.Secret != "" {
+		columns = append(columns, "secret")
+	}
+
+	// Select writes active even when it is turned off
+	if err = webhookRepository.DB.Model(&updatedWebhook).Select(columns).Updates(webhook).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+func (webhookRepository *WebhookRepositoryDB) Delete(id uint) (err error) {
+
+	if err = webhookRepository.DB.Delete(&domain.Webhook{}, id).Error; err != nil {
+		return
+	}
+
+	return
+}
+
+func (webhookRepository *WebhookRepositoryDB) CountByUser(userID uint) (count int64, err error) {
+
+	if err = webhookRepository.DB.Model(&domain.Webhook{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"mygram-api/config"
+	"mygram-api/models/domain"
+	"mygram-api/webhooks/repository"
+)
+
+const (
+	retryBaseDelay = time.Minute
+	maxRetryDelay  = 6 * time.Hour
+
+	// maxResponseBody bounds the part of the answer of an endpoint kept in the delivery log
+	maxResponseBody = 1024
+)
+
+var (
+	ErrPrivateAddress  = errors.New("webhook address is on a private network")
+	errWebhookDisabled = errors.New("webhook was disabled before the delivery")
+)
+
+// carrierGradeNAT is shared address space that is as internal as the private ranges
+var carrierGradeNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// DeliveryService sends the queued deliveries to their webhooks
+type DeliveryService interface {
+	Deliver(ctx context.Context, deliveryID uint) (err error)
+	OverdueDeliveries(before time.Time, limit int) (deliveries []domain.WebhookDelivery, err error)
+}
+
+type DeliveryServiceRepository struct {
+	WebhookDeliveryRepository repository.WebhookDeliveryRepository
+	DeliveryScheduler         DeliveryScheduler
+	Client                    *http.Client
+	Config                    config.WebhooksConfig
+}
+
+func NewDeliveryService(webhookDeliveryRepository repository.WebhookDeliveryRepository, deliveryScheduler DeliveryScheduler, webhooksConfig config.WebhooksConfig) DeliveryService {
+	return &DeliveryServiceRepository{WebhookDeliveryRepository: webhookDeliveryRepository, DeliveryScheduler: deliveryScheduler, Client: newClient(webhooksConfig), Config: webhooksConfig}
+}
+
+// Deliver posts a pending delivery to its webhook and records the outcome. A
+// failed attempt is scheduled again with a delay doubling each time, until
+// the configured number of attempts is reached and the delivery is marked
+// failed. A delivery that is no longer pending, sent by an earlier run of the
+// same job, is left alone.
+func (deliveryService *DeliveryServiceRepository) Deliver(ctx context.Context, deliveryID uint) (err error) {
+
+	delivery, err := deliveryService.WebhookDeliveryRepository.GetWithWebhook(deliveryID)
+	if err != nil {
+		return
+	}
+
+	if delivery.Status != domain.WebhookDeliveryPending {
+		return
+	}
+
+	// an earlier run of the same job recorded its attempt but failed to
+	// schedule the next one, which is scheduled again instead of sent twice
+	if delivery.NextAttemptAt.After(time.Now()) {
+		return deliveryService.DeliveryScheduler.ScheduleDelivery(delivery)
+	}
+
+	// a webhook disabled since the delivery was queued is not sent to anymore
+	if !delivery.Webhook.Active {
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.LastError = errWebhookDisabled.Error()
+
+		return deliveryService.WebhookDeliveryRepository.Finish(delivery)
+	}
+
+	now := time.Now()
+
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+
+	status, body, sendErr := deliveryService.send(ctx, delivery, now)
+
+	// an attempt cut short by the shutdown is not counted, the job is run again
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	delivery.ResponseStatus = status
+	delivery.ResponseBody = body
+
+	switch {
+	case sendErr == nil:
+		delivery.Status = domain.WebhookDeliverySucceeded
+		delivery.LastError = ""
+	case delivery.Attempts >= deliveryService.Config.MaxAttempts:
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.LastError = sendErr.Error()
+	default:
+		delivery.LastError = sendErr.Error()
+		delivery.NextAttemptAt = now.Add(retryDelay(delivery.Attempts))
+	}
+
+	if err = deliveryService.WebhookDeliveryRepository.Finish(delivery); err != nil {
+		return
+	}
+
+	if delivery.Status == domain.WebhookDeliveryPending {
+		if err = deliveryService.DeliveryScheduler.ScheduleDelivery(delivery); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// OverdueDeliveries lists the pending deliveries that should have been sent
+// before the given time, such as the ones whose job could not be queued
+func (deliveryService *DeliveryServiceRepository) OverdueDeliveries(before time.Time, limit int) (deliveries []domain.WebhookDelivery, err error) {
+
+	if deliveries, err = deliveryService.WebhookDeliveryRepository.GetOverdue(before, limit); err != nil {
+		return
+	}
+
+	return
+}
+
+func (deliveryService *DeliveryServiceRepository) send(ctx context.Context, delivery domain.WebhookDelivery, now time.Time) (status int, body string, err error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MyGram-Webhooks/1.0")
+	req.Header.Set("X-MyGram-Event", delivery.Event)
+	req.Header.Set("X-MyGram-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-MyGram-Timestamp", timestamp)
+	req.Header.Set("X-MyGram-Signature", "sha256="+Signature(delivery.Webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := deliveryService.Client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	read, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+
+	// postgres text takes neither invalid UTF-8 nor NUL
+	body = strings.ReplaceAll(strings.ToValidUTF8(string(read), "�"), "\x00", "")
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, body, fmt.Errorf("endpoint answered %s", resp.Status)
+	}
+
+	return resp.StatusCode, body, nil
+}
+
+// Signature returns the hex HMAC-SHA256, keyed by the secret of the webhook,
+// of the timestamp and the payload joined by a dot. Receivers recompute it to
+// check a delivery comes from us, and reject old timestamps against replays.
+func Signature(secret, timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryDelay doubles from retryBaseDelay after each failed attempt
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	return delay
+}
+
+// newClient builds a client that follows no redirects and, unless private
+// networks are allowed, refuses to connect to internal addresses. The address
+// is checked once resolved, when connecting, so a DNS name cannot point the
+// check elsewhere, and no proxy is used so that it is the address checked.
+func newClient(webhooksConfig config.WebhooksConfig) *http.Client {
+	dialer := &net.Dialer{Timeout: webhooksConfig.Timeout}
+
+	if !webhooksConfig.AllowPrivateNetworks {
+		dialer.Control = rejectPrivateAddress
+	}
+
+	return &http.Client{
+		Timeout: webhooksConfig.Timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			TLSHandshakeTimeout: webhooksConfig.Timeout,
+			MaxIdleConnsPerHost: 2,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func rejectPrivateAddress(network, address string, _ syscall.RawConn) error {
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsMulticast() || ip.IsLinkLocalMulticast() || carrierGradeNAT.Contains(ip) {
+		return ErrPrivateAddress
+	}
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mygram-api/config"
+	"mygram-api/database"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+)
+
+// memoryDeliveries keeps a single delivery in memory
+type memoryDeliveries struct {
+	delivery domain.WebhookDelivery
+}
+
+func (deliveries *memoryDeliveries) Create([]domain.WebhookDelivery) error { return nil }
+
+func (deliveries *memoryDeliveries) GetAllByWebhook(uint, request.ListRequest) ([]domain.WebhookDelivery, database.Page, error) {
+	return nil, database.Page{}, nil
+}
+
+func (deliveries *memoryDeliveries) GetOne(uint, uint) (domain.WebhookDelivery, error) {
+	return deliveries.delivery, nil
+}
+
+func (deliveries *memoryDeliveries) GetWithWebhook(uint) (domain.WebhookDelivery, error) {
+	return deliveries.delivery, nil
+}
+
+func (deliveries *memoryDeliveries) Finish(delivery domain.WebhookDelivery) error {
+	delivery.Webhook = deliveries.delivery.Webhook
+	deliveries.delivery = delivery
+	return nil
+}
+
+func (deliveries *memoryDeliveries) GetOverdue(time.Time, int) ([]domain.WebhookDelivery, error) {
+	return nil, nil
+}
+
+// flakyScheduler fails while failing is set and records what it scheduled
+type flakyScheduler struct {
+	failing   bool
+	scheduled []domain.WebhookDelivery
+}
+
+func (scheduler *flakyScheduler) ScheduleDelivery(delivery domain.WebhookDelivery) error {
+	if scheduler.failing {
+		return errors.New("jobs table unavailable")
+	}
+
+	scheduler.scheduled = append(scheduler.scheduled, delivery)
+	return nil
+}
+
+func TestDeliverRetryAfterASchedulingFailureDoesNotResend(t *testing.T) {
+	var received atomic.Int32
+
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer endpoint.Close()
+
+	deliveries := &memoryDeliveries{delivery: domain.WebhookDelivery{
+		ID:            1,
+		Status:        domain.WebhookDeliveryPending,
+		Payload:       `{}`,
+		NextAttemptAt: time.Now(),
+		Webhook:       domain.Webhook{URL: endpoint.URL, Secret: "secret", Active: true},
+	}}
+	scheduler := &flakyScheduler{failing: true}
+
+	deliveryService := NewDeliveryService(deliveries, scheduler, config.WebhooksConfig{
+		MaxAttempts:          5,
+		Timeout:              time.Second,
+		AllowPrivateNetworks: true,
+	})
+
+	if err := deliveryService.Deliver(context.Background(), 1); err == nil {
+		t.Fatal("Deliver hid the scheduling failure, the job would not be retried")
+	}
+
+	if deliveries.delivery.Attempts != 1 {
+		t.Fatalf("Attempts = %d, want the failed attempt recorded", deliveries.delivery.Attempts)
+	}
+
+	// the job is retried once the jobs table is back
+	scheduler.failing = false
+
+	if err := deliveryService.Deliver(context.Background(), 1); err != nil {
+		t.Fatalf("retrying the job: %v", err)
+	}
+
+	if got := received.Load(); got != 1 {
+		t.Errorf("endpoint received %d requests, want 1", got)
+	}
+
+	if len(scheduler.scheduled) != 1 || scheduler.scheduled[0].Attempts != 1 {
+		t.Fatalf("scheduled %+v, want the second attempt once", scheduler.scheduled)
+	}
+
+	if !scheduler.scheduled[0].NextAttemptAt.Equal(deliveries.delivery.NextAttemptAt) {
+		t.Errorf("scheduled at %v, want the recorded next attempt %v", scheduler.scheduled[0].NextAttemptAt, deliveries.delivery.NextAttemptAt)
+	}
+}
+
+func TestSignature(t *testing.T) {
+	tests := []struct {
+		secret, timestamp, payload string
+		want                       string
+	}{
+		// computed independently with hmac.new(secret, timestamp + "." + payload, sha256)
+		{"whsec_test", "1690891200", `{"event":"photo.created"}`, "a8ab0cf31f66e3bca872a3bc34f8c58795cf59e8b5ca4a3b9aa1cc142215294c"},
+		{"whsec_test", "1690891201", `{"event":"photo.created"}`, "ffcbe3ddaa3abaf0fcf067a561cfd844639ad73e2c34a452c8cb26fe4f033535"},
+		{"", "", "", "0d0ab78babcce47b6860946aad720dcc13630f70074364b65665c4caefb81ecf"},
+	}
+
+	for _, test := range tests {
+		if got := Signature(test.secret, test.timestamp, test.payload); got != test.want {
+			t.Errorf("Signature(%q, %q, %q) = %s, want %s", test.secret, test.timestamp, test.payload, got, test.want)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, time.Minute},
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{9, 256 * time.Minute},
+		{10, 6 * time.Hour},
+		{1000, 6 * time.Hour},
+	}
+
+	for _, test := range tests {
+		if got := retryDelay(test.attempts); got != test.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", test.attempts, got, test.want)
+		}
+	}
+}
+
+func TestRejectPrivateAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		private bool
+	}{
+		{"93.184.216.34:443", false},
+		{"[2606:2800:220:1:248:1893:25c8:1946]:443", false},
+		{"127.0.0.1:80", true},
+		{"[::1]:80", true},
+		{"10.1.2.3:80", true},
+		{"172.16.0.1:80", true},
+		{"192.168.1.1:80", true},
+		{"169.254.169.254:80", true},
+		{"100.64.0.1:80", true},
+		{"0.0.0.0:80", true},
+		{"[::]:80", true},
+		{"[fd00::1]:80", true},
+		{"[fe80::1]:80", true},
+		{"224.0.0.1:80", true},
+		{"[::ffff:127.0.0.1]:80", true},
+		{"[::ffff:10.0.0.1]:80", true},
+		// an unresolved name is refused rather than trusted
+		{"localhost:80", true},
+	}
+
+	for _, test := range tests {
+		err := rejectPrivateAddress("tcp", test.address, nil)
+		if private := errors.Is(err, ErrPrivateAddress); private != test.private {
+			t.Errorf("rejectPrivateAddress(%q) = %v, want private %t", test.address, err, test.private)
+		}
+	}
+
+	if err := rejectPrivateAddress("tcp", "no port", nil); err == nil {
+		t.Error("rejectPrivateAddress accepted an address without a port")
+	}
+}
+
+func TestClientRefusesPrivateNetworks(t *testing.T) {
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the client reached a loopback endpoint")
+	}))
+	defer endpoint.Close()
+
+	client := newClient(config.WebhooksConfig{Timeout: time.Second})
+
+	if _, err := client.Get(endpoint.URL); !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("Get(%s) = %v, want ErrPrivateAddress", endpoint.URL, err)
+	}
+}
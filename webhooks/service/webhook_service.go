@@ -0,0 +1,263 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"mygram-api/database"
+	"mygram-api/helpers"
+	"mygram-api/models/domain"
+	"mygram-api/models/request"
+	"mygram-api/models/response"
+	photoRepository "mygram-api/photos/repository"
+	"mygram-api/webhooks/repository"
+)
+
+// maxWebhooksPerUser keeps one user from fanning every event out to many endpoints
+const maxWebhooksPerUser = 10
+
+var (
+	ErrUnknownEvent      = errors.New("unknown webhook event")
+	ErrTooManyWebhooks   = errors.New("a user can register at most 10 webhooks")
+	ErrInvalidWebhookURL = errors.New("webhook url must be an absolute http or https url")
+)
+
+// DeliveryScheduler queues the sending of a delivery, at its first attempt
+// as well as at each retry
+type DeliveryScheduler interface {
+	ScheduleDelivery(delivery domain.WebhookDelivery) (err error)
+}
+
+// WebhookService manages the webhooks of users and queues a delivery to each
+// webhook subscribed to an event. The photo, comment and social media events
+// are best-effort: a delivery that cannot be queued is logged and does not
+// fail the action that caused it.
+type WebhookService interface {
+	Create(webhook *domain.Webhook) (err error)
+	GetAll(userID uint) (webhooks []domain.Webhook, err error)
+	GetOne(id uint) (webhook domain.Webhook, err error)
+	Update(webhook domain.Webhook) (updatedWebhook domain.Webhook, err error)
+	Delete(id uint) (err error)
+	GetDeliveries(webhookID uint, list request.ListRequest) (deliveries []domain.WebhookDelivery, page database.Page, err error)
+	Redeliver(webhookID, deliveryID uint) (delivery domain.WebhookDelivery, err error)
+
+	PhotoEvent(event string, photo domain.Photo)
+	CommentEvent(event string, comment domain.Comment)
+	SocialMediaEvent(event string, socialMedia domain.SocialMedia)
+}
+
+type WebhookServiceRepository struct {
+	WebhookRepository         repository.WebhookRepository
+	WebhookDeliveryRepository repository.WebhookDeliveryRepository
+	PhotoRepository           photoRepository.PhotoRepository
+	DeliveryScheduler         DeliveryScheduler
+}
+
+func NewWebhookService(webhookRepository repository.WebhookRepository, webhookDeliveryRepository repository.WebhookDeliveryRepository, photoRepository photoRepository.PhotoRepository, deliveryScheduler DeliveryScheduler) WebhookService {
+	return &WebhookServiceRepository{WebhookRepository: webhookRepository, WebhookDeliveryRepository: webhookDeliveryRepository, PhotoRepository: photoRepository, DeliveryScheduler: deliveryScheduler}
+}
+
+// Create registers a webhook, generating its secret when none is given
+func (webhookService *WebhookServiceRepository) Create(webhook *domain.Webhook) (err error) {
+
+	if err = validateWebhook(*webhook); err != nil {
+		return
+	}
+
+	count, err := webhookService.WebhookRepository.CountByUser(webhook.UserID)
+	if err != nil {
+		return
+	}
+
+	if count >= maxWebhooksPerUser {
+		return ErrTooManyWebhooks
+	}
+
+	if webhook.Secret == "" {
+		if webhook.Secret, err = helpers.GenerateRandomToken(32); err != nil {
+			return
+		}
+	}
+
+	if err = webhookService.WebhookRepository.Create(webhook); err != nil {
+		return
+	}
+
+	return
+}
+
+func (webhookService *WebhookServiceRepository) GetAll(userID uint) (webhooks []domain.Webhook, err error) {
+
+	if webhooks, err = webhookService.WebhookRepository.GetAllByUser(userID); err != nil {
+		return
+	}
+
+	return
+}
+
+func (webhookService *WebhookServiceRepository) GetOne(id uint) (webhook domain.Webhook, err error) {
+
+	if webhook, err = webhookService.WebhookRepository.GetOne(id); err != nil {
+		return
+	}
+
+	return
+}
+
+// Update replaces the url, events and active flag of a webhook, and rotates
+// its secret when a new one is given
+func (webhookService *WebhookServiceRepository) Update(webhook domain.Webhook) (updatedWebhook domain.Webhook, err error) {
+
+	if err = validateWebhook(webhook); err != nil {
+		return
+	}
+
+	if updatedWebhook, err = webhookService.WebhookRepository.Update(webhook); err != nil {
+		return
+	}
+
+	return
+}
+
+func (webhookService *WebhookServiceRepository) Delete(id uint) (err error) {
+
+	if err = webhookService.WebhookRepository.Delete(id); err != nil {
+		return
+	}
+
+	return
+}
+
+func (webhookService *WebhookServiceRepository) GetDeliveries(webhookID uint, list request.ListRequest) (deliveries []domain.WebhookDelivery, page database.Page, err error) {
+
+	if deliveries, page, err = webhookService.WebhookDeliveryRepository.GetAllByWebhook(webhookID, list); err != nil {
+		return
+	}
+
+	return
+}
+
+// Redeliver queues a new delivery of the payload of a previous one, which is
+// kept in the log as it was
+func (webhookService *WebhookServiceRepository) Redeliver(webhookID, deliveryID uint) (delivery domain.WebhookDelivery, err error) {
+
+	previous, err := webhookService.WebhookDeliveryRepository.GetOne(webhookID, deliveryID)
+	if err != nil {
+		return
+	}
+
+	delivery = domain.WebhookDelivery{
+		WebhookID:     webhookID,
+		Event:         previous.Event,
+		Payload:       previous.Payload,
+		Status:        domain.WebhookDeliveryPending,
+		NextAttemptAt: time.Now(),
+	}
+
+	deliveries := []domain.WebhookDelivery{delivery}
+	if err = webhookService.WebhookDeliveryRepository.Create(deliveries); err != nil {
+		return
+	}
+
+	// the delivery is stored, failing the request would only get it queued twice
+	if err = webhookService.DeliveryScheduler.ScheduleDelivery(deliveries[0]); err != nil {
+		log.Printf("queueing delivery %d, left to the sweep: %v", deliveries[0].ID, err)
+	}
+
+	return deliveries[0], nil
+}
+
+// PhotoEvent is sent to the webhooks of the owner of the photo
+func (webhookService *WebhookServiceRepository) PhotoEvent(event string, photo domain.Photo) {
+
+	webhookService.enqueue(event, []uint{photo.UserID}, response.NewWebhookPhotoData(photo))
+}
+
+// CommentEvent is sent to the webhooks of the author of the comment and of
+// the owner of the photo commented on
+func (webhookService *WebhookServiceRepository) CommentEvent(event string, comment domain.Comment) {
+
+	ownerID := comment.Photo.UserID
+
+	if ownerID == 0 {
+		photo, err := webhookService.PhotoRepository.GetOne(comment.PhotoID)
+		if err != nil {
+			log.Printf("queueing %s of comment %d: %v", event, comment.ID, err)
+			return
+		}
+
+		ownerID = photo.UserID
+	}
+
+	webhookService.enqueue(event, []uint{comment.UserID, ownerID}, response.NewWebhookCommentData(comment))
+}
+
+// SocialMediaEvent is sent to the webhooks of the owner of the social media
+func (webhookService *WebhookServiceRepository) SocialMediaEvent(event string, socialMedia domain.SocialMedia) {
+
+	webhookService.enqueue(event, []uint{socialMedia.UserID}, response.NewWebhookSocialMediaData(socialMedia))
+}
+
+// enqueue queues a delivery of data to every active webhook of userIDs subscribed to event
+func (webhookService *WebhookServiceRepository) enqueue(event string, userIDs []uint, data interface{}) {
+
+	webhooks, err := webhookService.WebhookRepository.GetSubscribed(userIDs, event)
+	if err != nil {
+		log.Printf("queueing %s: %v", event, err)
+		return
+	}
+
+	if len(webhooks) == 0 {
+		return
+	}
+
+	now := time.Now()
+
+	payload, err := json.Marshal(response.WebhookPayload{Event: event, CreatedAt: now, Data: data})
+	if err != nil {
+		log.Printf("queueing %s: %v", event, err)
+		return
+	}
+
+	deliveries := make([]domain.WebhookDelivery, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		deliveries = append(deliveries, domain.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        domain.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+
+	if err = webhookService.WebhookDeliveryRepository.Create(deliveries); err != nil {
+		log.Printf("queueing %s: %v", event, err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		if err = webhookService.DeliveryScheduler.ScheduleDelivery(delivery); err != nil {
+			log.Printf("queueing %s delivery %d, left to the sweep: %v", event, delivery.ID, err)
+		}
+	}
+}
+
+func validateWebhook(webhook domain.Webhook) error {
+
+	target, err := url.Parse(webhook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+
+	for _, event := range webhook.EventList() {
+		if !domain.IsValidWebhookEvent(event) {
+			return fmt.Errorf("%w %q", ErrUnknownEvent, event)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"mygram-api/jobs"
+	"mygram-api/models/domain"
+	"mygram-api/webhooks/service"
+)
+
+const (
+	// sweepLimit bounds how many overdue deliveries are requeued by a sweep
+	sweepLimit = 1000
+
+	// sweepGrace is how late a delivery may be before the sweep queues it
+	// again, well past the time a worker takes to claim a due job
+	sweepGrace = 10 * time.Minute
+)
+
+// DeliverPayload names the webhook delivery to send
+type DeliverPayload struct {
+	DeliveryID uint `json:"delivery_id"`
+}
+
+var (
+	// DeliverJob sends one attempt of a webhook delivery. The attempts of the
+	// job only cover failures to record the outcome, the delivery schedules a
+	// new job for each of its own retries.
+	DeliverJob = jobs.Type[DeliverPayload]{Name: "webhooks.deliver", Queue: jobs.QueueWebhooks, MaxAttempts: 3}
+
+	// DeliverSweepJob queues the pending deliveries left without a job, their
+	// job having failed to be queued or died
+	DeliverSweepJob = jobs.Type[struct{}]{Name: "webhooks.deliver_sweep", Queue: jobs.QueueWebhooks, MaxAttempts: 3}
+)
+
+// DeliveryScheduler queues a DeliverJob for each attempt of a delivery
+type DeliveryScheduler struct {
+	Client *jobs.Client
+}
+
+func NewDeliveryScheduler(client *jobs.Client) *DeliveryScheduler {
+	return &DeliveryScheduler{Client: client}
+}
+
+// ScheduleDelivery queues the next attempt of the delivery unless it is queued already
+func (deliveryScheduler *DeliveryScheduler) ScheduleDelivery(delivery domain.WebhookDelivery) (err error) {
+
+	if _, err = jobs.Enqueue(deliveryScheduler.Client, DeliverJob, DeliverPayload{DeliveryID: delivery.ID}, jobs.At(delivery.NextAttemptAt), deliverKey(delivery)); err != nil {
+		return
+	}
+
+	return
+}
+
+// DeliverHandler runs DeliverJob. A delivery removed with its webhook is given up.
+func DeliverHandler(deliveryService service.DeliveryService) jobs.Handler {
+	return DeliverJob.Handle(func(ctx context.Context, payload DeliverPayload) error {
+		err := deliveryService.Deliver(ctx, payload.DeliveryID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return jobs.Permanent(err)
+		}
+
+		return err
+	})
+}
+
+// DeliverSweepHandler runs DeliverSweepJob
+func DeliverSweepHandler(deliveryService service.DeliveryService, deliveryScheduler *DeliveryScheduler) jobs.Handler {
+	return DeliverSweepJob.Handle(func(ctx context.Context, _ struct{}) error {
+		deliveries, err := deliveryService.OverdueDeliveries(time.Now().Add(-sweepGrace), sweepLimit)
+		if err != nil {
+			return err
+		}
+
+		for _, delivery := range deliveries {
+			if err = deliveryScheduler.ScheduleDelivery(delivery); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// deliverKey is unique to an attempt, so the job of an attempt is never
+// queued twice while the job of the previous one can queue the next
+func deliverKey(delivery domain.WebhookDelivery) jobs.Option {
+	return jobs.Unique(fmt.Sprintf("webhooks.deliver:%d:%d", delivery.ID, delivery.Attempts))
+}